@@ -4,16 +4,22 @@ package api
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fosrl/newt/logger"
 	"github.com/fosrl/windows/version"
+	"golang.org/x/sys/windows"
 )
 
 // APIError represents an error from the API client
@@ -22,6 +28,9 @@ type APIError struct {
 	Status  int
 	Message string
 	Err     error
+	// RetryAfter is how long the server asked us to wait before trying
+	// again (from a 429 Retry-After header), or 0 if it didn't say
+	RetryAfter time.Duration
 }
 
 type ErrorType int
@@ -32,6 +41,25 @@ const (
 	ErrorTypeHTTPError
 	ErrorTypeNetworkError
 	ErrorTypeDecodingError
+	ErrorTypeMaintenance
+)
+
+// MaintenanceMessage is shown while the server reports it is down for maintenance
+const MaintenanceMessage = "The server is temporarily unavailable (maintenance) — retrying"
+
+// maintenanceHeader is set by servers behind a maintenance page
+const maintenanceHeader = "X-Maintenance"
+
+const (
+	// DefaultMaxRetries is how many times a failed GET is retried by default
+	DefaultMaxRetries = 2
+	// DefaultTimeout is how long a request may take, including reading the
+	// response, on a normal network
+	DefaultTimeout = 30 * time.Second
+	// retryBaseDelay is the wait before the first retry; it doubles after each attempt
+	retryBaseDelay = 1 * time.Second
+	// retryMaxDelay caps the wait between retries
+	retryMaxDelay = 8 * time.Second
 )
 
 func (e *APIError) Error() string {
@@ -58,6 +86,8 @@ func (e *APIError) Error() string {
 			return fmt.Sprintf("Failed to decode response: %v", e.Err)
 		}
 		return "Failed to decode response"
+	case ErrorTypeMaintenance:
+		return MaintenanceMessage
 	default:
 		return "Unknown error"
 	}
@@ -67,22 +97,92 @@ func (e *APIError) Unwrap() error {
 	return e.Err
 }
 
+// IsMaintenance returns true if err reports that the server is down for maintenance
+func IsMaintenance(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Type == ErrorTypeMaintenance
+}
+
+// IsNotFound returns true if err is the server saying the requested object
+// does not exist (HTTP 404)
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Type == ErrorTypeHTTPError && apiErr.Status == http.StatusNotFound
+}
+
+// DefaultRetryAfter is how long to back off after a 429 whose Retry-After
+// header is missing or unusable
+const DefaultRetryAfter = time.Minute
+
+// RetryAfter returns how long to wait before retrying if err is a rate limit
+// error: the server's Retry-After, or DefaultRetryAfter if it didn't say
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+	return DefaultRetryAfter, true
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date, into the time left to wait from now
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// isMaintenanceResponse returns true for a 503 or a response carrying the maintenance header
+func isMaintenanceResponse(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return true
+	}
+	value := strings.TrimSpace(resp.Header.Get(maintenanceHeader))
+	return value != "" && value != "0" && !strings.EqualFold(value, "false")
+}
+
 // APIClient handles HTTP requests to the Pangolin API
 type APIClient struct {
 	baseURL           string
 	sessionToken      string
+	tokenMu           sync.RWMutex // guards sessionToken, which rotation updates from request goroutines
 	sessionCookieName string
 	csrfToken         string
 	client            *http.Client
+	clientMu          sync.RWMutex // guards client and the network settings it is built from
+	proxy             *url.URL
+	rootCAs           *x509.CertPool
+	tlsSkipVerify     bool
+	maxRetries        int
 	onUnauthorized    func()
+	onSessionRotated  func(token string)
 }
 
-// NewAPIClient creates a new API client instance
-func NewAPIClient(baseURL string, sessionToken string) *APIClient {
+// NewAPIClient creates a new API client instance whose requests give up after
+// timeout, or DefaultTimeout if it is 0
+func NewAPIClient(baseURL string, sessionToken string, timeout time.Duration) *APIClient {
 	normalizedURL := normalizeBaseURL(baseURL)
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	if timeout <= 0 {
+		timeout = DefaultTimeout
 	}
 
 	apiClient := &APIClient{
@@ -90,7 +190,11 @@ func NewAPIClient(baseURL string, sessionToken string) *APIClient {
 		sessionToken:      sessionToken,
 		sessionCookieName: "p_session_token",
 		csrfToken:         "x-csrf-protection",
-		client:            client,
+		maxRetries:        DefaultMaxRetries,
+	}
+	apiClient.client = &http.Client{
+		Timeout:   timeout,
+		Transport: apiClient.newTransport(),
 	}
 
 	logger.Info("APIClient initialized with baseURL: %s", apiClient.baseURL)
@@ -104,19 +208,125 @@ func (c *APIClient) UpdateBaseURL(newBaseURL string) {
 
 // UpdateSessionToken updates the session token
 func (c *APIClient) UpdateSessionToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
 	c.sessionToken = token
 }
 
+// CurrentSessionToken returns the session token currently sent with requests
+func (c *APIClient) CurrentSessionToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.sessionToken
+}
+
+// rotateSessionToken replaces sent with rotated, unless the token was changed
+// (e.g. by a new login) while the request was in flight. Returns whether it did.
+func (c *APIClient) rotateSessionToken(sent, rotated string) bool {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.sessionToken != sent {
+		return false
+	}
+	c.sessionToken = rotated
+	return true
+}
+
 // CurrentBaseURL returns the current base URL
 func (c *APIClient) CurrentBaseURL() string {
 	return c.baseURL
 }
 
+// SetProxy sends requests through proxy, or through the proxy named by the
+// HTTP_PROXY/HTTPS_PROXY environment variables if proxy is nil
+func (c *APIClient) SetProxy(proxy *url.URL) {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.proxy = proxy
+	c.replaceClient(c.client.Timeout, c.newTransport())
+}
+
+// SetTimeout sets how long a request may take; 0 restores DefaultTimeout
+func (c *APIClient) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.replaceClient(timeout, c.client.Transport)
+}
+
+// SetTLS verifies servers against rootCAs, or the system roots if nil. With
+// skipVerify, server certificates are not verified at all, which is only
+// meant for lab setups.
+func (c *APIClient) SetTLS(rootCAs *x509.CertPool, skipVerify bool) {
+	if skipVerify {
+		logger.Warn("TLS certificate verification is turned off for API requests")
+	}
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	c.rootCAs = rootCAs
+	c.tlsSkipVerify = skipVerify
+	c.replaceClient(c.client.Timeout, c.newTransport())
+}
+
+// replaceClient swaps in a new HTTP client rather than changing the one that
+// requests on other goroutines may be using, and lets the old transport's
+// idle connections go. The caller must hold clientMu.
+func (c *APIClient) replaceClient(timeout time.Duration, transport http.RoundTripper) {
+	old := c.client
+	c.client = &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+	if old.Transport != transport {
+		old.CloseIdleConnections()
+	}
+}
+
+// httpClient returns the HTTP client for the current network settings
+func (c *APIClient) httpClient() *http.Client {
+	c.clientMu.RLock()
+	defer c.clientMu.RUnlock()
+	return c.client
+}
+
+// newTransport returns a transport for the client's current network
+// settings. The caller must hold clientMu unless the client is not shared yet.
+func (c *APIClient) newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.proxy != nil {
+		transport.Proxy = http.ProxyURL(c.proxy)
+	}
+	if c.rootCAs != nil || c.tlsSkipVerify {
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:            c.rootCAs,
+			InsecureSkipVerify: c.tlsSkipVerify,
+		}
+	}
+	return transport
+}
+
+// SetMaxRetries sets how many times a GET request is retried after a transient
+// failure; 0 disables retries. Requests that change state are never retried.
+func (c *APIClient) SetMaxRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	c.maxRetries = n
+}
+
 // SetOnUnauthorized sets the callback invoked when a request sent with a session token returns 401 or 403.
 func (c *APIClient) SetOnUnauthorized(fn func()) {
 	c.onUnauthorized = fn
 }
 
+// SetOnSessionRotated sets the callback invoked when the server issues a new session token
+// via Set-Cookie on an authenticated request, so the new token can be persisted.
+func (c *APIClient) SetOnSessionRotated(fn func(token string)) {
+	c.onSessionRotated = fn
+}
+
 // normalizeBaseURL normalizes a base URL string
 func normalizeBaseURL(urlStr string) string {
 	normalized := strings.TrimSpace(urlStr)
@@ -160,13 +370,73 @@ func (c *APIClient) apiURL(path string) (string, error) {
 	return fullURL, nil
 }
 
-// makeRequest makes an HTTP request and returns the response data and status
+// makeRequest makes an HTTP request and returns the response data and status.
+// GET requests are retried with backoff on transient failures; other methods
+// may change state on the server and are sent only once.
 func (c *APIClient) makeRequest(method, path string, body []byte) ([]byte, *http.Response, error) {
 	fullURL, err := c.apiURL(path)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	attempts := 1
+	if method == http.MethodGet {
+		attempts += c.maxRetries
+	}
+	for attempt := 1; ; attempt++ {
+		data, resp, err := c.doRequest(method, fullURL, body)
+		if attempt >= attempts || !isTransientFailure(resp, err) {
+			return data, resp, err
+		}
+		delay := retryDelay(attempt)
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if wait > retryMaxDelay {
+					// Too long to wait here; let the caller back off
+					return data, resp, err
+				}
+				delay = wait
+			}
+		}
+		logger.Info("Retrying %s %s in %s (attempt %d of %d)", method, path, delay, attempt+1, attempts)
+		time.Sleep(delay)
+	}
+}
+
+// isTransientFailure returns true for failures that are likely to go away on
+// their own: timeouts, dropped connections, rate limiting and gateway errors
+func isTransientFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && (urlErr.Timeout() || urlErr.Temporary()) {
+			return true
+		}
+		return errors.Is(err, windows.WSAECONNRESET) ||
+			errors.Is(err, windows.WSAECONNABORTED) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, io.EOF)
+	}
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns the backoff before retry number attempt (starting at 1)
+func retryDelay(attempt int) time.Duration {
+	delay := retryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// doRequest sends a single HTTP request to fullURL
+func (c *APIClient) doRequest(method, fullURL string, body []byte) ([]byte, *http.Response, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		bodyReader = bytes.NewReader(body)
@@ -184,13 +454,14 @@ func (c *APIClient) makeRequest(method, path string, body []byte) ([]byte, *http
 	req.Header.Set("X-CSRF-Token", c.csrfToken)
 
 	// Add session cookie if available
-	if c.sessionToken != "" {
-		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", c.sessionCookieName, c.sessionToken))
+	sessionToken := c.CurrentSessionToken()
+	if sessionToken != "" {
+		req.Header.Set("Cookie", fmt.Sprintf("%s=%s", c.sessionCookieName, sessionToken))
 	}
 
 	logger.Debug("Making request to: %s", fullURL)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.httpClient().Do(req)
 	if err != nil {
 		// Handle network errors with more specific messages
 		if urlErr, ok := err.(*url.Error); ok {
@@ -219,8 +490,18 @@ func (c *APIClient) makeRequest(method, path string, body []byte) ([]byte, *http
 		return nil, resp, &APIError{Type: ErrorTypeInvalidResponse, Err: err}
 	}
 
+	// Pick up a rotated session cookie so later requests don't keep using the old token
+	if sessionToken != "" {
+		if rotated := extractCookie(resp, c.sessionCookieName); rotated != "" && rotated != sessionToken && c.rotateSessionToken(sessionToken, rotated) {
+			logger.Info("Session token was rotated by the server")
+			if c.onSessionRotated != nil {
+				c.onSessionRotated(rotated)
+			}
+		}
+	}
+
 	// Notify when an authenticated request gets 401/403 so session-expired state can be set
-	if (resp.StatusCode == 401 || resp.StatusCode == 403) && c.sessionToken != "" && c.onUnauthorized != nil {
+	if (resp.StatusCode == 401 || resp.StatusCode == 403) && sessionToken != "" && c.onUnauthorized != nil {
 		c.onUnauthorized()
 	}
 
@@ -229,8 +510,22 @@ func (c *APIClient) makeRequest(method, path string, body []byte) ([]byte, *http
 
 // parseResponse parses the API response and returns the data
 func (c *APIClient) parseResponse(data []byte, resp *http.Response, result interface{}) error {
+	// Maintenance pages are usually HTML, so check before trying to parse a message
+	if isMaintenanceResponse(resp) {
+		return &APIError{
+			Type:    ErrorTypeMaintenance,
+			Status:  resp.StatusCode,
+			Message: MaintenanceMessage,
+		}
+	}
+
 	// Check HTTP status first
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var retryAfter time.Duration
+		if resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+
 		// Try to parse error message from response
 		var errorResponse APIResponse[EmptyResponse]
 		if err := json.Unmarshal(data, &errorResponse); err == nil {
@@ -239,17 +534,19 @@ func (c *APIClient) parseResponse(data []byte, resp *http.Response, result inter
 				message = getDefaultHTTPErrorMessage(resp.StatusCode)
 			}
 			return &APIError{
-				Type:    ErrorTypeHTTPError,
-				Status:  resp.StatusCode,
-				Message: message,
+				Type:       ErrorTypeHTTPError,
+				Status:     resp.StatusCode,
+				Message:    message,
+				RetryAfter: retryAfter,
 			}
 		}
 
 		// Fallback to default error message
 		return &APIError{
-			Type:    ErrorTypeHTTPError,
-			Status:  resp.StatusCode,
-			Message: getDefaultHTTPErrorMessage(resp.StatusCode),
+			Type:       ErrorTypeHTTPError,
+			Status:     resp.StatusCode,
+			Message:    getDefaultHTTPErrorMessage(resp.StatusCode),
+			RetryAfter: retryAfter,
 		}
 	}
 
@@ -332,6 +629,8 @@ func getDefaultHTTPErrorMessage(statusCode int) string {
 		return "Rate limit exceeded"
 	case 500:
 		return "Internal server error"
+	case 503:
+		return MaintenanceMessage
 	default:
 		return fmt.Sprintf("HTTP error %d", statusCode)
 	}
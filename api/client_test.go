@@ -0,0 +1,134 @@
+//go:build windows
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"empty", "", 0, false},
+		{"seconds", "120", 2 * time.Minute, true},
+		{"seconds with spaces", " 5 ", 5 * time.Second, true},
+		{"zero seconds", "0", 0, true},
+		{"negative seconds", "-1", 0, false},
+		{"http date", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{"http date in the past", now.Add(-time.Hour).Format(http.TimeFormat), 0, true},
+		{"garbage", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   time.Duration
+		wantOK bool
+	}{
+		{"nil", nil, 0, false},
+		{"not an API error", fmt.Errorf("dial failed"), 0, false},
+		{"other status", &APIError{Type: ErrorTypeHTTPError, Status: http.StatusInternalServerError}, 0, false},
+		{"429 with Retry-After", &APIError{Type: ErrorTypeHTTPError, Status: http.StatusTooManyRequests, RetryAfter: 30 * time.Second}, 30 * time.Second, true},
+		{"429 without Retry-After", &APIError{Type: ErrorTypeHTTPError, Status: http.StatusTooManyRequests}, DefaultRetryAfter, true},
+		{"wrapped 429", fmt.Errorf("refresh: %w", &APIError{Type: ErrorTypeHTTPError, Status: http.StatusTooManyRequests}), DefaultRetryAfter, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RetryAfter(tt.err)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RetryAfter(%v) = %v, %v; want %v, %v", tt.err, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewAPIClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewAPIClient(server.URL, "", 50*time.Millisecond)
+	client.SetMaxRetries(0)
+
+	start := time.Now()
+	if _, _, err := client.makeRequest(http.MethodGet, "/user", nil); err == nil {
+		t.Fatal("request to a stalled server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("request took %v, want it cut off by the 50ms timeout", elapsed)
+	}
+}
+
+func TestNewAPIClientDefaultTimeout(t *testing.T) {
+	client := NewAPIClient("https://pangolin.example.com", "", 0)
+	if timeout := client.httpClient().Timeout; timeout != DefaultTimeout {
+		t.Errorf("timeout = %v, want DefaultTimeout (%v)", timeout, DefaultTimeout)
+	}
+}
+
+func TestSettingsReplaceClientDuringRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{},"success":true}`))
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL, "", 0)
+	client.SetMaxRetries(0)
+
+	// Run under -race: the setters must not touch the client requests use
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				client.makeRequest(http.MethodGet, "/user", nil)
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		client.SetTimeout(time.Duration(i+1) * time.Second)
+		client.SetProxy(nil)
+		client.SetTLS(nil, false)
+	}
+	wg.Wait()
+
+	before := client.httpClient()
+	client.SetTimeout(time.Minute)
+	after := client.httpClient()
+	if after == before {
+		t.Error("SetTimeout changed the shared client instead of replacing it")
+	}
+	if after.Timeout != time.Minute {
+		t.Errorf("timeout = %v, want 1m", after.Timeout)
+	}
+	if after.Transport != before.Transport {
+		t.Error("SetTimeout replaced the transport, dropping its connections")
+	}
+}
@@ -168,6 +168,22 @@ func (c *APIClient) ListUserOrgs(userId string) (*ListUserOrgsResponse, error) {
 	return &response, nil
 }
 
+// ListResources lists the private resources of an organization
+func (c *APIClient) ListResources(orgId string) (*ListResourcesResponse, error) {
+	path := fmt.Sprintf("/org/%s/site-resources", orgId)
+	data, resp, err := c.makeRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ListResourcesResponse
+	if err := c.parseResponse(data, resp, &response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
 // CreateOlm creates an OLM for a user
 func (c *APIClient) CreateOlm(userId, name string) (*CreateOlmResponse, error) {
 	requestBody := CreateOlmRequest{
@@ -290,7 +306,8 @@ func (c *APIClient) GetMyDevice(olmId string) (*MyDeviceResponse, error) {
 func (c *APIClient) TestConnection() (bool, error) {
 	// Create a temporary client with shorter timeout for connection test
 	testClient := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout:   10 * time.Second,
+		Transport: c.httpClient().Transport,
 	}
 
 	// Use HEAD request to test connection
@@ -329,7 +346,8 @@ func (c *APIClient) GetServerInfo() (*ServerInfo, error) {
 
 // CheckHealth checks if the server is reachable and responding
 // Returns true if status is 200-299, 401, or 403 (server is up)
-// Returns false if server is unreachable or returns other error status
+// Returns false if server is unreachable or returns other error status, with a
+// maintenance error if the server reports it is down for maintenance
 func (c *APIClient) CheckHealth() (bool, error) {
 	_, resp, err := c.makeRequest("GET", "", nil)
 	if err != nil {
@@ -337,6 +355,10 @@ func (c *APIClient) CheckHealth() (bool, error) {
 		return false, nil
 	}
 
+	if isMaintenanceResponse(resp) {
+		return false, &APIError{Type: ErrorTypeMaintenance, Status: resp.StatusCode, Message: MaintenanceMessage}
+	}
+
 	// Server is up if status is 200-299, 401, or 403
 	statusCode := resp.StatusCode
 	if (statusCode >= 200 && statusCode < 300) || statusCode == 401 || statusCode == 403 {
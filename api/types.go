@@ -70,6 +70,21 @@ type Org struct {
 	Name string `json:"name"`
 }
 
+// ListResourcesResponse represents the response for listing an organization's resources
+type ListResourcesResponse struct {
+	Resources []Resource `json:"siteResources"`
+}
+
+// Resource represents a private resource the tunnel can route to
+type Resource struct {
+	Id          int     `json:"siteResourceId"`
+	Name        string  `json:"name"`
+	SiteName    string  `json:"siteName,omitempty"`
+	Mode        string  `json:"mode,omitempty"` // "host" or "cidr"
+	Destination string  `json:"destination"`
+	Alias       *string `json:"alias,omitempty"`
+}
+
 // CreateOlmRequest represents a request to create an OLM
 type CreateOlmRequest struct {
 	Name string `json:"name"`
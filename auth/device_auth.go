@@ -0,0 +1,62 @@
+//go:build windows
+
+package auth
+
+import (
+	"time"
+)
+
+// deviceAuthResumeGrace is how long a device auth code is kept after the
+// login is cancelled, so reopening the login dialog continues with the code
+// the user may already have entered instead of generating a new one
+const deviceAuthResumeGrace = 60 * time.Second
+
+// pendingDeviceAuth is a device auth code whose login was cancelled but may
+// still be resumed
+type pendingDeviceAuth struct {
+	hostname     string
+	code         string
+	loginURL     string
+	expiresAt    time.Time
+	resumeBefore time.Time
+}
+
+// usable reports whether the code may still be resumed at now: within the
+// grace window and before the server's own expiry
+func (p *pendingDeviceAuth) usable(now time.Time) bool {
+	return p != nil && now.Before(p.resumeBefore) && now.Before(p.expiresAt)
+}
+
+// ResumableDeviceAuthHostname returns the server of a cancelled device login
+// that can still be resumed, or "" if there is none
+func (am *AuthManager) ResumableDeviceAuthHostname() string {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	if !am.pendingDeviceAuth.usable(time.Now()) {
+		return ""
+	}
+	return am.pendingDeviceAuth.hostname
+}
+
+// suspendDeviceAuth keeps a cancelled login's code for the grace window.
+// Must be called with am.mu held.
+func (am *AuthManager) suspendDeviceAuth(hostname, code, loginURL string, expiresAt time.Time) {
+	am.pendingDeviceAuth = &pendingDeviceAuth{
+		hostname:     hostname,
+		code:         code,
+		loginURL:     loginURL,
+		expiresAt:    expiresAt,
+		resumeBefore: time.Now().Add(deviceAuthResumeGrace),
+	}
+}
+
+// takePendingDeviceAuth returns and forgets the cancelled login for hostname
+// if it can still be resumed. Must be called with am.mu held.
+func (am *AuthManager) takePendingDeviceAuth(hostname string) *pendingDeviceAuth {
+	pending := am.pendingDeviceAuth
+	am.pendingDeviceAuth = nil
+	if !pending.usable(time.Now()) || pending.hostname != hostname {
+		return nil
+	}
+	return pending
+}
@@ -3,6 +3,9 @@
 package auth
 
 import (
+	"strings"
+	"unicode"
+
 	"github.com/fosrl/windows/api"
 	"github.com/fosrl/windows/config"
 )
@@ -30,12 +33,16 @@ func UserDisplayName(user *api.User) string {
 }
 
 // AccountDisplayName returns a display name for an account with precedence:
-// email > name > username > "Account"
+// label > email > name > username > "Account"
 func AccountDisplayName(account *config.Account) string {
 	if account == nil {
 		return "Account"
 	}
 
+	if account.Label != "" {
+		return account.Label
+	}
+
 	if account.Email != "" {
 		return account.Email
 	}
@@ -50,3 +57,40 @@ func AccountDisplayName(account *config.Account) string {
 
 	return "Account"
 }
+
+// AccountInitials returns up to two uppercase initials for an account, taken
+// from the name's first and last words, or the first letter of the username
+// or email when there is no name.
+func AccountInitials(account *config.Account) string {
+	if account == nil {
+		return "?"
+	}
+
+	if words := strings.Fields(account.Name); len(words) > 0 {
+		initials := firstLetter(words[0])
+		if len(words) > 1 {
+			initials += firstLetter(words[len(words)-1])
+		}
+		if initials != "" {
+			return initials
+		}
+	}
+
+	for _, s := range []string{account.Username, account.Email} {
+		if initial := firstLetter(s); initial != "" {
+			return initial
+		}
+	}
+
+	return "?"
+}
+
+// firstLetter returns the first letter or digit of s in upper case
+func firstLetter(s string) string {
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return string(unicode.ToUpper(r))
+		}
+	}
+	return ""
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/fosrl/windows/api"
 	"github.com/fosrl/windows/config"
 	"github.com/fosrl/windows/secrets"
+	"github.com/fosrl/windows/version"
 
 	"github.com/fosrl/newt/logger"
 )
@@ -59,15 +60,24 @@ type AuthManager struct {
 	currentUser                *api.User
 	currentOrg                 *api.Org
 	organizations              []api.Org
+	orgsListed                 bool // organizations holds a successfully loaded list
 	isInitializing             bool
 	errorMessage               *string
 	deviceAuthCode             *string
 	deviceAuthLoginURL         *string
+	pendingDeviceAuth          *pendingDeviceAuth
 	serverInfo                 *api.ServerInfo
 	isServerDown               bool
 	sessionExpired             bool
 	isDeviceAuthInProgress     bool
 	startDeviceAuthImmediately bool
+	isSwitchingAccount         bool
+	pendingAuth                *pendingAuth
+	serverVersionWarning       string
+	sessionRotatedAt           time.Time
+
+	// switchMu serializes SwitchAccount so rapid switches run one at a time
+	switchMu sync.Mutex
 }
 
 // NewAuthManager creates a new AuthManager instance
@@ -183,41 +193,72 @@ func (am *AuthManager) LoginWithDeviceAuth(ctx context.Context, hostnameOverride
 	var loginClient *api.APIClient
 	if hostnameOverride != nil && *hostnameOverride != "" {
 		// Create temporary client with override hostname
-		loginClient = api.NewAPIClient(*hostnameOverride, "")
+		loginClient = api.NewAPIClient(*hostnameOverride, "", config.ScaleForNetwork(api.DefaultTimeout, am.configManager.GetSlowNetwork()))
+		loginClient.SetProxy(am.secretManager.ProxyURL(am.configManager))
+		loginClient.SetTLS(am.configManager.GetRootCAs(), am.configManager.GetTLSSkipVerify())
 	} else {
 		// Use main API client
 		loginClient = am.apiClient
 	}
 
-	// Get friendly device name (e.g., "Windows Laptop" or "Windows Desktop")
-	deviceName := config.GetFriendlyDeviceName()
-
-	// Start device auth
-	startResponse, err := loginClient.StartDeviceAuth("Pangolin Windows Client", &deviceName)
-	if err != nil {
+	// Warn about servers that are too old, since they may fail with confusing
+	// API errors, but let the user try anyway
+	if err := checkServerVersion(loginClient); err != nil {
+		logger.Warn("Login: %v", err)
 		am.mu.Lock()
-		if apiErr, ok := err.(*api.APIError); ok {
-			msg := apiErr.Error()
-			am.errorMessage = &msg
-		} else {
-			msg := err.Error()
-			am.errorMessage = &msg
-		}
+		am.serverVersionWarning = err.Error()
 		am.mu.Unlock()
-		return err
 	}
 
-	// Store code and URL for UI display
-	code := startResponse.Code
-	loginURL := fmt.Sprintf("%s/auth/login/device", loginClient.CurrentBaseURL())
+	hostname := ""
+	if hostnameOverride != nil {
+		hostname = *hostnameOverride
+	}
+
+	// Continue with the code of a login cancelled moments ago, if any, so a
+	// user who closed the dialog by accident does not have to start over
+	am.mu.Lock()
+	pending := am.takePendingDeviceAuth(hostname)
+	am.mu.Unlock()
+
+	var code, loginURL string
+	var expiresAt time.Time
+	if pending != nil {
+		logger.Info("Resuming device login with the previous code")
+		code = pending.code
+		loginURL = pending.loginURL
+		expiresAt = pending.expiresAt
+	} else {
+		// Get friendly device name (user-configured, or e.g. "DESKTOP-AB12 (Windows Desktop)")
+		deviceName := am.configManager.GetFriendlyDeviceName()
+
+		// Start device auth
+		startResponse, err := loginClient.StartDeviceAuth("Pangolin Windows Client", &deviceName)
+		if err != nil {
+			am.mu.Lock()
+			if apiErr, ok := err.(*api.APIError); ok {
+				msg := apiErr.Error()
+				am.errorMessage = &msg
+			} else {
+				msg := err.Error()
+				am.errorMessage = &msg
+			}
+			am.mu.Unlock()
+			return err
+		}
+
+		code = startResponse.Code
+		loginURL = fmt.Sprintf("%s/auth/login/device", loginClient.CurrentBaseURL())
+		expiresAt = time.Now().Add(time.Duration(startResponse.ExpiresInSeconds) * time.Second)
+	}
 
+	// Store code and URL for UI display
 	am.mu.Lock()
 	am.deviceAuthCode = &code
 	am.deviceAuthLoginURL = &loginURL
 	am.mu.Unlock()
 
 	// Poll for verification
-	expiresAt := time.Now().Add(time.Duration(startResponse.ExpiresInSeconds) * time.Second)
 	verified := false
 	var sessionToken *string
 
@@ -227,10 +268,12 @@ func (am *AuthManager) LoginWithDeviceAuth(ctx context.Context, hostnameOverride
 	for !verified && time.Now().Before(expiresAt) {
 		select {
 		case <-ctx.Done():
-			// Context canceled, clear state and return
+			// Context canceled, clear state but keep the code for a short
+			// while in case the login dialog is reopened
 			am.mu.Lock()
 			am.deviceAuthCode = nil
 			am.deviceAuthLoginURL = nil
+			am.suspendDeviceAuth(hostname, code, loginURL, expiresAt)
 			am.mu.Unlock()
 			return ctx.Err()
 		case <-ticker.C:
@@ -270,6 +313,9 @@ func (am *AuthManager) LoginWithDeviceAuth(ctx context.Context, hostnameOverride
 		return &AuthError{Type: AuthErrorInvalidToken}
 	}
 
+	// Keep the signed-in account's state so a login that can't complete doesn't sign it out
+	prev := am.saveAuthState()
+
 	// If hostname override was provided, update main API client's base URL
 	if hostnameOverride != nil && *hostnameOverride != "" {
 		am.apiClient.UpdateBaseURL(*hostnameOverride)
@@ -281,6 +327,7 @@ func (am *AuthManager) LoginWithDeviceAuth(ctx context.Context, hostnameOverride
 	// Get user info using main API client (now with updated base URL if override was provided)
 	user, err := am.apiClient.GetUser()
 	if err != nil {
+		am.restoreAuthState(prev)
 		am.mu.Lock()
 		msg := err.Error()
 		am.errorMessage = &msg
@@ -294,7 +341,7 @@ func (am *AuthManager) LoginWithDeviceAuth(ctx context.Context, hostnameOverride
 	am.deviceAuthLoginURL = nil
 	am.mu.Unlock()
 
-	return am.handleSuccessfulAuth(user, loginClient.CurrentBaseURL(), *sessionToken)
+	return am.completeAuth(user, loginClient.CurrentBaseURL(), *sessionToken, prev)
 }
 
 // Select an organization if there isn't one already. This happens
@@ -317,10 +364,12 @@ func (am *AuthManager) ensureOrgIsSelected(account *config.Account) string {
 		logger.Error("Failed to load organizations: %v", err)
 		am.mu.Lock()
 		am.organizations = []api.Org{}
+		am.orgsListed = false
 		am.mu.Unlock()
 	} else {
 		am.mu.Lock()
 		am.organizations = orgsResponse.Orgs
+		am.orgsListed = true
 		am.mu.Unlock()
 
 		// Restore last selected org from config,
@@ -362,8 +411,102 @@ func (am *AuthManager) ensureOrgIsSelected(account *config.Account) string {
 	return selectedOrgID
 }
 
+// ErrSessionTokenNotSaved is returned by login when the server accepted the
+// user but the session token could not be stored. The account signed in
+// before, if any, stays signed in; RetryPendingAuth tries to store the token
+// again and ContinuePendingAuth signs in with the token kept in memory only.
+var ErrSessionTokenNotSaved = errors.New("failed to save session token")
+
+// pendingAuth is a successful login whose session token could not be saved yet
+type pendingAuth struct {
+	user     *api.User
+	hostname string
+	token    string
+}
+
+// authState is the signed-in state a login replaces. It is restored when the
+// login cannot complete, so adding an account doesn't sign out the current one.
+type authState struct {
+	baseURL         string
+	sessionToken    string
+	isAuthenticated bool
+	currentUser     *api.User
+	currentOrg      *api.Org
+	organizations   []api.Org
+	orgsListed      bool
+}
+
+func (am *AuthManager) saveAuthState() authState {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return authState{
+		baseURL:         am.apiClient.CurrentBaseURL(),
+		sessionToken:    am.apiClient.CurrentSessionToken(),
+		isAuthenticated: am.isAuthenticated,
+		currentUser:     am.currentUser,
+		currentOrg:      am.currentOrg,
+		organizations:   am.organizations,
+		orgsListed:      am.orgsListed,
+	}
+}
+
+func (am *AuthManager) restoreAuthState(s authState) {
+	am.apiClient.UpdateBaseURL(s.baseURL)
+	am.apiClient.UpdateSessionToken(s.sessionToken)
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.isAuthenticated = s.isAuthenticated
+	am.currentUser = s.currentUser
+	am.currentOrg = s.currentOrg
+	am.organizations = s.organizations
+	am.orgsListed = s.orgsListed
+}
+
+// takePendingAuth returns the login waiting on ErrSessionTokenNotSaved, if any
+func (am *AuthManager) takePendingAuth() (*pendingAuth, error) {
+	am.mu.RLock()
+	pending := am.pendingAuth
+	am.mu.RUnlock()
+	if pending == nil {
+		return nil, errors.New("no login is waiting to be saved")
+	}
+	return pending, nil
+}
+
+// RetryPendingAuth retries saving the session token of the last login that
+// failed with ErrSessionTokenNotSaved and completes that login
+func (am *AuthManager) RetryPendingAuth() error {
+	pending, err := am.takePendingAuth()
+	if err != nil {
+		return err
+	}
+	return am.handleSuccessfulAuth(pending.user, pending.hostname, pending.token)
+}
+
+// ContinuePendingAuth completes the last login that failed with
+// ErrSessionTokenNotSaved, keeping its session token in memory until it can
+// be stored (see SecretManager.PersistSessionTokens) or the app exits
+func (am *AuthManager) ContinuePendingAuth() error {
+	pending, err := am.takePendingAuth()
+	if err != nil {
+		return err
+	}
+	userId := pending.user.UserId
+	if userId == "" {
+		userId = pending.user.Id
+	}
+	am.secretManager.KeepSessionToken(userId, pending.token)
+	return am.handleSuccessfulAuth(pending.user, pending.hostname, pending.token)
+}
+
 // handleSuccessfulAuth handles successful authentication
 func (am *AuthManager) handleSuccessfulAuth(user *api.User, hostname string, token string) error {
+	return am.completeAuth(user, hostname, token, am.saveAuthState())
+}
+
+// completeAuth signs in user. If the session token cannot be saved, prev is
+// restored and the login waits as pendingAuth.
+func (am *AuthManager) completeAuth(user *api.User, hostname string, token string, prev authState) error {
 	am.apiClient.UpdateBaseURL(hostname)
 	am.apiClient.UpdateSessionToken(token)
 
@@ -382,9 +525,18 @@ func (am *AuthManager) handleSuccessfulAuth(user *api.User, hostname string, tok
 
 	selectedOrgID := am.ensureOrgIsSelected(existingAccount)
 
-	if !am.secretManager.SaveSessionToken(user.UserId, token) {
+	if !am.secretManager.SaveSessionToken(user.UserId, token) && !am.secretManager.HasMemorySessionToken(user.UserId, token) {
 		logger.Error("Auth: SaveSessionToken() failed (userId=%s)", user.UserId)
-		return fmt.Errorf("failed to save session token")
+		// Don't stay half signed in with a token that won't survive a restart;
+		// go back to the account that was signed in before, if any
+		am.restoreAuthState(prev)
+		am.mu.Lock()
+		am.pendingAuth = &pendingAuth{user: user, hostname: hostname, token: token}
+		am.mu.Unlock()
+		if err := am.secretManager.LastSaveError(); err != nil {
+			return fmt.Errorf("%w: %v", ErrSessionTokenNotSaved, err)
+		}
+		return ErrSessionTokenNotSaved
 	}
 
 	var username string
@@ -412,7 +564,9 @@ func (am *AuthManager) handleSuccessfulAuth(user *api.User, hostname string, tok
 	am.mu.Lock()
 	am.isAuthenticated = true
 	am.sessionExpired = false
+	am.sessionRotatedAt = time.Time{}
 	am.startDeviceAuthImmediately = false
+	am.pendingAuth = nil
 	am.mu.Unlock()
 
 	// Fetch server info after successful authentication
@@ -430,6 +584,44 @@ func (am *AuthManager) MarkSessionExpired() {
 	am.errorMessage = nil
 }
 
+// HandleSessionRotated persists a session token rotated by the server for the active account
+func (am *AuthManager) HandleSessionRotated(token string) {
+	activeAccount, _ := am.accountManager.ActiveAccount()
+	if activeAccount == nil {
+		return
+	}
+
+	am.mu.Lock()
+	am.sessionRotatedAt = time.Now()
+	am.mu.Unlock()
+
+	if !am.secretManager.SaveSessionToken(activeAccount.UserID, token) {
+		// The old token is already revoked; keep the new one so the session survives until restart
+		logger.Error("Auth: failed to save rotated session token (userId=%s)", activeAccount.UserID)
+		am.secretManager.KeepSessionToken(activeAccount.UserID, token)
+		return
+	}
+	logger.Info("Auth: saved rotated session token (userId=%s)", activeAccount.UserID)
+}
+
+// VerifySession checks that the current session is still accepted by the server
+// and marks the session expired on 401/403. The server has no refresh tokens,
+// so an expired session always needs a new login.
+func (am *AuthManager) VerifySession() error {
+	_, err := am.apiClient.GetUser()
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || (apiErr.Status != 401 && apiErr.Status != 403) {
+		return err
+	}
+
+	am.MarkSessionExpired()
+	return err
+}
+
 // RefreshOrganizations refreshes the list of organizations
 func (am *AuthManager) RefreshOrganizations() error {
 	am.mu.RLock()
@@ -480,6 +672,7 @@ func (am *AuthManager) RefreshOrganizations() error {
 
 	// Update organizations list
 	am.organizations = newOrgs
+	am.orgsListed = true
 	am.mu.Unlock()
 
 	logger.Debug("Organizations refreshed successfully: %d orgs", len(newOrgs))
@@ -556,6 +749,7 @@ func (am *AuthManager) RefreshFromMyDevice(olmId string) error {
 
 	// Update organizations list
 	am.organizations = newOrgs
+	am.orgsListed = true
 
 	// Ensure authentication is still set (should be true if we got here)
 	am.isAuthenticated = true
@@ -580,6 +774,61 @@ func (am *AuthManager) GetOlmId() (string, bool) {
 	return am.secretManager.GetOlmId(userId)
 }
 
+// UnsupportedServerError describes a server older than
+// version.MinServerVersion
+type UnsupportedServerError struct {
+	Version string
+}
+
+func (e *UnsupportedServerError) Error() string {
+	return fmt.Sprintf("This Pangolin server is running version %s, but this client needs version %s or newer, so some features may not work. Please ask your server administrator to upgrade Pangolin.", e.Version, version.MinServerVersion)
+}
+
+// checkServerVersion returns an UnsupportedServerError if the server reports a
+// version below the supported minimum. Servers whose version can't be fetched
+// are not reported, since the check is only there to explain API mismatches.
+func checkServerVersion(client *api.APIClient) error {
+	serverInfo, err := client.GetServerInfo()
+	if err != nil {
+		logger.Info("Could not determine server version: %v", err)
+		return nil
+	}
+	if !version.IsServerVersionSupported(serverInfo.Version) {
+		return &UnsupportedServerError{Version: serverInfo.Version}
+	}
+	return nil
+}
+
+// OrgAccessDeniedError is returned by CheckOrgAccess when an organization
+// policy denies the user access
+type OrgAccessDeniedError struct {
+	OrgID         string
+	Reason        string
+	ResolutionURL string
+}
+
+func (e *OrgAccessDeniedError) Error() string {
+	msg := "Access denied due to organization policy violations."
+	if e.Reason != "" {
+		msg = fmt.Sprintf("Access denied: %s", e.Reason)
+	}
+	return msg + fmt.Sprintf("\n\nSee more and resolve the issues by visiting: %s", e.ResolutionURL)
+}
+
+// OrgResolutionURL returns the URL where the user can review and resolve
+// policy issues for an organization on the active account's server
+func (am *AuthManager) OrgResolutionURL(orgId string) string {
+	var hostname string
+	if activeAccount, _ := am.accountManager.ActiveAccount(); activeAccount != nil {
+		hostname = activeAccount.Hostname
+	} else {
+		// Ideally this should never happen, but use a safe fallback
+		// just in case.
+		hostname = config.DefaultHostname
+	}
+	return fmt.Sprintf("%s/%s", hostname, orgId)
+}
+
 // CheckOrgAccess checks if the user has access to an organization
 func (am *AuthManager) CheckOrgAccess(orgId string) (bool, error) {
 	// First, try to fetch the org to check access
@@ -608,25 +857,14 @@ func (am *AuthManager) CheckOrgAccess(orgId string) (bool, error) {
 			if err == nil {
 				// Check if access is denied and show error message
 				if !policyResponse.Allowed {
-					// Get hostname for the resolution URL
-					var hostname string
-					if activeAccount, _ := am.accountManager.ActiveAccount(); activeAccount != nil {
-						hostname = activeAccount.Hostname
-					} else {
-						// Ideally this should never happen, but use a safe fallback
-						// just in case.
-						hostname = config.DefaultHostname
+					deniedErr := &OrgAccessDeniedError{
+						OrgID:         orgId,
+						ResolutionURL: am.OrgResolutionURL(orgId),
 					}
-
-					resolutionURL := fmt.Sprintf("%s/%s", hostname, orgId)
-
-					// Always use fallback message format
-					fallbackMsg := "Access denied due to organization policy violations."
-					if policyResponse.Error != nil && *policyResponse.Error != "" {
-						fallbackMsg = fmt.Sprintf("Access denied: %s", *policyResponse.Error)
+					if policyResponse.Error != nil {
+						deniedErr.Reason = *policyResponse.Error
 					}
-					fallbackMsg += fmt.Sprintf("\n\nSee more and resolve the issues by visiting: %s", resolutionURL)
-					return false, errors.New(fallbackMsg)
+					return false, deniedErr
 				}
 
 				// Return false with a descriptive error (shouldn't reach here if Allowed is true)
@@ -667,6 +905,15 @@ func (am *AuthManager) SelectOrganization(org *api.Org) error {
 	return nil
 }
 
+// RegisterNewOlmCredentials discards the user's stored OLM credentials and
+// registers the device again. Used to recover when the stored credentials are
+// incomplete or corrupted.
+func (am *AuthManager) RegisterNewOlmCredentials(userId string) error {
+	logger.Info("Auth: discarding stored OLM credentials and re-registering device (userId=%s)", userId)
+	am.secretManager.DeleteOlmCredentials(userId)
+	return am.EnsureOlmCredentials(userId)
+}
+
 // EnsureOlmCredentials ensures OLM credentials exist for the user
 func (am *AuthManager) EnsureOlmCredentials(userId string) error {
 	if am.secretManager.HasOlmCredentials(userId) {
@@ -680,9 +927,17 @@ func (am *AuthManager) EnsureOlmCredentials(userId string) error {
 				}
 				logger.Error("Auth: OLM ID mismatch (userId=%s, server=%s, stored=%s)", userId, olm.OlmId, olmIdString)
 				am.secretManager.DeleteOlmCredentials(userId)
-			} else {
-				logger.Error("Auth: failed to verify OLM credentials (userId=%s): %v", userId, err)
+			} else if err == nil || api.IsNotFound(err) {
+				logger.Error("Auth: stored OLM no longer exists on the server (userId=%s, olmId=%s)", userId, olmIdString)
 				am.secretManager.DeleteOlmCredentials(userId)
+			} else {
+				// Only the server saying the OLM is gone justifies replacing it; after a
+				// network or server error the stored credentials are most likely still
+				// valid, and recreating them would orphan the existing OLM server-side.
+				// The GET was already retried by the API client; the caller may retry
+				// the whole connect.
+				logger.Error("Auth: failed to verify OLM credentials, keeping them (userId=%s): %v", userId, err)
+				return fmt.Errorf("failed to verify device credentials: %w", err)
 			}
 		}
 	}
@@ -708,7 +963,7 @@ func (am *AuthManager) EnsureOlmCredentials(userId string) error {
 		return nil
 	}
 
-	deviceName := config.GetFriendlyDeviceName()
+	deviceName := am.configManager.GetFriendlyDeviceName()
 	olmResponse, err := am.apiClient.CreateOlm(userId, deviceName)
 	if err != nil {
 		logger.Error("Auth: failed to create OLM (userId=%s): %v", userId, err)
@@ -725,6 +980,18 @@ func (am *AuthManager) EnsureOlmCredentials(userId string) error {
 }
 
 func (am *AuthManager) SwitchAccount(userID string) error {
+	am.switchMu.Lock()
+	defer am.switchMu.Unlock()
+
+	am.mu.Lock()
+	am.isSwitchingAccount = true
+	am.mu.Unlock()
+	defer func() {
+		am.mu.Lock()
+		am.isSwitchingAccount = false
+		am.mu.Unlock()
+	}()
+
 	accountToSwitchTo, exists := am.accountManager.Accounts[userID]
 	if !exists {
 		return errors.New("account does not exist")
@@ -745,11 +1012,13 @@ func (am *AuthManager) SwitchAccount(userID string) error {
 	am.currentUser = nil
 	am.currentOrg = nil
 	am.organizations = []api.Org{}
+	am.orgsListed = false
 	am.serverInfo = nil // Clear server info to avoid showing stale data
 	am.isAuthenticated = true
 	am.isServerDown = false
 	am.errorMessage = nil
 	am.sessionExpired = false
+	am.sessionRotatedAt = time.Time{}
 	am.mu.Unlock()
 
 	// Step 3: Validate with server (health check, fetch user, select org, fetch server info)
@@ -828,9 +1097,11 @@ func (am *AuthManager) Logout() error {
 	am.isAuthenticated = false
 	am.currentOrg = nil
 	am.organizations = []api.Org{}
+	am.orgsListed = false
 	am.errorMessage = nil
 	am.deviceAuthCode = nil
 	am.deviceAuthLoginURL = nil
+	am.pendingDeviceAuth = nil
 	am.mu.Unlock()
 
 	_ = am.secretManager.DeleteSessionToken(userID)
@@ -861,9 +1132,12 @@ func (am *AuthManager) CheckHealthAndSetState() error {
 	healthy, err := am.apiClient.CheckHealth()
 	if err != nil {
 		// Network error means server is down
+		msg := "The server appears to be down."
+		if api.IsMaintenance(err) {
+			msg = api.MaintenanceMessage
+		}
 		am.mu.Lock()
 		am.isServerDown = true
-		msg := "The server appears to be down."
 		am.errorMessage = &msg
 		am.mu.Unlock()
 		return err
@@ -894,6 +1168,11 @@ func (am *AuthManager) fetchServerInfo() error {
 
 	am.mu.Lock()
 	am.serverInfo = serverInfo
+	am.serverVersionWarning = ""
+	if !version.IsServerVersionSupported(serverInfo.Version) {
+		am.serverVersionWarning = (&UnsupportedServerError{Version: serverInfo.Version}).Error()
+		logger.Warn("%s", am.serverVersionWarning)
+	}
 	am.mu.Unlock()
 
 	return nil
@@ -913,6 +1192,13 @@ func (am *AuthManager) CurrentUser() *api.User {
 	return am.currentUser
 }
 
+// IsSwitchingAccount returns whether an account switch is in progress
+func (am *AuthManager) IsSwitchingAccount() bool {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.isSwitchingAccount
+}
+
 func (am *AuthManager) CurrentOrg() *api.Org {
 	am.mu.RLock()
 	defer am.mu.RUnlock()
@@ -925,6 +1211,14 @@ func (am *AuthManager) Organizations() []api.Org {
 	return am.organizations
 }
 
+// HasNoOrganizations reports whether the user's organizations were loaded and
+// there are none, as opposed to the list not having been loaded
+func (am *AuthManager) HasNoOrganizations() bool {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.orgsListed && len(am.organizations) == 0
+}
+
 func (am *AuthManager) IsInitializing() bool {
 	am.mu.RLock()
 	defer am.mu.RUnlock()
@@ -937,6 +1231,22 @@ func (am *AuthManager) ErrorMessage() *string {
 	return am.errorMessage
 }
 
+// SessionRotatedAt returns when the server last rotated the session token, or
+// the zero time if it has not since the app started
+func (am *AuthManager) SessionRotatedAt() time.Time {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.sessionRotatedAt
+}
+
+// ServerVersionWarning explains that the server is older than
+// version.MinServerVersion, or returns "" if it is supported
+func (am *AuthManager) ServerVersionWarning() string {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return am.serverVersionWarning
+}
+
 func (am *AuthManager) DeviceAuthCode() *string {
 	am.mu.RLock()
 	defer am.mu.RUnlock()
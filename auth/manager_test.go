@@ -0,0 +1,140 @@
+//go:build windows
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fosrl/windows/api"
+	"github.com/fosrl/windows/managers/secretstore"
+	"github.com/fosrl/windows/secrets"
+)
+
+// fakeSecretsIPC keeps secrets in memory in place of the manager service
+type fakeSecretsIPC struct {
+	mu      sync.Mutex
+	secrets map[string]secretstore.UserSecrets
+	deletes int
+}
+
+func (f *fakeSecretsIPC) Ready() bool { return true }
+
+func (f *fakeSecretsIPC) GetUserSecrets(userID string) (secretstore.UserSecrets, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.secrets[userID], nil
+}
+
+func (f *fakeSecretsIPC) SaveUserSecrets(userID string, update secretstore.SecretsUpdate) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current := f.secrets[userID]
+	if update.SetOlmId {
+		current.OlmId = update.Secrets.OlmId
+	}
+	if update.SetOlmSecret {
+		current.OlmSecret = update.Secrets.OlmSecret
+	}
+	if update.SetSessionToken {
+		current.SessionToken = update.Secrets.SessionToken
+	}
+	f.secrets[userID] = current
+	return nil
+}
+
+func (f *fakeSecretsIPC) DeleteUserSecrets(userID string, flags secretstore.DeleteSecretsFlags) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current := f.secrets[userID]
+	if flags.OlmCredentials {
+		f.deletes++
+		current.OlmId = ""
+		current.OlmSecret = ""
+	}
+	if flags.SessionToken {
+		current.SessionToken = ""
+	}
+	f.secrets[userID] = current
+	return nil
+}
+
+// newOlmTestAuthManager returns an AuthManager whose API client talks to
+// handler with a short timeout, and a secrets store holding an OLM for user-1
+func newOlmTestAuthManager(t *testing.T, handler http.HandlerFunc) (*AuthManager, *fakeSecretsIPC) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	store := &fakeSecretsIPC{secrets: map[string]secretstore.UserSecrets{
+		"user-1": {OlmId: "olm-1", OlmSecret: "secret-1"},
+	}}
+	secrets.SetIPCAPI(store)
+	t.Cleanup(func() { secrets.SetIPCAPI(nil) })
+
+	client := api.NewAPIClient(server.URL, "token", 100*time.Millisecond)
+	client.SetMaxRetries(0)
+	return NewAuthManager(client, nil, nil, secrets.NewSecretManager()), store
+}
+
+func TestEnsureOlmCredentialsServerErrorKeepsCredentials(t *testing.T) {
+	am, store := newOlmTestAuthManager(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Service unavailable"}`, http.StatusServiceUnavailable)
+	})
+
+	if err := am.EnsureOlmCredentials("user-1"); err == nil {
+		t.Fatal("EnsureOlmCredentials succeeded although the server failed")
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.deletes != 0 {
+		t.Errorf("credentials were deleted %d time(s) after a server error", store.deletes)
+	}
+	if got := store.secrets["user-1"]; got.OlmId != "olm-1" || got.OlmSecret != "secret-1" {
+		t.Errorf("stored credentials = %+v, want them kept", got)
+	}
+}
+
+func TestEnsureOlmCredentialsTimeoutKeepsCredentials(t *testing.T) {
+	release := make(chan struct{})
+	am, store := newOlmTestAuthManager(t, func(w http.ResponseWriter, r *http.Request) {
+		// Answer only after the client has given up
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+	})
+	defer close(release)
+
+	if err := am.EnsureOlmCredentials("user-1"); err == nil {
+		t.Fatal("EnsureOlmCredentials succeeded although the server timed out")
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.deletes != 0 {
+		t.Errorf("credentials were deleted %d time(s) after a timeout", store.deletes)
+	}
+	if got := store.secrets["user-1"]; got.OlmId != "olm-1" || got.OlmSecret != "secret-1" {
+		t.Errorf("stored credentials = %+v, want them kept", got)
+	}
+}
+
+func TestEnsureOlmCredentialsNotFoundDeletesCredentials(t *testing.T) {
+	am, store := newOlmTestAuthManager(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Olm not found"}`, http.StatusNotFound)
+	})
+
+	// Recreating the OLM needs the device posture IPC, which is not set up here
+	_ = am.EnsureOlmCredentials("user-1")
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.deletes != 1 {
+		t.Errorf("credentials were deleted %d time(s) after a 404, want 1", store.deletes)
+	}
+}
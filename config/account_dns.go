@@ -0,0 +1,51 @@
+//go:build windows
+
+package config
+
+// AccountDNS holds DNS settings that replace the global DNS settings for a
+// single account. Unset fields fall back to the global value.
+type AccountDNS struct {
+	DNSOverride  *bool   `json:"dnsOverride,omitempty"`
+	DNSTunnel    *bool   `json:"dnsTunnel,omitempty"`
+	PrimaryDNS   *string `json:"primaryDNS,omitempty"`
+	SecondaryDNS *string `json:"secondaryDNS,omitempty"`
+}
+
+// DNSSettings is the effective DNS configuration for an account after
+// per-account settings have been applied over the global ones
+type DNSSettings struct {
+	DNSOverride  bool
+	DNSTunnel    bool
+	PrimaryDNS   string
+	SecondaryDNS string
+}
+
+// copyAccountDNS returns a deep copy of a per-account DNS map
+func copyAccountDNS(src map[string]AccountDNS) map[string]AccountDNS {
+	if len(src) == 0 {
+		return nil
+	}
+
+	dst := make(map[string]AccountDNS, len(src))
+	for userID, settings := range src {
+		var copied AccountDNS
+		if settings.DNSOverride != nil {
+			v := *settings.DNSOverride
+			copied.DNSOverride = &v
+		}
+		if settings.DNSTunnel != nil {
+			v := *settings.DNSTunnel
+			copied.DNSTunnel = &v
+		}
+		if settings.PrimaryDNS != nil {
+			v := *settings.PrimaryDNS
+			copied.PrimaryDNS = &v
+		}
+		if settings.SecondaryDNS != nil {
+			v := *settings.SecondaryDNS
+			copied.SecondaryDNS = &v
+		}
+		dst[userID] = copied
+	}
+	return dst
+}
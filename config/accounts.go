@@ -7,6 +7,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/fosrl/newt/logger"
@@ -14,6 +16,9 @@ import (
 
 const (
 	AccountsFileName = "accounts.json"
+
+	// MaxRecentOrgs is how many recently selected organizations are kept per account
+	MaxRecentOrgs = 5
 )
 
 type AccountManager struct {
@@ -23,6 +28,10 @@ type AccountManager struct {
 
 	ActiveUserID string             `json:"activeUserId"`
 	Accounts     map[string]Account `json:"accounts"`
+
+	// AccountOrder lists user IDs in the order the user arranged them.
+	// Accounts missing from it are listed after, by email.
+	AccountOrder []string `json:"accountOrder,omitempty"`
 }
 
 type Account struct {
@@ -32,6 +41,12 @@ type Account struct {
 	Username string `json:"username"`
 	Name     string `json:"name"`
 	Hostname string `json:"hostname"`
+
+	// Label is a friendly name chosen by the user, shown instead of the email
+	Label string `json:"label,omitempty"`
+
+	// RecentOrgIDs lists recently selected organizations, most recent first
+	RecentOrgIDs []string `json:"recentOrgIds,omitempty"`
 }
 
 func NewAccountManager() *AccountManager {
@@ -107,6 +122,7 @@ func (m *AccountManager) RemoveAccount(userID string) error {
 	defer m.mu.Unlock()
 
 	delete(m.Accounts, userID)
+	m.AccountOrder = slices.DeleteFunc(m.AccountOrder, func(id string) bool { return id == userID })
 
 	if m.ActiveUserID == userID {
 		m.ActiveUserID = ""
@@ -115,6 +131,14 @@ func (m *AccountManager) RemoveAccount(userID string) error {
 	return m.saveLocked()
 }
 
+// HasAccounts reports whether any account has been added
+func (m *AccountManager) HasAccounts() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.Accounts) > 0
+}
+
 func (m *AccountManager) ActiveAccount() (*Account, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -149,6 +173,7 @@ func (m *AccountManager) SetUserOrganization(userID string, orgID string) error
 
 	if account, ok := m.Accounts[userID]; ok {
 		account.OrgID = orgID
+		account.RecentOrgIDs = addRecentOrg(account.RecentOrgIDs, orgID)
 		m.Accounts[userID] = account // Put the modified account back in the map
 	} else {
 		return errors.New("account does not exist")
@@ -171,3 +196,90 @@ func (m *AccountManager) UpdateAccountUserInfo(userID, username, name string) er
 
 	return m.saveLocked()
 }
+
+// SetAccountLabel sets the friendly label shown for an account; an empty
+// label shows the email again
+func (m *AccountManager) SetAccountLabel(userID, label string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account, ok := m.Accounts[userID]
+	if !ok {
+		return errors.New("account does not exist")
+	}
+	account.Label = strings.TrimSpace(label)
+	m.Accounts[userID] = account
+
+	return m.saveLocked()
+}
+
+// OrderedAccounts returns the accounts in the user's chosen order
+func (m *AccountManager) OrderedAccounts() []Account {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.orderedLocked()
+}
+
+func (m *AccountManager) orderedLocked() []Account {
+	ordered := make([]Account, 0, len(m.Accounts))
+	seen := make(map[string]bool)
+	for _, id := range m.AccountOrder {
+		if account, ok := m.Accounts[id]; ok && !seen[id] {
+			ordered = append(ordered, account)
+			seen[id] = true
+		}
+	}
+
+	var rest []Account
+	for id, account := range m.Accounts {
+		if !seen[id] {
+			rest = append(rest, account)
+		}
+	}
+	slices.SortFunc(rest, func(a, b Account) int {
+		if c := strings.Compare(strings.ToLower(a.Email), strings.ToLower(b.Email)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.UserID, b.UserID)
+	})
+	return append(ordered, rest...)
+}
+
+// MoveAccount moves an account up (negative offset) or down (positive offset)
+// in the account order
+func (m *AccountManager) MoveAccount(userID string, offset int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ordered := m.orderedLocked()
+	index := slices.IndexFunc(ordered, func(a Account) bool { return a.UserID == userID })
+	if index < 0 {
+		return errors.New("account does not exist")
+	}
+	target := min(max(index+offset, 0), len(ordered)-1)
+	if target == index {
+		return nil
+	}
+	account := ordered[index]
+	ordered = slices.Delete(ordered, index, index+1)
+	ordered = slices.Insert(ordered, target, account)
+
+	m.AccountOrder = make([]string, len(ordered))
+	for i, a := range ordered {
+		m.AccountOrder[i] = a.UserID
+	}
+	return m.saveLocked()
+}
+
+// addRecentOrg moves orgID to the front of recent, keeping at most MaxRecentOrgs entries
+func addRecentOrg(recent []string, orgID string) []string {
+	updated := make([]string, 0, MaxRecentOrgs)
+	updated = append(updated, orgID)
+	for _, id := range recent {
+		if id != orgID && len(updated) < MaxRecentOrgs {
+			updated = append(updated, id)
+		}
+	}
+	return updated
+}
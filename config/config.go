@@ -3,11 +3,15 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/fosrl/newt/logger"
@@ -15,43 +19,113 @@ import (
 )
 
 const (
-	AppName            = "Pangolin"
-	DefaultHostname    = "https://app.pangolin.net"
-	ConfigFileName     = "pangolin.json"
-	LogLevel           = "info"
-	DefaultDNSOverride = true
-	DefaultDNSTunnel   = false
-	DefaultMTU         = 1280
+	AppName                  = "Pangolin"
+	DefaultHostname          = "https://app.pangolin.net"
+	ConfigFileName           = "pangolin.json"
+	LogLevel                 = "info"
+	DefaultDNSOverride       = true
+	DefaultDNSTunnel         = false
+	DefaultMTU               = 1280
+	DefaultConnectRetryCount = 3
+	DefaultConnectTimeout    = 30
+	// DefaultInterfaceName is the name of the tunnel's network adapter
+	DefaultInterfaceName     = "Pangolin"
+	DefaultHolepunch         = true
+	DefaultPingInterval      = 5
+	DefaultPingTimeout       = 5
+	DefaultReconnectOnResume = true
+	DefaultLogMaxSizeMB      = 10
+	DefaultLogMaxFiles       = 5
+	// DefaultTunnelRestartRetries is how many times a crashed tunnel service
+	// is restarted before the connection is reported as failed
+	DefaultTunnelRestartRetries = 3
+	// DefaultUpdateSnoozeHours is how long "Remind Me Later" postpones the
+	// startup update prompt
+	DefaultUpdateSnoozeHours = 4
+	// DefaultAPIRetryCount is how many times a failed API GET request is retried
+	DefaultAPIRetryCount = 2
+	// DefaultBackgroundRefreshMinutes is how often account and organization
+	// changes are fetched in the background; 0 turns it off
+	DefaultBackgroundRefreshMinutes = 0
+	// SlowNetworkFactor is how much slow network mode stretches request
+	// timeouts and poll intervals
+	SlowNetworkFactor = 3
+	// ConfigSchemaVersion is the current per-user config schema; bump it and
+	// add a step to migrateConfig when the stored format changes.
+	ConfigSchemaVersion = 1
 )
 
 // Config represents the per-user application configuration stored under
 // %LOCALAPPDATA%\Pangolin\pangolin.json (or %APPDATA% as a fallback).
 type Config struct {
-	DNSOverride            *bool    `json:"dnsOverride,omitempty"`
-	DNSTunnel              *bool    `json:"dnsTunnel,omitempty"`
-	PrimaryDNS             *string  `json:"primaryDNS,omitempty"`
-	SecondaryDNS           *string  `json:"secondaryDNS,omitempty"`
-	MatchDomains           []string `json:"dnsMatchDomains,omitempty"`
-	MTU                    *int     `json:"mtu,omitempty"`
-	DefaultServerURL       *string  `json:"defaultServerURL,omitempty"`
-	UserSettingsDisabled   *bool    `json:"userSettingsDisabled,omitempty"`
-	AuthPath               *string  `json:"authPath,omitempty"`
-	OpenStatusTabOnConnect *bool    `json:"openStatusTabOnConnect,omitempty"`
-	PreferLocalRoutes      *bool    `json:"preferLocalRoutes,omitempty"`
+	SchemaVersion             int                   `json:"schemaVersion,omitempty"`
+	DNSOverride               *bool                 `json:"dnsOverride,omitempty"`
+	DNSTunnel                 *bool                 `json:"dnsTunnel,omitempty"`
+	PrimaryDNS                *string               `json:"primaryDNS,omitempty"`
+	SecondaryDNS              *string               `json:"secondaryDNS,omitempty"`
+	MatchDomains              []string              `json:"dnsMatchDomains,omitempty"`
+	MTU                       *int                  `json:"mtu,omitempty"`
+	DefaultServerURL          *string               `json:"defaultServerURL,omitempty"`
+	UserSettingsDisabled      *bool                 `json:"userSettingsDisabled,omitempty"`
+	AuthPath                  *string               `json:"authPath,omitempty"`
+	OpenStatusTabOnConnect    *bool                 `json:"openStatusTabOnConnect,omitempty"`
+	PreferLocalRoutes         *bool                 `json:"preferLocalRoutes,omitempty"`
+	AutoRetryConnect          *bool                 `json:"autoRetryConnect,omitempty"`
+	ConnectRetryCount         *int                  `json:"connectRetryCount,omitempty"`
+	Holepunch                 *bool                 `json:"holepunch,omitempty"`
+	PingIntervalSeconds       *int                  `json:"pingIntervalSeconds,omitempty"`
+	PingTimeoutSeconds        *int                  `json:"pingTimeoutSeconds,omitempty"`
+	DeviceName                *string               `json:"deviceName,omitempty"`
+	ReconnectOnResume         *bool                 `json:"reconnectOnResume,omitempty"`
+	DeclinedUpdateVersion     *string               `json:"declinedUpdateVersion,omitempty"`
+	ExcludedRoutes            []string              `json:"excludedRoutes,omitempty"`
+	ConnectSchedule           *ConnectSchedule      `json:"connectSchedule,omitempty"`
+	PreferencesOnTop          *bool                 `json:"preferencesOnTop,omitempty"`
+	OnboardingShown           *bool                 `json:"onboardingShown,omitempty"`
+	AccountDNS                map[string]AccountDNS `json:"accountDNS,omitempty"`
+	FavoriteOrgs              []string              `json:"favoriteOrgs,omitempty"`
+	AutoConnectOrgID          *string               `json:"autoConnectOrgId,omitempty"`
+	LogLevel                  *string               `json:"logLevel,omitempty"`
+	TunnelRestartRetries      *int                  `json:"tunnelRestartRetries,omitempty"`
+	ConnectTimeoutSeconds     *int                  `json:"connectTimeoutSeconds,omitempty"`
+	UpdateSnoozeHours         *int                  `json:"updateSnoozeHours,omitempty"`
+	UpdateSnoozedUntil        *time.Time            `json:"updateSnoozedUntil,omitempty"`
+	APIRetryCount             *int                  `json:"apiRetryCount,omitempty"`
+	ShowStatusWindow          *bool                 `json:"showStatusWindow,omitempty"`
+	KeepTunnelOnExit          *bool                 `json:"keepTunnelOnExit,omitempty"`
+	ConfirmQuitWhileConnected *bool                 `json:"confirmQuitWhileConnected,omitempty"`
+	ConnectOnLaunch           *bool                 `json:"connectOnLaunch,omitempty"`
+	InterfaceName             *string               `json:"interfaceName,omitempty"`
+	ConnectionMode            *string               `json:"connectionMode,omitempty"`
+	BackgroundRefreshMinutes  *int                  `json:"backgroundRefreshMinutes,omitempty"`
+	Proxy                     *string               `json:"proxy,omitempty"`
+	CACertFile                *string               `json:"caCertFile,omitempty"`
+	TLSSkipVerify             *bool                 `json:"tlsSkipVerify,omitempty"`
+	SlowNetwork               *bool                 `json:"slowNetwork,omitempty"`
 }
 
 // SystemConfig represents machine-wide configuration stored under
 // %ProgramData%\Pangolin\pangolin.json. It supports the same settings as
-// per-user config plus system-only fields like log level.
+// per-user config. Services read their log level and log rotation from it.
 type SystemConfig struct {
 	Config
-	LogLevel *string `json:"logLevel,omitempty"`
+
+	// Machine-wide only settings, not offered per user
+
+	MinOSBuild   *int    `json:"minOsBuild,omitempty"`
+	OLMPipePath  *string `json:"olmPipePath,omitempty"`
+	LogMaxSizeMB *int    `json:"logMaxSizeMB,omitempty"`
+	LogMaxFiles  *int    `json:"logMaxFiles,omitempty"`
 }
 
 // ConfigManager manages loading and saving of application configuration
 type ConfigManager struct {
 	config     *Config
 	configPath string
+	base       *Config         // defaults, system config and environment, below the user layer
+	user       *Config         // the per-user config file, the only layer save writes
+	policy     *Config         // admin-locked values, merged over everything else
+	locked     map[string]bool // names of settings locked by policy
 	mu         sync.RWMutex
 }
 
@@ -95,17 +169,37 @@ func (cm *ConfigManager) GetConfigCopy() *Config {
 }
 
 // load loads the configuration from the file
-// Returns a default config if the file doesn't exist or can't be read
+// Returns a default config if the file doesn't exist or can't be read.
+// Settings are layered, later layers winning: built-in defaults, the
+// machine-wide config file, PANGOLIN_* environment variables, the per-user
+// config written by the preferences window, then settings locked by policy.
 func (cm *ConfigManager) load() *Config {
+	cm.policy, cm.locked = loadPolicy()
+
 	// Load machine-wide defaults first, then overlay user-specific values.
-	merged := configFromSystemConfig(LoadSystemConfig())
+	cm.base = mergeConfig(configFromSystemConfig(LoadSystemConfig()), configFromEnvironment())
 
-	userCfg, ok := cm.loadUserConfig()
-	if !ok {
-		return merged
+	cm.user = &Config{}
+	if userCfg, ok := cm.loadUserConfig(); ok {
+		cm.user = userCfg
 	}
 
-	return mergeConfig(merged, userCfg)
+	return mergeConfig(mergeConfig(cm.base, cm.user), cm.policy)
+}
+
+// IsLocked returns whether a setting (one of the Policy* names) is locked by
+// admin policy and must not be changed by the user
+func (cm *ConfigManager) IsLocked(setting string) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.locked[setting]
+}
+
+// HasLockedSettings returns whether any setting is locked by admin policy
+func (cm *ConfigManager) HasLockedSettings() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return len(cm.locked) > 0
 }
 
 // Load loads the configuration from the file
@@ -117,11 +211,21 @@ func (cm *ConfigManager) Load() *Config {
 	return cm.config
 }
 
-// save saves the configuration to the file without locking
+// save saves the configuration to the file without locking. cfg is a
+// modified copy of the merged config; only the user layer is written, so
+// settings from the system config, environment or policy don't get copied
+// into the user's file.
 // Caller must hold the lock
 func (cm *ConfigManager) save(cfg *Config) bool {
+	user, err := cm.userLayer(cfg)
+	if err != nil {
+		logger.Error("Error encoding config: %v", err)
+		return false
+	}
+	user.SchemaVersion = ConfigSchemaVersion
+
 	// Marshal with pretty printing (equivalent to Swift's .prettyPrinted and .sortedKeys)
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := json.MarshalIndent(user, "", "  ")
 	if err != nil {
 		logger.Error("Error encoding config: %v", err)
 		return false
@@ -133,11 +237,69 @@ func (cm *ConfigManager) save(cfg *Config) bool {
 		return false
 	}
 
-	// Update stored config
-	cm.config = cfg
+	// Update stored config, keeping locked settings in force
+	cm.user = user
+	cm.config = mergeConfig(mergeConfig(cm.base, cm.user), cm.policy)
 	return true
 }
 
+// userLayer returns the user layer to store for cfg: the current user
+// settings, with every field where cfg differs from the merged config taken
+// from cfg, and every field cfg clears removed.
+// Caller must hold the lock
+func (cm *ConfigManager) userLayer(cfg *Config) (*Config, error) {
+	current, err := configFields(cm.config)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := configFields(cfg)
+	if err != nil {
+		return nil, err
+	}
+	user, err := configFields(cm.user)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range updated {
+		if !bytes.Equal(current[key], value) {
+			user[key] = value
+		}
+	}
+	for key := range current {
+		if _, ok := updated[key]; !ok {
+			delete(user, key)
+		}
+	}
+
+	data, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+	var layer Config
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return nil, err
+	}
+	return &layer, nil
+}
+
+// configFields returns the JSON-encoded value of each field set in cfg, keyed
+// by its JSON name
+func configFields(cfg *Config) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if cfg == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
 // Save saves the configuration to the file
 // Returns true if successful, false otherwise
 func (cm *ConfigManager) Save(cfg *Config) bool {
@@ -201,6 +363,80 @@ func (cm *ConfigManager) GetSecondaryDNS() string {
 	return ""
 }
 
+// GetDNSSettings returns the effective DNS settings for an account: its own
+// settings where set, otherwise the global ones. An empty userID returns the
+// global settings.
+func (cm *ConfigManager) GetDNSSettings(userID string) DNSSettings {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	settings := DNSSettings{
+		DNSOverride: DefaultDNSOverride,
+		DNSTunnel:   DefaultDNSTunnel,
+	}
+	if cm.config == nil {
+		return settings
+	}
+	if cm.config.DNSOverride != nil {
+		settings.DNSOverride = *cm.config.DNSOverride
+	}
+	if cm.config.DNSTunnel != nil {
+		settings.DNSTunnel = *cm.config.DNSTunnel
+	}
+	if cm.config.PrimaryDNS != nil {
+		settings.PrimaryDNS = *cm.config.PrimaryDNS
+	}
+	if cm.config.SecondaryDNS != nil {
+		settings.SecondaryDNS = *cm.config.SecondaryDNS
+	}
+
+	accountDNS, ok := cm.config.AccountDNS[userID]
+	if userID == "" || !ok {
+		return settings
+	}
+	if accountDNS.DNSOverride != nil {
+		settings.DNSOverride = *accountDNS.DNSOverride
+	}
+	if accountDNS.DNSTunnel != nil {
+		settings.DNSTunnel = *accountDNS.DNSTunnel
+	}
+	if accountDNS.PrimaryDNS != nil {
+		settings.PrimaryDNS = *accountDNS.PrimaryDNS
+	}
+	if accountDNS.SecondaryDNS != nil {
+		settings.SecondaryDNS = *accountDNS.SecondaryDNS
+	}
+
+	// Locked settings win over the account's own settings too
+	if cm.policy != nil {
+		if cm.policy.DNSOverride != nil {
+			settings.DNSOverride = *cm.policy.DNSOverride
+		}
+		if cm.policy.DNSTunnel != nil {
+			settings.DNSTunnel = *cm.policy.DNSTunnel
+		}
+		if cm.policy.PrimaryDNS != nil {
+			settings.PrimaryDNS = *cm.policy.PrimaryDNS
+		}
+		if cm.policy.SecondaryDNS != nil {
+			settings.SecondaryDNS = *cm.policy.SecondaryDNS
+		}
+	}
+	return settings
+}
+
+// HasAccountDNS returns whether the account has its own DNS settings
+func (cm *ConfigManager) HasAccountDNS(userID string) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config == nil || userID == "" {
+		return false
+	}
+	_, ok := cm.config.AccountDNS[userID]
+	return ok
+}
+
 // GetMatchDomains returns the configured FQDN wildcard match-domain patterns
 // (see olm's MatchDomains) or an empty slice if not set, meaning every domain
 // is checked against local records/upstream DNS.
@@ -224,6 +460,28 @@ func (cm *ConfigManager) SetMatchDomains(value []string) bool {
 	return cm.save(cfg)
 }
 
+// GetExcludedRoutes returns the CIDRs that should not be routed through the
+// tunnel, or an empty slice if not set
+func (cm *ConfigManager) GetExcludedRoutes() []string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil {
+		return cm.config.ExcludedRoutes
+	}
+	return nil
+}
+
+// SetExcludedRoutes sets the CIDRs excluded from the tunnel and saves to config
+func (cm *ConfigManager) SetExcludedRoutes(value []string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.ExcludedRoutes = value
+	return cm.save(cfg)
+}
+
 // GetPreferLocalRoutes returns whether tunnel routes should be added with a
 // high metric so overlapping local/connected routes take precedence, or
 // false if not set.
@@ -247,6 +505,428 @@ func (cm *ConfigManager) SetPreferLocalRoutes(value bool) bool {
 	return cm.save(cfg)
 }
 
+// GetAutoRetryConnect returns whether failed connections should be retried automatically, or false if not set
+func (cm *ConfigManager) GetAutoRetryConnect() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.AutoRetryConnect != nil {
+		return *cm.config.AutoRetryConnect
+	}
+	return false
+}
+
+// SetAutoRetryConnect sets the auto-retry setting and saves to config
+func (cm *ConfigManager) SetAutoRetryConnect(value bool) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.AutoRetryConnect = &value
+	return cm.save(cfg)
+}
+
+// GetConnectRetryCount returns how many times a failed connect is retried, or the default if not set
+func (cm *ConfigManager) GetConnectRetryCount() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.ConnectRetryCount != nil && *cm.config.ConnectRetryCount > 0 {
+		return *cm.config.ConnectRetryCount
+	}
+	return DefaultConnectRetryCount
+}
+
+// GetConnectTimeoutSeconds returns how long a connect attempt may take to
+// register before it is abandoned
+func (cm *ConfigManager) GetConnectTimeoutSeconds() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.ConnectTimeoutSeconds != nil && *cm.config.ConnectTimeoutSeconds > 0 {
+		return *cm.config.ConnectTimeoutSeconds
+	}
+	return DefaultConnectTimeout
+}
+
+// GetAPIRetryCount returns how many times a failed API GET request is retried;
+// 0 disables retries
+func (cm *ConfigManager) GetAPIRetryCount() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.APIRetryCount != nil && *cm.config.APIRetryCount >= 0 {
+		return *cm.config.APIRetryCount
+	}
+	return DefaultAPIRetryCount
+}
+
+// GetTunnelRestartRetries returns how many times a crashed tunnel service is
+// restarted; 0 disables restarting
+func (cm *ConfigManager) GetTunnelRestartRetries() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.TunnelRestartRetries != nil && *cm.config.TunnelRestartRetries >= 0 {
+		return *cm.config.TunnelRestartRetries
+	}
+	return DefaultTunnelRestartRetries
+}
+
+// SetConnectRetryCount sets the connect retry count and saves to config
+func (cm *ConfigManager) SetConnectRetryCount(value int) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.ConnectRetryCount = &value
+	return cm.save(cfg)
+}
+
+// GetHolepunch returns whether UDP holepunching is enabled, or the default if not set
+func (cm *ConfigManager) GetHolepunch() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.Holepunch != nil {
+		return *cm.config.Holepunch
+	}
+	return DefaultHolepunch
+}
+
+// GetConnectionMode returns how the tunnel reaches sites, one of
+// ConnectionModes. A holepunch policy wins over the stored mode: holepunching
+// locked off means relay, and locked on rules out relay. Otherwise
+// holepunching turned off by the user means relay, and the default is
+// ConnectionModeAuto.
+func (cm *ConfigManager) GetConnectionMode() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config == nil {
+		return ConnectionModeAuto
+	}
+	holepunchLocked := cm.policy != nil && cm.policy.Holepunch != nil
+	if holepunchLocked && !*cm.policy.Holepunch {
+		return ConnectionModeRelay
+	}
+	if !holepunchLocked && cm.config.Holepunch != nil && !*cm.config.Holepunch {
+		return ConnectionModeRelay
+	}
+	if cm.config.ConnectionMode != nil && slices.Contains(ConnectionModes, *cm.config.ConnectionMode) {
+		if holepunchLocked && *cm.config.ConnectionMode == ConnectionModeRelay {
+			return ConnectionModeAuto
+		}
+		return *cm.config.ConnectionMode
+	}
+	return ConnectionModeAuto
+}
+
+// SetHolepunch sets the holepunch setting and saves to config
+func (cm *ConfigManager) SetHolepunch(value bool) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.Holepunch = &value
+	return cm.save(cfg)
+}
+
+// GetPingIntervalSeconds returns how often peers are pinged, in seconds, or the default if not set
+func (cm *ConfigManager) GetPingIntervalSeconds() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.PingIntervalSeconds != nil && *cm.config.PingIntervalSeconds > 0 {
+		return *cm.config.PingIntervalSeconds
+	}
+	return DefaultPingInterval
+}
+
+// SetPingIntervalSeconds sets the peer ping interval and saves to config
+func (cm *ConfigManager) SetPingIntervalSeconds(value int) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.PingIntervalSeconds = &value
+	return cm.save(cfg)
+}
+
+// GetBackgroundRefreshMinutes returns how often user and organization changes
+// are fetched in the background, in minutes, or 0 if background refresh is off
+func (cm *ConfigManager) GetBackgroundRefreshMinutes() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.BackgroundRefreshMinutes != nil && *cm.config.BackgroundRefreshMinutes >= 0 {
+		return *cm.config.BackgroundRefreshMinutes
+	}
+	return DefaultBackgroundRefreshMinutes
+}
+
+// GetPingTimeoutSeconds returns how long to wait for a ping reply before a peer
+// is considered unreachable, in seconds, or the default if not set
+func (cm *ConfigManager) GetPingTimeoutSeconds() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.PingTimeoutSeconds != nil && *cm.config.PingTimeoutSeconds > 0 {
+		return *cm.config.PingTimeoutSeconds
+	}
+	return DefaultPingTimeout
+}
+
+// SetPingTimeoutSeconds sets the peer ping timeout and saves to config
+func (cm *ConfigManager) SetPingTimeoutSeconds(value int) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.PingTimeoutSeconds = &value
+	return cm.save(cfg)
+}
+
+// GetSlowNetwork returns whether slow network mode is on, or false if not set
+func (cm *ConfigManager) GetSlowNetwork() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.SlowNetwork != nil {
+		return *cm.config.SlowNetwork
+	}
+	return false
+}
+
+// ScaleForNetwork stretches a timeout or poll interval by SlowNetworkFactor
+// when slow is set
+func ScaleForNetwork(d time.Duration, slow bool) time.Duration {
+	if slow {
+		return d * SlowNetworkFactor
+	}
+	return d
+}
+
+// GetReconnectOnResume returns whether the tunnel should be verified and
+// reconnected after the system resumes from sleep, or the default if not set
+func (cm *ConfigManager) GetReconnectOnResume() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.ReconnectOnResume != nil {
+		return *cm.config.ReconnectOnResume
+	}
+	return DefaultReconnectOnResume
+}
+
+// SetReconnectOnResume sets the reconnect-on-resume setting and saves to config
+func (cm *ConfigManager) SetReconnectOnResume(value bool) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.ReconnectOnResume = &value
+	return cm.save(cfg)
+}
+
+// GetShowStatusWindow returns whether the status window is shown in the
+// taskbar, or false if not set
+func (cm *ConfigManager) GetShowStatusWindow() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.ShowStatusWindow != nil {
+		return *cm.config.ShowStatusWindow
+	}
+	return false
+}
+
+// GetKeepTunnelOnExit returns whether quitting the app leaves the tunnel
+// running in the background, or false if not set
+func (cm *ConfigManager) GetKeepTunnelOnExit() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.KeepTunnelOnExit != nil {
+		return *cm.config.KeepTunnelOnExit
+	}
+	return false
+}
+
+// GetConfirmQuitWhileConnected returns whether quitting while a tunnel is
+// connected asks for confirmation first, or true if not set
+func (cm *ConfigManager) GetConfirmQuitWhileConnected() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.ConfirmQuitWhileConnected != nil {
+		return *cm.config.ConfirmQuitWhileConnected
+	}
+	return true
+}
+
+// SetConfirmQuitWhileConnected sets whether quitting while a tunnel is
+// connected asks for confirmation first
+func (cm *ConfigManager) SetConfirmQuitWhileConnected(value bool) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.ConfirmQuitWhileConnected = &value
+	return cm.save(cfg)
+}
+
+// GetPreferencesOnTop returns whether the preferences window stays above other windows, or false if not set
+func (cm *ConfigManager) GetPreferencesOnTop() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.PreferencesOnTop != nil {
+		return *cm.config.PreferencesOnTop
+	}
+	return false
+}
+
+// SetPreferencesOnTop sets whether the preferences window stays on top and saves to config
+func (cm *ConfigManager) SetPreferencesOnTop(value bool) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.PreferencesOnTop = &value
+	return cm.save(cfg)
+}
+
+// GetLogLevel returns the configured log level, falling back to the system
+// config file and then the built-in default
+func (cm *ConfigManager) GetLogLevel() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.LogLevel != nil {
+		if level := strings.TrimSpace(*cm.config.LogLevel); level != "" {
+			return strings.ToLower(level)
+		}
+	}
+	return LogLevel
+}
+
+// SetLogLevel sets the log level and saves to config
+func (cm *ConfigManager) SetLogLevel(level string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.LogLevel = &level
+	return cm.save(cfg)
+}
+
+// GetConnectSchedule returns a copy of the connect schedule, or a disabled schedule if not set
+func (cm *ConfigManager) GetConnectSchedule() ConnectSchedule {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.ConnectSchedule != nil {
+		schedule := *cm.config.ConnectSchedule
+		schedule.Days = append([]time.Weekday(nil), cm.config.ConnectSchedule.Days...)
+		return schedule
+	}
+	return ConnectSchedule{Start: "09:00", End: "17:00"}
+}
+
+// SetConnectSchedule sets the connect schedule and saves to config
+func (cm *ConfigManager) SetConnectSchedule(value ConnectSchedule) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.ConnectSchedule = &value
+	return cm.save(cfg)
+}
+
+// GetOnboardingShown returns whether the first-run welcome window has already been shown
+func (cm *ConfigManager) GetOnboardingShown() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.OnboardingShown != nil {
+		return *cm.config.OnboardingShown
+	}
+	return false
+}
+
+// SetOnboardingShown records whether the first-run welcome window has been shown and saves to config
+func (cm *ConfigManager) SetOnboardingShown(value bool) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.OnboardingShown = &value
+	return cm.save(cfg)
+}
+
+// IsFavoriteOrg returns whether the organization is starred
+func (cm *ConfigManager) IsFavoriteOrg(orgID string) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config == nil {
+		return false
+	}
+	return slices.Contains(cm.config.FavoriteOrgs, orgID)
+}
+
+// SetFavoriteOrg stars or unstars the organization and saves to config
+func (cm *ConfigManager) SetFavoriteOrg(orgID string, favorite bool) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.FavoriteOrgs = slices.DeleteFunc(cfg.FavoriteOrgs, func(id string) bool { return id == orgID })
+	if favorite {
+		cfg.FavoriteOrgs = append(cfg.FavoriteOrgs, orgID)
+	}
+	return cm.save(cfg)
+}
+
+// GetConnectOnLaunch returns whether the tunnel is connected automatically
+// when the app starts, or false if not set
+func (cm *ConfigManager) GetConnectOnLaunch() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.ConnectOnLaunch != nil {
+		return *cm.config.ConnectOnLaunch
+	}
+	return false
+}
+
+// GetAutoConnectOrgID returns the organization pinned for automatic connects, or empty string if not set
+func (cm *ConfigManager) GetAutoConnectOrgID() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.AutoConnectOrgID != nil {
+		return *cm.config.AutoConnectOrgID
+	}
+	return ""
+}
+
+// SetAutoConnectOrgID pins the organization for automatic connects and saves to config.
+// An empty orgID removes the pin.
+func (cm *ConfigManager) SetAutoConnectOrgID(orgID string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	if orgID == "" {
+		cfg.AutoConnectOrgID = nil
+	} else {
+		cfg.AutoConnectOrgID = &orgID
+	}
+	return cm.save(cfg)
+}
+
 // GetMTU returns the MTU from config or default if not set
 func (cm *ConfigManager) GetMTU() int {
 	cm.mu.RLock()
@@ -435,6 +1115,74 @@ func GetSystemLogLevel() string {
 	return LogLevel
 }
 
+// Connection modes decide how the tunnel reaches sites
+const (
+	// ConnectionModeAuto holepunches directly to sites and falls back to the relay
+	ConnectionModeAuto = "auto"
+	// ConnectionModeRelay always connects through the relay
+	ConnectionModeRelay = "relay"
+	// ConnectionModeDirect only connects directly, never through the relay
+	ConnectionModeDirect = "direct"
+)
+
+// ConnectionModes are the connection modes offered in preferences
+var ConnectionModes = []string{ConnectionModeAuto, ConnectionModeRelay, ConnectionModeDirect}
+
+// LogLevels are the log levels offered in preferences, least verbose first
+var LogLevels = []string{"error", "warn", "info", "debug"}
+
+// ParseLogLevel converts a log level name to a logger.LogLevel. Returns INFO
+// if the name doesn't match any known level.
+func ParseLogLevel(level string) logger.LogLevel {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return logger.DEBUG
+	case "info":
+		return logger.INFO
+	case "warn":
+		return logger.WARN
+	case "error":
+		return logger.ERROR
+	case "fatal":
+		return logger.FATAL
+	default:
+		return logger.INFO
+	}
+}
+
+// ApplyLogLevel changes the log level of this process
+func ApplyLogLevel(level string) {
+	logger.GetLogger().SetLevel(ParseLogLevel(level))
+}
+
+// GetSystemLogRotation returns the size in MB at which pangolin.log is rolled
+// over and the number of archives (pangolin.log.1, .2, ...) to keep, from the
+// system config file. Log files are shared by every process, so these are
+// machine-wide only settings.
+func GetSystemLogRotation() (maxSizeMB, maxFiles int) {
+	cfg := LoadSystemConfig()
+	maxSizeMB = DefaultLogMaxSizeMB
+	if cfg.LogMaxSizeMB != nil && *cfg.LogMaxSizeMB > 0 {
+		maxSizeMB = *cfg.LogMaxSizeMB
+	}
+	maxFiles = DefaultLogMaxFiles
+	if cfg.LogMaxFiles != nil && *cfg.LogMaxFiles > 0 {
+		maxFiles = *cfg.LogMaxFiles
+	}
+	return maxSizeMB, maxFiles
+}
+
+// GetSystemOLMPipePath returns the OLM named pipe path set in the system
+// config file, or "" for the default. Both the tunnel service, which serves
+// the pipe, and the manager, which polls it, read it from here so they agree.
+func GetSystemOLMPipePath() string {
+	cfg := LoadSystemConfig()
+	if cfg.OLMPipePath != nil {
+		return strings.TrimSpace(*cfg.OLMPipePath)
+	}
+	return ""
+}
+
 // getConfigCopy creates a deep copy of the current config
 // Caller must hold the lock
 func (cm *ConfigManager) getConfigCopy() *Config {
@@ -456,12 +1204,78 @@ func (cm *ConfigManager) loadUserConfig() (*Config, bool) {
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		logger.Error("Error parsing config: %v", err)
-		return nil, false
+
+		// Keep the corrupt file so settings can be recovered by hand, then salvage what we can
+		backupPath := cm.configPath + ".bak"
+		if err := os.WriteFile(backupPath, data, 0o644); err != nil {
+			logger.Error("Failed to back up corrupt config to %s: %v", backupPath, err)
+		} else {
+			logger.Info("Backed up corrupt config to %s", backupPath)
+		}
+
+		recovered, n := recoverConfig(data)
+		if n == 0 {
+			return nil, false
+		}
+		logger.Info("Recovered %d setting(s) from corrupt config", n)
+		cfg = *recovered
 	}
 
+	migrateConfig(&cfg)
 	return &cfg, true
 }
 
+// recoverConfig does a best-effort, field-by-field parse of a config file
+// that failed to unmarshal. Top-level fields are read in order until the JSON
+// becomes unreadable; fields whose values have the wrong type are skipped.
+// Returns the recovered config and the number of fields kept.
+func recoverConfig(data []byte) (*Config, int) {
+	recovered := &Config{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return recovered, 0
+	}
+
+	n := 0
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		key, ok := tok.(string)
+		if !ok {
+			break
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			break
+		}
+
+		field, err := json.Marshal(map[string]json.RawMessage{key: value})
+		if err != nil {
+			continue
+		}
+		var partial Config
+		if err := json.Unmarshal(field, &partial); err != nil {
+			logger.Warn("Dropping unreadable config field %q: %v", key, err)
+			continue
+		}
+		recovered = mergeConfig(recovered, &partial)
+		n++
+	}
+	return recovered, n
+}
+
+// migrateConfig upgrades a config loaded from disk to the current schema version
+func migrateConfig(cfg *Config) {
+	if cfg.SchemaVersion >= ConfigSchemaVersion {
+		return
+	}
+	// Version 0 (no schemaVersion field) predates versioning and needs no changes
+	logger.Info("Migrating config from schema version %d to %d", cfg.SchemaVersion, ConfigSchemaVersion)
+	cfg.SchemaVersion = ConfigSchemaVersion
+}
+
 // configFromSystemConfig extracts shared config fields from system config.
 func configFromSystemConfig(sys *SystemConfig) *Config {
 	if sys == nil {
@@ -477,6 +1291,9 @@ func mergeConfig(base, override *Config) *Config {
 		return merged
 	}
 
+	if override.SchemaVersion != 0 {
+		merged.SchemaVersion = override.SchemaVersion
+	}
 	if override.DNSOverride != nil {
 		v := *override.DNSOverride
 		merged.DNSOverride = &v
@@ -520,6 +1337,133 @@ func mergeConfig(base, override *Config) *Config {
 		v := *override.PreferLocalRoutes
 		merged.PreferLocalRoutes = &v
 	}
+	if override.AutoRetryConnect != nil {
+		v := *override.AutoRetryConnect
+		merged.AutoRetryConnect = &v
+	}
+	if override.ConnectRetryCount != nil {
+		v := *override.ConnectRetryCount
+		merged.ConnectRetryCount = &v
+	}
+	if override.Holepunch != nil {
+		v := *override.Holepunch
+		merged.Holepunch = &v
+	}
+	if override.PingIntervalSeconds != nil {
+		v := *override.PingIntervalSeconds
+		merged.PingIntervalSeconds = &v
+	}
+	if override.PingTimeoutSeconds != nil {
+		v := *override.PingTimeoutSeconds
+		merged.PingTimeoutSeconds = &v
+	}
+	if override.DeviceName != nil {
+		v := *override.DeviceName
+		merged.DeviceName = &v
+	}
+	if override.ReconnectOnResume != nil {
+		v := *override.ReconnectOnResume
+		merged.ReconnectOnResume = &v
+	}
+	if override.DeclinedUpdateVersion != nil {
+		v := *override.DeclinedUpdateVersion
+		merged.DeclinedUpdateVersion = &v
+	}
+	if len(override.ExcludedRoutes) > 0 {
+		merged.ExcludedRoutes = append([]string(nil), override.ExcludedRoutes...)
+	}
+	if override.PreferencesOnTop != nil {
+		v := *override.PreferencesOnTop
+		merged.PreferencesOnTop = &v
+	}
+
+	if override.ConnectSchedule != nil {
+		v := *override.ConnectSchedule
+		v.Days = append([]time.Weekday(nil), override.ConnectSchedule.Days...)
+		merged.ConnectSchedule = &v
+	}
+	if override.OnboardingShown != nil {
+		v := *override.OnboardingShown
+		merged.OnboardingShown = &v
+	}
+	if len(override.AccountDNS) > 0 {
+		merged.AccountDNS = copyAccountDNS(override.AccountDNS)
+	}
+	if len(override.FavoriteOrgs) > 0 {
+		merged.FavoriteOrgs = append([]string(nil), override.FavoriteOrgs...)
+	}
+	if override.AutoConnectOrgID != nil {
+		v := *override.AutoConnectOrgID
+		merged.AutoConnectOrgID = &v
+	}
+	if override.LogLevel != nil {
+		v := *override.LogLevel
+		merged.LogLevel = &v
+	}
+	if override.TunnelRestartRetries != nil {
+		v := *override.TunnelRestartRetries
+		merged.TunnelRestartRetries = &v
+	}
+	if override.ConnectTimeoutSeconds != nil {
+		v := *override.ConnectTimeoutSeconds
+		merged.ConnectTimeoutSeconds = &v
+	}
+	if override.UpdateSnoozeHours != nil {
+		v := *override.UpdateSnoozeHours
+		merged.UpdateSnoozeHours = &v
+	}
+	if override.UpdateSnoozedUntil != nil {
+		v := *override.UpdateSnoozedUntil
+		merged.UpdateSnoozedUntil = &v
+	}
+	if override.APIRetryCount != nil {
+		v := *override.APIRetryCount
+		merged.APIRetryCount = &v
+	}
+	if override.ShowStatusWindow != nil {
+		v := *override.ShowStatusWindow
+		merged.ShowStatusWindow = &v
+	}
+	if override.KeepTunnelOnExit != nil {
+		v := *override.KeepTunnelOnExit
+		merged.KeepTunnelOnExit = &v
+	}
+	if override.ConfirmQuitWhileConnected != nil {
+		v := *override.ConfirmQuitWhileConnected
+		merged.ConfirmQuitWhileConnected = &v
+	}
+	if override.ConnectOnLaunch != nil {
+		v := *override.ConnectOnLaunch
+		merged.ConnectOnLaunch = &v
+	}
+	if override.InterfaceName != nil {
+		v := *override.InterfaceName
+		merged.InterfaceName = &v
+	}
+	if override.ConnectionMode != nil {
+		v := *override.ConnectionMode
+		merged.ConnectionMode = &v
+	}
+	if override.BackgroundRefreshMinutes != nil {
+		v := *override.BackgroundRefreshMinutes
+		merged.BackgroundRefreshMinutes = &v
+	}
+	if override.Proxy != nil {
+		v := *override.Proxy
+		merged.Proxy = &v
+	}
+	if override.CACertFile != nil {
+		v := *override.CACertFile
+		merged.CACertFile = &v
+	}
+	if override.TLSSkipVerify != nil {
+		v := *override.TLSSkipVerify
+		merged.TLSSkipVerify = &v
+	}
+	if override.SlowNetwork != nil {
+		v := *override.SlowNetwork
+		merged.SlowNetwork = &v
+	}
 
 	return merged
 }
@@ -530,7 +1474,7 @@ func copyConfig(src *Config) *Config {
 		return &Config{}
 	}
 
-	cfg := &Config{}
+	cfg := &Config{SchemaVersion: src.SchemaVersion}
 	if src.DNSOverride != nil {
 		dnsOverride := *src.DNSOverride
 		cfg.DNSOverride = &dnsOverride
@@ -574,6 +1518,130 @@ func copyConfig(src *Config) *Config {
 		preferLocalRoutes := *src.PreferLocalRoutes
 		cfg.PreferLocalRoutes = &preferLocalRoutes
 	}
+	if src.AutoRetryConnect != nil {
+		autoRetryConnect := *src.AutoRetryConnect
+		cfg.AutoRetryConnect = &autoRetryConnect
+	}
+	if src.ConnectRetryCount != nil {
+		connectRetryCount := *src.ConnectRetryCount
+		cfg.ConnectRetryCount = &connectRetryCount
+	}
+	if src.Holepunch != nil {
+		holepunch := *src.Holepunch
+		cfg.Holepunch = &holepunch
+	}
+	if src.PingIntervalSeconds != nil {
+		pingIntervalSeconds := *src.PingIntervalSeconds
+		cfg.PingIntervalSeconds = &pingIntervalSeconds
+	}
+	if src.PingTimeoutSeconds != nil {
+		pingTimeoutSeconds := *src.PingTimeoutSeconds
+		cfg.PingTimeoutSeconds = &pingTimeoutSeconds
+	}
+	if src.DeviceName != nil {
+		deviceName := *src.DeviceName
+		cfg.DeviceName = &deviceName
+	}
+	if src.ReconnectOnResume != nil {
+		reconnectOnResume := *src.ReconnectOnResume
+		cfg.ReconnectOnResume = &reconnectOnResume
+	}
+	if src.DeclinedUpdateVersion != nil {
+		declinedUpdateVersion := *src.DeclinedUpdateVersion
+		cfg.DeclinedUpdateVersion = &declinedUpdateVersion
+	}
+	if len(src.ExcludedRoutes) > 0 {
+		cfg.ExcludedRoutes = append([]string(nil), src.ExcludedRoutes...)
+	}
+	if src.PreferencesOnTop != nil {
+		preferencesOnTop := *src.PreferencesOnTop
+		cfg.PreferencesOnTop = &preferencesOnTop
+	}
+	if src.ConnectSchedule != nil {
+		schedule := *src.ConnectSchedule
+		schedule.Days = append([]time.Weekday(nil), src.ConnectSchedule.Days...)
+		cfg.ConnectSchedule = &schedule
+	}
+	if src.OnboardingShown != nil {
+		onboardingShown := *src.OnboardingShown
+		cfg.OnboardingShown = &onboardingShown
+	}
+	cfg.AccountDNS = copyAccountDNS(src.AccountDNS)
+	if len(src.FavoriteOrgs) > 0 {
+		cfg.FavoriteOrgs = append([]string(nil), src.FavoriteOrgs...)
+	}
+	if src.AutoConnectOrgID != nil {
+		autoConnectOrgID := *src.AutoConnectOrgID
+		cfg.AutoConnectOrgID = &autoConnectOrgID
+	}
+	if src.LogLevel != nil {
+		logLevel := *src.LogLevel
+		cfg.LogLevel = &logLevel
+	}
+	if src.TunnelRestartRetries != nil {
+		tunnelRestartRetries := *src.TunnelRestartRetries
+		cfg.TunnelRestartRetries = &tunnelRestartRetries
+	}
+	if src.ConnectTimeoutSeconds != nil {
+		connectTimeoutSeconds := *src.ConnectTimeoutSeconds
+		cfg.ConnectTimeoutSeconds = &connectTimeoutSeconds
+	}
+	if src.UpdateSnoozeHours != nil {
+		updateSnoozeHours := *src.UpdateSnoozeHours
+		cfg.UpdateSnoozeHours = &updateSnoozeHours
+	}
+	if src.UpdateSnoozedUntil != nil {
+		updateSnoozedUntil := *src.UpdateSnoozedUntil
+		cfg.UpdateSnoozedUntil = &updateSnoozedUntil
+	}
+	if src.APIRetryCount != nil {
+		apiRetryCount := *src.APIRetryCount
+		cfg.APIRetryCount = &apiRetryCount
+	}
+	if src.ShowStatusWindow != nil {
+		showStatusWindow := *src.ShowStatusWindow
+		cfg.ShowStatusWindow = &showStatusWindow
+	}
+	if src.KeepTunnelOnExit != nil {
+		keepTunnelOnExit := *src.KeepTunnelOnExit
+		cfg.KeepTunnelOnExit = &keepTunnelOnExit
+	}
+	if src.ConfirmQuitWhileConnected != nil {
+		confirmQuitWhileConnected := *src.ConfirmQuitWhileConnected
+		cfg.ConfirmQuitWhileConnected = &confirmQuitWhileConnected
+	}
+	if src.ConnectOnLaunch != nil {
+		connectOnLaunch := *src.ConnectOnLaunch
+		cfg.ConnectOnLaunch = &connectOnLaunch
+	}
+	if src.InterfaceName != nil {
+		interfaceName := *src.InterfaceName
+		cfg.InterfaceName = &interfaceName
+	}
+	if src.ConnectionMode != nil {
+		connectionMode := *src.ConnectionMode
+		cfg.ConnectionMode = &connectionMode
+	}
+	if src.BackgroundRefreshMinutes != nil {
+		backgroundRefreshMinutes := *src.BackgroundRefreshMinutes
+		cfg.BackgroundRefreshMinutes = &backgroundRefreshMinutes
+	}
+	if src.Proxy != nil {
+		proxy := *src.Proxy
+		cfg.Proxy = &proxy
+	}
+	if src.CACertFile != nil {
+		caCertFile := *src.CACertFile
+		cfg.CACertFile = &caCertFile
+	}
+	if src.TLSSkipVerify != nil {
+		tlsSkipVerify := *src.TLSSkipVerify
+		cfg.TLSSkipVerify = &tlsSkipVerify
+	}
+	if src.SlowNetwork != nil {
+		slowNetwork := *src.SlowNetwork
+		cfg.SlowNetwork = &slowNetwork
+	}
 	return cfg
 }
 
@@ -593,16 +1661,131 @@ func GetIconsPath() string {
 	return filepath.Join(os.Getenv("PROGRAMFILES"), AppName, "icons")
 }
 
-// GetFriendlyDeviceName returns a friendly device name like "Windows Laptop" or "Windows Desktop"
+// GetInterfaceName returns the name for the tunnel's network adapter, or
+// DefaultInterfaceName if not set
+func (cm *ConfigManager) GetInterfaceName() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.InterfaceName != nil {
+		if name := strings.TrimSpace(*cm.config.InterfaceName); name != "" {
+			return name
+		}
+	}
+	return DefaultInterfaceName
+}
+
+// GetDeviceName returns the user-configured device name, or empty string if not set
+func (cm *ConfigManager) GetDeviceName() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.DeviceName != nil {
+		return strings.TrimSpace(*cm.config.DeviceName)
+	}
+	return ""
+}
+
+// GetDeclinedUpdateVersion returns the update version the user declined, or "" if none
+func (cm *ConfigManager) GetDeclinedUpdateVersion() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.DeclinedUpdateVersion != nil {
+		return *cm.config.DeclinedUpdateVersion
+	}
+	return ""
+}
+
+// SetDeclinedUpdateVersion records the update version the user declined and saves to config
+func (cm *ConfigManager) SetDeclinedUpdateVersion(value string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	if value == "" {
+		cfg.DeclinedUpdateVersion = nil
+	} else {
+		cfg.DeclinedUpdateVersion = &value
+	}
+	return cm.save(cfg)
+}
+
+// GetUpdateSnoozeHours returns how many hours "Remind Me Later" postpones the
+// startup update prompt
+func (cm *ConfigManager) GetUpdateSnoozeHours() int {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.UpdateSnoozeHours != nil && *cm.config.UpdateSnoozeHours > 0 {
+		return *cm.config.UpdateSnoozeHours
+	}
+	return DefaultUpdateSnoozeHours
+}
+
+// IsUpdateSnoozed returns whether the user postponed the update prompt and
+// the snooze has not yet run out
+func (cm *ConfigManager) IsUpdateSnoozed() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	return cm.config != nil && cm.config.UpdateSnoozedUntil != nil && time.Now().Before(*cm.config.UpdateSnoozedUntil)
+}
+
+// SnoozeUpdate postpones the update prompt for the configured snooze interval
+// and saves to config
+func (cm *ConfigManager) SnoozeUpdate() bool {
+	until := time.Now().Add(time.Duration(cm.GetUpdateSnoozeHours()) * time.Hour)
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	cfg.UpdateSnoozedUntil = &until
+	return cm.save(cfg)
+}
+
+// SetDeviceName sets the device name and saves to config
+func (cm *ConfigManager) SetDeviceName(value string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cfg := cm.getConfigCopy()
+	value = strings.TrimSpace(value)
+	if value == "" {
+		cfg.DeviceName = nil
+	} else {
+		cfg.DeviceName = &value
+	}
+	return cm.save(cfg)
+}
+
+// GetFriendlyDeviceName returns the device name configured by the user, or
+// otherwise a name combining the hostname with the device type, like
+// "DESKTOP-AB12 (Windows Desktop)"
+func (cm *ConfigManager) GetFriendlyDeviceName() string {
+	if name := cm.GetDeviceName(); name != "" {
+		return name
+	}
+	return defaultDeviceName()
+}
+
+// defaultDeviceName returns the hostname combined with "Windows Laptop" or "Windows Desktop"
 // It attempts to detect the device type by checking for battery presence
-func GetFriendlyDeviceName() string {
+func defaultDeviceName() string {
 	// Check if system has a battery (indicates laptop)
 	hasBattery := isLaptop()
 
+	deviceType := "Windows Desktop"
 	if hasBattery {
-		return "Windows Laptop"
+		deviceType = "Windows Laptop"
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || strings.TrimSpace(hostname) == "" {
+		return deviceType
 	}
-	return "Windows Desktop"
+	return fmt.Sprintf("%s (%s)", hostname, deviceType)
 }
 
 // isLaptop attempts to determine if the system is a laptop by checking for battery presence
@@ -0,0 +1,132 @@
+//go:build windows
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecoverConfigKeepsValidFields(t *testing.T) {
+	// dnsOverride has the wrong type and the file is cut off in holepunch
+	data := []byte(`{"mtu": 1400, "dnsOverride": "yes", "primaryDNS": "1.1.1.1", "holepunch": tru`)
+
+	cfg, n := recoverConfig(data)
+	if n != 2 {
+		t.Errorf("recovered %d fields, want 2", n)
+	}
+	if cfg.MTU == nil || *cfg.MTU != 1400 {
+		t.Errorf("MTU = %v, want 1400", cfg.MTU)
+	}
+	if cfg.PrimaryDNS == nil || *cfg.PrimaryDNS != "1.1.1.1" {
+		t.Errorf("PrimaryDNS = %v, want 1.1.1.1", cfg.PrimaryDNS)
+	}
+	if cfg.DNSOverride != nil {
+		t.Errorf("DNSOverride = %v, want it dropped", *cfg.DNSOverride)
+	}
+	if cfg.Holepunch != nil {
+		t.Errorf("Holepunch = %v, want it dropped", *cfg.Holepunch)
+	}
+}
+
+func TestRecoverConfigUnreadable(t *testing.T) {
+	for _, data := range []string{"", "not json", "[1, 2]", `{"mtu": }`} {
+		if _, n := recoverConfig([]byte(data)); n != 0 {
+			t.Errorf("recoverConfig(%q) recovered %d fields, want 0", data, n)
+		}
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	cfg := &Config{}
+	migrateConfig(cfg)
+	if cfg.SchemaVersion != ConfigSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", cfg.SchemaVersion, ConfigSchemaVersion)
+	}
+
+	// A config from a newer client is left alone
+	newer := &Config{SchemaVersion: ConfigSchemaVersion + 1}
+	migrateConfig(newer)
+	if newer.SchemaVersion != ConfigSchemaVersion+1 {
+		t.Errorf("SchemaVersion = %d, want %d", newer.SchemaVersion, ConfigSchemaVersion+1)
+	}
+}
+
+func TestLoadUserConfigBacksUpCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pangolin.json")
+	data := []byte(`{"mtu": 1380, "deviceName": 7}`)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cm := &ConfigManager{configPath: path}
+	cfg, ok := cm.loadUserConfig()
+	if !ok {
+		t.Fatal("loadUserConfig found nothing to recover")
+	}
+	if cfg.MTU == nil || *cfg.MTU != 1380 {
+		t.Errorf("MTU = %v, want 1380", cfg.MTU)
+	}
+	if cfg.DeviceName != nil {
+		t.Errorf("DeviceName = %q, want it dropped", *cfg.DeviceName)
+	}
+	if cfg.SchemaVersion != ConfigSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", cfg.SchemaVersion, ConfigSchemaVersion)
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatalf("corrupt config was not backed up: %v", err)
+	}
+	if string(backup) != string(data) {
+		t.Errorf("backup = %q, want the original file", backup)
+	}
+}
+
+func TestScaleForNetwork(t *testing.T) {
+	if got := ScaleForNetwork(10*time.Second, false); got != 10*time.Second {
+		t.Errorf("ScaleForNetwork(10s, false) = %v, want 10s", got)
+	}
+	if got := ScaleForNetwork(10*time.Second, true); got != 10*time.Second*SlowNetworkFactor {
+		t.Errorf("ScaleForNetwork(10s, true) = %v, want %v", got, 10*time.Second*SlowNetworkFactor)
+	}
+}
+
+func TestSaveWritesOnlyUserLayer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pangolin.json")
+	systemMTU, lockedDNSOverride, userDeviceName := 1400, false, "laptop"
+	cm := &ConfigManager{
+		configPath: path,
+		base:       &Config{MTU: &systemMTU},
+		user:       &Config{DeviceName: &userDeviceName},
+		policy:     &Config{DNSOverride: &lockedDNSOverride},
+	}
+	cm.config = mergeConfig(mergeConfig(cm.base, cm.user), cm.policy)
+
+	if !cm.SetHolepunch(false) {
+		t.Fatal("SetHolepunch failed")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]any
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"mtu", "dnsOverride"} {
+		if _, ok := saved[key]; ok {
+			t.Errorf("saved user config contains %q from another layer: %s", key, data)
+		}
+	}
+	if saved["holepunch"] != false || saved["deviceName"] != "laptop" {
+		t.Errorf("saved user config = %s, want holepunch and deviceName", data)
+	}
+	if cm.GetHolepunch() || cm.GetDNSOverride() {
+		t.Error("merged config lost the saved or locked setting")
+	}
+}
@@ -0,0 +1,73 @@
+//go:build windows
+
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fosrl/newt/logger"
+)
+
+// Environment variables that seed configuration for managed deployments
+// (MDM/Intune). They sit between the machine-wide config file and the
+// per-user config, see ConfigManager.load for the full precedence.
+const (
+	EnvDefaultServerURL = "PANGOLIN_DEFAULT_SERVER_URL"
+	EnvDNSOverride      = "PANGOLIN_DNS_OVERRIDE"
+	EnvDNSTunnel        = "PANGOLIN_DNS_TUNNEL"
+	EnvPrimaryDNS       = "PANGOLIN_PRIMARY_DNS"
+	EnvSecondaryDNS     = "PANGOLIN_SECONDARY_DNS"
+	EnvMTU              = "PANGOLIN_MTU"
+	EnvAutoRetryConnect = "PANGOLIN_AUTO_RETRY_CONNECT"
+)
+
+// configFromEnvironment builds a config from the PANGOLIN_* environment
+// variables. Unset variables leave their field nil; invalid values are logged
+// and ignored.
+func configFromEnvironment() *Config {
+	cfg := &Config{}
+	cfg.DefaultServerURL = envString(EnvDefaultServerURL)
+	cfg.DNSOverride = envBool(EnvDNSOverride)
+	cfg.DNSTunnel = envBool(EnvDNSTunnel)
+	cfg.PrimaryDNS = envString(EnvPrimaryDNS)
+	cfg.SecondaryDNS = envString(EnvSecondaryDNS)
+	cfg.MTU = envInt(EnvMTU)
+	cfg.AutoRetryConnect = envBool(EnvAutoRetryConnect)
+	return cfg
+}
+
+func envString(name string) *string {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func envBool(name string) *bool {
+	value := envString(name)
+	if value == nil {
+		return nil
+	}
+	b, err := strconv.ParseBool(*value)
+	if err != nil {
+		logger.Warn("Ignoring %s: %q is not a valid boolean", name, *value)
+		return nil
+	}
+	return &b
+}
+
+func envInt(name string) *int {
+	value := envString(name)
+	if value == nil {
+		return nil
+	}
+	n, err := strconv.Atoi(*value)
+	if err != nil {
+		logger.Warn("Ignoring %s: %q is not a valid number", name, *value)
+		return nil
+	}
+	return &n
+}
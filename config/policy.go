@@ -0,0 +1,116 @@
+//go:build windows
+
+package config
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/fosrl/newt/logger"
+	"golang.org/x/sys/windows/registry"
+)
+
+// PolicyKeyPath is the HKLM key admins use to lock settings, e.g. through
+// Group Policy or Intune. Value names match the JSON keys in pangolin.json;
+// booleans and numbers are REG_DWORD, text is REG_SZ. A locked setting
+// overrides every other config layer and is read-only in preferences.
+const PolicyKeyPath = `SOFTWARE\Policies\` + AppName
+
+// Settings that can be locked by policy
+const (
+	PolicyDNSOverride       = "dnsOverride"
+	PolicyDNSTunnel         = "dnsTunnel"
+	PolicyPrimaryDNS        = "primaryDNS"
+	PolicySecondaryDNS      = "secondaryDNS"
+	PolicyMTU               = "mtu"
+	PolicyDefaultServerURL  = "defaultServerURL"
+	PolicyAutoRetryConnect  = "autoRetryConnect"
+	PolicyConnectRetryCount = "connectRetryCount"
+	PolicyHolepunch         = "holepunch"
+	PolicyPingInterval      = "pingIntervalSeconds"
+	PolicyPingTimeout       = "pingTimeoutSeconds"
+	PolicyReconnectOnResume = "reconnectOnResume"
+	PolicyPreferLocalRoutes = "preferLocalRoutes"
+	PolicyMinOSBuild        = "minOsBuild"
+)
+
+// GetSystemMinOSBuild returns the oldest Windows build number considered up to
+// date by the OS posture check, from policy or else the system config file,
+// or 0 if no minimum is set. Like the other posture inputs it is machine-wide.
+func GetSystemMinOSBuild() int {
+	if k, err := registry.OpenKey(registry.LOCAL_MACHINE, PolicyKeyPath, registry.QUERY_VALUE); err == nil {
+		value, _, err := k.GetIntegerValue(PolicyMinOSBuild)
+		k.Close()
+		if err == nil {
+			return int(value)
+		}
+	}
+	if cfg := LoadSystemConfig(); cfg.MinOSBuild != nil && *cfg.MinOSBuild > 0 {
+		return *cfg.MinOSBuild
+	}
+	return 0
+}
+
+// loadPolicy reads admin-locked settings from the registry. It returns the
+// locked values as a config to merge over everything else, and the set of
+// locked setting names.
+func loadPolicy() (*Config, map[string]bool) {
+	policy := &Config{}
+	locked := make(map[string]bool)
+
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, PolicyKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		if !errors.Is(err, registry.ErrNotExist) {
+			logger.Error("Failed to open policy key: %v", err)
+		}
+		return policy, locked
+	}
+	defer k.Close()
+
+	boolPolicy := func(name string, dst **bool) {
+		value, _, err := k.GetIntegerValue(name)
+		if err != nil {
+			return
+		}
+		b := value != 0
+		*dst = &b
+		locked[name] = true
+	}
+	intPolicy := func(name string, dst **int) {
+		value, _, err := k.GetIntegerValue(name)
+		if err != nil {
+			return
+		}
+		n := int(value)
+		*dst = &n
+		locked[name] = true
+	}
+	stringPolicy := func(name string, dst **string) {
+		value, _, err := k.GetStringValue(name)
+		if err != nil {
+			return
+		}
+		value = strings.TrimSpace(value)
+		*dst = &value
+		locked[name] = true
+	}
+
+	boolPolicy(PolicyDNSOverride, &policy.DNSOverride)
+	boolPolicy(PolicyDNSTunnel, &policy.DNSTunnel)
+	stringPolicy(PolicyPrimaryDNS, &policy.PrimaryDNS)
+	stringPolicy(PolicySecondaryDNS, &policy.SecondaryDNS)
+	intPolicy(PolicyMTU, &policy.MTU)
+	stringPolicy(PolicyDefaultServerURL, &policy.DefaultServerURL)
+	boolPolicy(PolicyAutoRetryConnect, &policy.AutoRetryConnect)
+	intPolicy(PolicyConnectRetryCount, &policy.ConnectRetryCount)
+	boolPolicy(PolicyHolepunch, &policy.Holepunch)
+	intPolicy(PolicyPingInterval, &policy.PingIntervalSeconds)
+	intPolicy(PolicyPingTimeout, &policy.PingTimeoutSeconds)
+	boolPolicy(PolicyReconnectOnResume, &policy.ReconnectOnResume)
+	boolPolicy(PolicyPreferLocalRoutes, &policy.PreferLocalRoutes)
+
+	if len(locked) > 0 {
+		logger.Info("Loaded %d locked setting(s) from policy", len(locked))
+	}
+	return policy, locked
+}
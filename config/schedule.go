@@ -0,0 +1,85 @@
+//go:build windows
+
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// scheduleTimeLayout is the HH:MM format used for schedule start and end times
+const scheduleTimeLayout = "15:04"
+
+// ConnectSchedule describes when the tunnel should be connected automatically.
+// Times are HH:MM in the computer's local time zone. When End is earlier than
+// Start the range runs overnight into the following day.
+type ConnectSchedule struct {
+	Enabled bool           `json:"enabled"`
+	Days    []time.Weekday `json:"days,omitempty"`
+	Start   string         `json:"start"`
+	End     string         `json:"end"`
+}
+
+// ParseScheduleTime parses an HH:MM schedule time into minutes after midnight
+func ParseScheduleTime(value string) (int, error) {
+	t, err := time.Parse(scheduleTimeLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate checks that the start and end times are valid and different
+func (s ConnectSchedule) Validate() error {
+	start, err := ParseScheduleTime(s.Start)
+	if err != nil {
+		return err
+	}
+	end, err := ParseScheduleTime(s.End)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return fmt.Errorf("start and end times must be different")
+	}
+	return nil
+}
+
+// Active reports whether t falls inside the schedule. For overnight ranges
+// the part after midnight belongs to the previous day's entry.
+func (s ConnectSchedule) Active(t time.Time) bool {
+	if !s.Enabled || len(s.Days) == 0 {
+		return false
+	}
+	start, err := ParseScheduleTime(s.Start)
+	if err != nil {
+		return false
+	}
+	end, err := ParseScheduleTime(s.End)
+	if err != nil || start == end {
+		return false
+	}
+
+	t = t.Local()
+	now := t.Hour()*60 + t.Minute()
+	if start < end {
+		return s.hasDay(t.Weekday()) && now >= start && now < end
+	}
+	// Overnight: from start until midnight today, or from midnight until end if yesterday was scheduled
+	if now >= start {
+		return s.hasDay(t.Weekday())
+	}
+	if now < end {
+		return s.hasDay((t.Weekday() + 6) % 7)
+	}
+	return false
+}
+
+func (s ConnectSchedule) hasDay(day time.Weekday) bool {
+	for _, d := range s.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+//go:build windows
+
+package config
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fosrl/newt/logger"
+)
+
+// LoadCACertFile reads a PEM file of CA certificates, e.g. the private CA of
+// a self-hosted server, and returns a pool of the system roots plus those
+// certificates
+func LoadCACertFile(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s contains no PEM encoded certificates", path)
+	}
+	return pool, nil
+}
+
+// GetCACertFile returns the path of the extra CA certificates to trust, or ""
+func (cm *ConfigManager) GetCACertFile() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.CACertFile != nil {
+		return strings.TrimSpace(*cm.config.CACertFile)
+	}
+	return ""
+}
+
+// GetTLSSkipVerify returns whether server certificates are accepted without
+// verification, or false if not set
+func (cm *ConfigManager) GetTLSSkipVerify() bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if cm.config != nil && cm.config.TLSSkipVerify != nil {
+		return *cm.config.TLSSkipVerify
+	}
+	return false
+}
+
+// GetRootCAs returns the CA pool to verify servers with, or nil for the
+// system roots, if no CA file is set or it cannot be loaded
+func (cm *ConfigManager) GetRootCAs() *x509.CertPool {
+	path := cm.GetCACertFile()
+	if path == "" {
+		return nil
+	}
+	pool, err := LoadCACertFile(path)
+	if err != nil {
+		logger.Error("Ignoring CA certificate file: %v", err)
+		return nil
+	}
+	return pool
+}
+
+// GetSystemRootCAs returns the CA pool from the CA file named in the system
+// config file, or nil if none is set or it cannot be loaded. The manager
+// service, which downloads updates, reads it from here.
+func GetSystemRootCAs() *x509.CertPool {
+	cfg := LoadSystemConfig()
+	if cfg.CACertFile == nil || *cfg.CACertFile == "" {
+		return nil
+	}
+	pool, err := LoadCACertFile(*cfg.CACertFile)
+	if err != nil {
+		logger.Error("Ignoring system CA certificate file: %v", err)
+		return nil
+	}
+	return pool
+}
+
+// GetSystemTLSSkipVerify returns whether the system config file turns off
+// certificate verification. The manager service, which downloads updates,
+// reads it from here.
+func GetSystemTLSSkipVerify() bool {
+	cfg := LoadSystemConfig()
+	return cfg.TLSSkipVerify != nil && *cfg.TLSSkipVerify
+}
@@ -20,47 +20,23 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/fosrl/windows/config"
+
 	"github.com/fosrl/newt/logger"
 	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/registry"
 )
 
-const powerShellTimeout = 60 * time.Second
-
-// One PowerShell process gathers all WMI-dependent fingerprint and posture data.
-const windowsSystemQueryScript = `
-$ErrorActionPreference = 'SilentlyContinue'
-
-$serial = (Get-CimInstance Win32_ComputerSystemProduct | Select-Object -ExpandProperty IdentifyingNumber)
-
-$bitlockerStatus = Get-BitLockerVolume -MountPoint 'C:' | Select-Object -ExpandProperty VolumeStatus
-$diskEncrypted = ($bitlockerStatus -eq 'FullyEncrypted' -or $bitlockerStatus -eq 'EncryptionInProgress')
-
-$firewallEnabled = @((Get-NetFirewallProfile | Where-Object { $_.Enabled -eq $true })).Count -gt 0
-
-$tpmAvailable = $false
-$tpm = Get-Tpm
-if ($null -ne $tpm) { $tpmAvailable = [bool]$tpm.TpmPresent }
-
-$antivirusProductStates = @(
-  Get-CimInstance -Namespace 'root/SecurityCenter2' -ClassName AntiVirusProduct |
-    ForEach-Object { [uint32]$_.productState }
-)
-
-[PSCustomObject]@{
-  serialNumber = $serial
-  diskEncrypted = [bool]$diskEncrypted
-  firewallEnabled = [bool]$firewallEnabled
-  tpmAvailable = [bool]$tpmAvailable
-  antivirusProductStates = $antivirusProductStates
-} | ConvertTo-Json -Compress
-`
+// systemQueryTimeout bounds the WMI and COM posture queries. WMI providers
+// can hang, and a stuck query must not hold up posture reporting.
+const systemQueryTimeout = 20 * time.Second
 
 type Fingerprint struct {
 	Username            string `json:"username"`
 	Hostname            string `json:"hostname"`
 	Platform            string `json:"platform"`
 	OSVersion           string `json:"osVersion"`
+	OSBuild             int    `json:"osBuild"`
 	KernelVersion       string `json:"kernelVersion"`
 	Architecture        string `json:"arch"`
 	DeviceModel         string `json:"deviceModel"`
@@ -68,16 +44,30 @@ type Fingerprint struct {
 	PlatformFingerprint string `json:"platformFingerprint"`
 }
 
+// Redacted returns a copy of the fingerprint with the serial number hidden,
+// for sharing outside the organization. The platform fingerprint is a hash
+// and is kept as is.
+func (f *Fingerprint) Redacted() *Fingerprint {
+	redacted := *f
+	if redacted.SerialNumber != "" {
+		redacted.SerialNumber = "<redacted>"
+	}
+	return &redacted
+}
+
 type PostureChecks struct {
 	// Platform-agnostic checks
 
-	DiskEncrypted   bool `json:"diskEncrypted"`
-	FirewallEnabled bool `json:"firewallEnabled"`
-	TpmAvailable    bool `json:"tpmAvailable"`
+	DiskEncrypted     bool `json:"diskEncrypted"`
+	FirewallEnabled   bool `json:"firewallEnabled"`
+	TpmAvailable      bool `json:"tpmAvailable"`
+	ScreenLockEnabled bool `json:"screenLockEnabled"`
+	OSUpToDate        bool `json:"osUpToDate"`
 
 	// Windows-specific posture check information
 
 	WindowsAntivirusEnabled bool `json:"windowsAntivirusEnabled"`
+	WindowsUpdatesPending   bool `json:"windowsUpdatesPending"`
 }
 
 type windowsSystemQueryResult struct {
@@ -110,9 +100,12 @@ func gatherDevicePosture() (*Fingerprint, *PostureChecks) {
 		hostname      string
 		osVersion     string
 		kernelVersion string
+		osBuild       int
 		deviceModel   string
 		sysQuery      windowsSystemQueryResult
 		sysQueryOK    bool
+		screenLock    bool
+		updates       bool
 		wg            sync.WaitGroup
 	)
 
@@ -122,6 +115,7 @@ func gatherDevicePosture() (*Fingerprint, *PostureChecks) {
 
 	wg.Go(func() {
 		osVersion, kernelVersion = getWindowsVersion()
+		osBuild = int(rtlGetVersion().dwBuildNumber)
 	})
 
 	wg.Go(func() {
@@ -132,6 +126,14 @@ func gatherDevicePosture() (*Fingerprint, *PostureChecks) {
 		sysQuery, sysQueryOK = gatherWindowsSystemQueries()
 	})
 
+	wg.Go(func() {
+		screenLock = screenLockEnabled()
+	})
+
+	wg.Go(func() {
+		updates = criticalUpdatesPending()
+	})
+
 	wg.Wait()
 
 	serialNumber := resolveSerialNumber(sysQueryOK, sysQuery.SerialNumber)
@@ -142,6 +144,7 @@ func gatherDevicePosture() (*Fingerprint, *PostureChecks) {
 		Hostname:            hostname,
 		Platform:            "windows",
 		OSVersion:           osVersion,
+		OSBuild:             osBuild,
 		KernelVersion:       kernelVersion,
 		Architecture:        runtime.GOARCH,
 		DeviceModel:         deviceModel,
@@ -150,6 +153,9 @@ func gatherDevicePosture() (*Fingerprint, *PostureChecks) {
 	}
 
 	postures := postureChecksFromSystemQuery(sysQuery, sysQueryOK)
+	postures.ScreenLockEnabled = screenLock
+	postures.WindowsUpdatesPending = updates
+	postures.OSUpToDate = osUpToDate(osBuild, config.GetSystemMinOSBuild())
 
 	logger.Debug("Fingerprint: gatherDevicePosture() finished (hostname=%q, model=%q, hasSerial=%v, sysQueryOK=%v)",
 		fp.Hostname, fp.DeviceModel, fp.SerialNumber != "", sysQueryOK)
@@ -170,24 +176,38 @@ func postureChecksFromSystemQuery(query windowsSystemQueryResult, ok bool) *Post
 }
 
 func gatherWindowsSystemQueries() (windowsSystemQueryResult, bool) {
-	logger.Debug("Fingerprint: gathering WMI posture and serial via single PowerShell invocation")
+	logger.Debug("Fingerprint: gathering posture and serial via native Windows APIs")
 
-	out, err := runPowerShellScript(windowsSystemQueryScript)
-	if err != nil {
-		logger.Debug("Fingerprint: system query script failed: %v", err)
-		return windowsSystemQueryResult{}, false
-	}
+	done := make(chan windowsSystemQueryResult, 1)
+	go withCOM(func() {
+		var result windowsSystemQueryResult
+		var err error
 
-	rawOutput := strings.TrimSpace(string(out))
-	logger.Debug("Fingerprint: system query raw output: %q", rawOutput)
+		if result.SerialNumber, err = wmiSerialNumber(); err != nil {
+			logger.Debug("Fingerprint: serial number query failed: %v", err)
+		}
+		if result.DiskEncrypted, err = systemDriveEncrypted(); err != nil {
+			logger.Debug("Posture check: Disk encryption - BitLocker query failed: %v", err)
+		}
+		if result.FirewallEnabled, err = firewallEnabled(); err != nil {
+			logger.Debug("Posture check: Firewall - query failed: %v", err)
+		}
+		result.TpmAvailable = tpmPresent()
+		if result.AntivirusProductStates, err = antivirusProductStates(); err != nil {
+			logger.Debug("Posture check: Antivirus - Security Center query failed: %v", err)
+		}
+		done <- result
+	})
 
 	var result windowsSystemQueryResult
-	if err := json.Unmarshal([]byte(rawOutput), &result); err != nil {
-		logger.Debug("Fingerprint: failed to parse system query JSON: %v", err)
+	select {
+	case result = <-done:
+	case <-time.After(systemQueryTimeout):
+		logger.Warn("Fingerprint: system queries timed out after %v, reporting defaults", systemQueryTimeout)
 		return windowsSystemQueryResult{}, false
 	}
 
-	logger.Debug("Fingerprint: system query parsed (hasSerial=%v, diskEncrypted=%v, firewall=%v, tpm=%v, avStates=%d)",
+	logger.Debug("Fingerprint: system queries finished (hasSerial=%v, diskEncrypted=%v, firewall=%v, tpm=%v, avStates=%d)",
 		strings.TrimSpace(result.SerialNumber) != "",
 		result.DiskEncrypted,
 		result.FirewallEnabled,
@@ -197,11 +217,21 @@ func gatherWindowsSystemQueries() (windowsSystemQueryResult, bool) {
 	return result, true
 }
 
-func runPowerShellScript(script string) ([]byte, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), powerShellTimeout)
+// runCommand runs a hidden process and returns its standard output, killing it
+// when ctx is done. Tests replace it with a fake runner.
+var runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return cmd.Output()
+}
+
+// runPowerShellScript runs script in a hidden PowerShell process, killing it
+// if it has not finished within timeout
+func runPowerShellScript(script string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(
+	out, err := runCommand(
 		ctx,
 		getPowerShellPath(),
 		"-NoProfile",
@@ -209,8 +239,11 @@ func runPowerShellScript(script string) ([]byte, error) {
 		"-Command",
 		script,
 	)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	return cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		logger.Warn("Fingerprint: PowerShell query timed out after %v, reporting defaults", timeout)
+		return nil, ctx.Err()
+	}
+	return out, err
 }
 
 type rtlOsVersionInfoEx struct {
@@ -222,7 +255,16 @@ type rtlOsVersionInfoEx struct {
 	szCSDVersion        [128]uint16
 }
 
-func getWindowsVersion() (string, string) {
+// OSVersion returns the Windows version as reported in the fingerprint, e.g.
+// "Windows 11 (10.0.22631)". Unlike GatherFingerprintInfo it is cheap.
+func OSVersion() string {
+	osVersion, _ := getWindowsVersion()
+	return osVersion
+}
+
+// rtlGetVersion returns the real Windows version, unaffected by the
+// compatibility shims that make GetVersionEx lie
+func rtlGetVersion() rtlOsVersionInfoEx {
 	ntdll := syscall.NewLazyDLL("ntdll.dll")
 	proc := ntdll.NewProc("RtlGetVersion")
 
@@ -230,6 +272,22 @@ func getWindowsVersion() (string, string) {
 	info.dwOSVersionInfoSize = uint32(unsafe.Sizeof(info))
 
 	_, _, _ = proc.Call(uintptr(unsafe.Pointer(&info)))
+	return info
+}
+
+// osUpToDate reports whether build meets minBuild. With no minimum (0) every
+// build is up to date.
+func osUpToDate(build, minBuild int) bool {
+	if minBuild <= 0 {
+		return true
+	}
+	upToDate := build >= minBuild
+	logger.Debug("Posture check: OS build - build %d, minimum %d, up to date=%v", build, minBuild, upToDate)
+	return upToDate
+}
+
+func getWindowsVersion() (string, string) {
+	info := rtlGetVersion()
 
 	marketingName := "Windows 10"
 	if info.dwMajorVersion == 10 && info.dwBuildNumber >= 22000 {
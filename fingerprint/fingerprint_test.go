@@ -0,0 +1,83 @@
+//go:build windows
+
+package fingerprint
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeRunner replaces runCommand for the duration of a test
+func fakeRunner(t *testing.T, fn func(ctx context.Context, name string, args ...string) ([]byte, error)) {
+	t.Helper()
+	previous := runCommand
+	runCommand = fn
+	t.Cleanup(func() { runCommand = previous })
+}
+
+func TestRunPowerShellScriptTimeout(t *testing.T) {
+	fakeRunner(t, func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		// A hung query: only returns once it is killed
+		<-ctx.Done()
+		return []byte("partial"), errors.New("killed")
+	})
+
+	start := time.Now()
+	out, err := runPowerShellScript("Get-Tpm", 50*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if out != nil {
+		t.Errorf("out = %q, want nil after a timeout", out)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("runPowerShellScript took %v, want it bounded by the timeout", elapsed)
+	}
+}
+
+func TestRunPowerShellScriptOutput(t *testing.T) {
+	var gotArgs []string
+	fakeRunner(t, func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		gotArgs = args
+		return []byte("True\r\n"), nil
+	})
+
+	out, err := runPowerShellScript("$true", time.Second)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if string(out) != "True\r\n" {
+		t.Errorf("out = %q, want %q", out, "True\r\n")
+	}
+	if len(gotArgs) == 0 || gotArgs[len(gotArgs)-1] != "$true" {
+		t.Errorf("args = %q, want the script as the last argument", gotArgs)
+	}
+}
+
+func TestCriticalUpdatesPendingFailsClosed(t *testing.T) {
+	resetCache := func() {
+		windowsUpdatesMu.Lock()
+		windowsUpdatesPending = false
+		windowsUpdatesChecked = time.Time{}
+		windowsUpdatesUBR = 0
+		windowsUpdatesMu.Unlock()
+	}
+	resetCache()
+	t.Cleanup(resetCache)
+
+	fakeRunner(t, func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return nil, errors.New("search failed")
+	})
+	if !criticalUpdatesPending() {
+		t.Error("a failed search reported no pending updates")
+	}
+
+	fakeRunner(t, func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return []byte("False\r\n"), nil
+	})
+	if criticalUpdatesPending() {
+		t.Error("a successful search with no updates reported pending updates")
+	}
+}
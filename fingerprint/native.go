@@ -0,0 +1,330 @@
+//go:build windows
+
+package fingerprint
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modole32    = windows.NewLazySystemDLL("ole32.dll")
+	modoleaut32 = windows.NewLazySystemDLL("oleaut32.dll")
+	modtbs      = windows.NewLazySystemDLL("tbs.dll")
+
+	procCoCreateInstance  = modole32.NewProc("CoCreateInstance")
+	procCoSetProxyBlanket = modole32.NewProc("CoSetProxyBlanket")
+	procSysAllocString    = modoleaut32.NewProc("SysAllocString")
+	procSysFreeString     = modoleaut32.NewProc("SysFreeString")
+	procVariantClear      = modoleaut32.NewProc("VariantClear")
+	procTbsiGetDeviceInfo = modtbs.NewProc("Tbsi_GetDeviceInfo")
+)
+
+var (
+	clsidNetFwPolicy2 = windows.GUID{Data1: 0xe2b3c97f, Data2: 0x6ae1, Data3: 0x41ac, Data4: [8]byte{0x81, 0x7a, 0xf6, 0xf9, 0x21, 0x66, 0xd7, 0xdd}}
+	iidINetFwPolicy2  = windows.GUID{Data1: 0x98325047, Data2: 0xc671, Data3: 0x4174, Data4: [8]byte{0x8d, 0x81, 0xde, 0xfc, 0xd3, 0xf0, 0x31, 0x86}}
+	clsidWbemLocator  = windows.GUID{Data1: 0x4590f811, Data2: 0x1d3a, Data3: 0x11d0, Data4: [8]byte{0x89, 0x1f, 0x00, 0xaa, 0x00, 0x4b, 0x2e, 0x24}}
+	iidIWbemLocator   = windows.GUID{Data1: 0xdc12a687, Data2: 0x737f, Data3: 0x11cf, Data4: [8]byte{0x88, 0x4d, 0x00, 0xaa, 0x00, 0x4b, 0x2e, 0x24}}
+)
+
+// Method slots in the COM vtables used below, counting the IUnknown (and,
+// for INetFwPolicy2, IDispatch) methods that precede them
+const (
+	vtblRelease                  = 2
+	vtblNetFwPolicy2FirewallOn   = 8
+	vtblWbemLocatorConnectServer = 3
+	vtblWbemServicesExecQuery    = 20
+	vtblEnumWbemClassObjectNext  = 4
+	vtblWbemClassObjectGet       = 4
+)
+
+const (
+	clsctxInprocServer = 0x1
+
+	netFwProfile2Domain  = 0x1
+	netFwProfile2Private = 0x2
+	netFwProfile2Public  = 0x4
+
+	wbemFlagForwardOnly       = 0x20
+	wbemFlagReturnImmediately = 0x10
+	wbemInfinite              = 0xffffffff
+
+	rpcAuthnWinNT           = 10
+	rpcAuthnLevelCall       = 3
+	rpcImpLevelImpersonate  = 3
+	tbsSuccess              = 0
+	tpmDeviceInfoVersion    = 1
+	variantBoolFalse        = 0
+	vtNull                  = 1
+	vtI4                    = 3
+	vtBool                  = 11
+	vtBSTR                  = 8
+	vtUI4                   = 19
+	bitLockerFullyEncrypted = 1
+	bitLockerEncrypting     = 2
+)
+
+// comObject is a pointer to a COM interface, whose first word points to its
+// vtable
+type comObject unsafe.Pointer
+
+// comCall invokes method slot of obj's vtable and returns its HRESULT
+func comCall(obj comObject, slot int, args ...uintptr) error {
+	vtbl := *(*unsafe.Pointer)(obj)
+	method := *(*uintptr)(unsafe.Add(vtbl, slot*int(unsafe.Sizeof(uintptr(0)))))
+	hr, _, _ := syscall.SyscallN(method, append([]uintptr{uintptr(obj)}, args...)...)
+	if int32(hr) < 0 {
+		return windows.Errno(hr)
+	}
+	return nil
+}
+
+func comRelease(obj comObject) {
+	if obj != nil {
+		_ = comCall(obj, vtblRelease)
+	}
+}
+
+func coCreateInstance(clsid, iid *windows.GUID) (comObject, error) {
+	var obj unsafe.Pointer
+	hr, _, _ := procCoCreateInstance.Call(
+		uintptr(unsafe.Pointer(clsid)),
+		0,
+		clsctxInprocServer,
+		uintptr(unsafe.Pointer(iid)),
+		uintptr(unsafe.Pointer(&obj)),
+	)
+	if int32(hr) < 0 {
+		return nil, windows.Errno(hr)
+	}
+	return comObject(obj), nil
+}
+
+// withCOM runs fn on a locked OS thread with COM initialized for it
+func withCOM(fn func()) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	// S_FALSE (already initialized), which x/sys returns as Errno(1), still
+	// needs a matching CoUninitialize; RPC_E_CHANGED_MODE means COM is usable
+	// but not ours to tear down
+	err := windows.CoInitializeEx(0, windows.COINIT_MULTITHREADED)
+	if err == nil || errors.Is(err, syscall.Errno(windows.S_FALSE)) {
+		defer windows.CoUninitialize()
+	}
+	fn()
+}
+
+func sysAllocString(s string) (uintptr, error) {
+	p, err := windows.UTF16PtrFromString(s)
+	if err != nil {
+		return 0, err
+	}
+	bstr, _, _ := procSysAllocString.Call(uintptr(unsafe.Pointer(p)))
+	if bstr == 0 {
+		return 0, errors.New("SysAllocString failed")
+	}
+	return bstr, nil
+}
+
+// variant is large enough for a VARIANT on every architecture. Only the
+// scalar and BSTR members used by the WMI queries below are read.
+type variant struct {
+	vt  uint16
+	_   [3]uint16
+	val [2]uint64
+}
+
+func (v *variant) clear() {
+	_, _, _ = procVariantClear.Call(uintptr(unsafe.Pointer(v)))
+}
+
+// value returns the VARIANT's value as a string, uint32 or bool, or nil
+func (v *variant) value() any {
+	switch v.vt {
+	case vtBSTR:
+		bstr := *(**uint16)(unsafe.Pointer(&v.val))
+		return windows.UTF16PtrToString(bstr)
+	case vtI4, vtUI4:
+		return uint32(v.val[0])
+	case vtBool:
+		return int16(v.val[0]) != variantBoolFalse
+	default:
+		return nil
+	}
+}
+
+// wmiQuery runs a WQL query in namespace and returns the requested property
+// of every result. COM must be initialized on the calling thread.
+func wmiQuery(namespace, query, property string) ([]any, error) {
+	locator, err := coCreateInstance(&clsidWbemLocator, &iidIWbemLocator)
+	if err != nil {
+		return nil, fmt.Errorf("create WbemLocator: %w", err)
+	}
+	defer comRelease(locator)
+
+	bstrNamespace, err := sysAllocString(namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer procSysFreeString.Call(bstrNamespace)
+
+	var services unsafe.Pointer
+	if err := comCall(locator, vtblWbemLocatorConnectServer,
+		bstrNamespace, 0, 0, 0, 0, 0, 0,
+		uintptr(unsafe.Pointer(&services)),
+	); err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", namespace, err)
+	}
+	defer comRelease(comObject(services))
+
+	// WMI needs impersonation, which is not the process default
+	hr, _, _ := procCoSetProxyBlanket.Call(
+		uintptr(services),
+		rpcAuthnWinNT,
+		0,
+		0,
+		rpcAuthnLevelCall,
+		rpcImpLevelImpersonate,
+		0,
+		0,
+	)
+	if int32(hr) < 0 {
+		return nil, fmt.Errorf("set proxy blanket: %w", windows.Errno(hr))
+	}
+
+	bstrLanguage, err := sysAllocString("WQL")
+	if err != nil {
+		return nil, err
+	}
+	defer procSysFreeString.Call(bstrLanguage)
+	bstrQuery, err := sysAllocString(query)
+	if err != nil {
+		return nil, err
+	}
+	defer procSysFreeString.Call(bstrQuery)
+
+	var enum unsafe.Pointer
+	if err := comCall(comObject(services), vtblWbemServicesExecQuery,
+		bstrLanguage,
+		bstrQuery,
+		wbemFlagForwardOnly|wbemFlagReturnImmediately,
+		0,
+		uintptr(unsafe.Pointer(&enum)),
+	); err != nil {
+		return nil, fmt.Errorf("query %q: %w", query, err)
+	}
+	defer comRelease(comObject(enum))
+
+	name, err := windows.UTF16PtrFromString(property)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []any
+	for {
+		var object unsafe.Pointer
+		var returned uint32
+		err := comCall(comObject(enum), vtblEnumWbemClassObjectNext,
+			wbemInfinite,
+			1,
+			uintptr(unsafe.Pointer(&object)),
+			uintptr(unsafe.Pointer(&returned)),
+		)
+		if err != nil {
+			return values, fmt.Errorf("read results of %q: %w", query, err)
+		}
+		if returned == 0 {
+			return values, nil
+		}
+
+		var v variant
+		err = comCall(comObject(object), vtblWbemClassObjectGet,
+			uintptr(unsafe.Pointer(name)),
+			0,
+			uintptr(unsafe.Pointer(&v)),
+			0,
+			0,
+		)
+		if err == nil && v.vt != vtNull {
+			values = append(values, v.value())
+		}
+		v.clear()
+		comRelease(comObject(object))
+	}
+}
+
+// firewallEnabled reports whether Windows Firewall is on for any profile,
+// as reported by INetFwPolicy2
+func firewallEnabled() (bool, error) {
+	policy, err := coCreateInstance(&clsidNetFwPolicy2, &iidINetFwPolicy2)
+	if err != nil {
+		return false, fmt.Errorf("create NetFwPolicy2: %w", err)
+	}
+	defer comRelease(policy)
+
+	for _, profile := range []uintptr{netFwProfile2Domain, netFwProfile2Private, netFwProfile2Public} {
+		var enabled int16
+		if err := comCall(policy, vtblNetFwPolicy2FirewallOn, profile, uintptr(unsafe.Pointer(&enabled))); err != nil {
+			return false, fmt.Errorf("read firewall state: %w", err)
+		}
+		if enabled != variantBoolFalse {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// tpmPresent reports whether TPM Base Services finds a TPM
+func tpmPresent() bool {
+	if err := procTbsiGetDeviceInfo.Find(); err != nil {
+		return false
+	}
+	// TPM_DEVICE_INFO: structVersion, tpmVersion, tpmInterfaceType, tpmImpRevision
+	info := [4]uint32{tpmDeviceInfoVersion}
+	r, _, _ := procTbsiGetDeviceInfo.Call(unsafe.Sizeof(info), uintptr(unsafe.Pointer(&info)))
+	return r == tbsSuccess
+}
+
+// systemDriveEncrypted reports whether BitLocker has encrypted, or is
+// encrypting, drive C:
+func systemDriveEncrypted() (bool, error) {
+	values, err := wmiQuery(
+		`ROOT\CIMV2\Security\MicrosoftVolumeEncryption`,
+		"SELECT ConversionStatus FROM Win32_EncryptableVolume WHERE DriveLetter = 'C:'",
+		"ConversionStatus",
+	)
+	if err != nil || len(values) == 0 {
+		return false, err
+	}
+	status, _ := values[0].(uint32)
+	return status == bitLockerFullyEncrypted || status == bitLockerEncrypting, nil
+}
+
+// wmiSerialNumber returns the serial number WMI reports for the machine
+func wmiSerialNumber() (string, error) {
+	values, err := wmiQuery(`ROOT\CIMV2`, "SELECT IdentifyingNumber FROM Win32_ComputerSystemProduct", "IdentifyingNumber")
+	if err != nil || len(values) == 0 {
+		return "", err
+	}
+	serial, _ := values[0].(string)
+	return serial, nil
+}
+
+// antivirusProductStates returns the productState of every antivirus product
+// registered with Windows Security Center
+func antivirusProductStates() ([]uint32, error) {
+	values, err := wmiQuery(`ROOT\SecurityCenter2`, "SELECT productState FROM AntiVirusProduct", "productState")
+	states := make([]uint32, 0, len(values))
+	for _, value := range values {
+		if state, ok := value.(uint32); ok {
+			states = append(states, state)
+		}
+	}
+	return states, err
+}
@@ -0,0 +1,88 @@
+//go:build windows
+
+package fingerprint
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fosrl/newt/logger"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// desktopPolicyKey holds screen saver settings enforced by group policy,
+	// which take precedence over the user's own settings
+	desktopPolicyKey = `Software\Policies\Microsoft\Windows\Control Panel\Desktop`
+	// desktopSettingsKey holds the user's screen saver settings
+	desktopSettingsKey = `Control Panel\Desktop`
+	// systemPolicyKey holds the machine inactivity limit that locks the session
+	systemPolicyKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\Policies\System`
+)
+
+// screenLockEnabled reports whether the interactive session locks by itself:
+// either the machine inactivity limit is set, or a password-protected screen
+// saver with a timeout is configured for the console user.
+func screenLockEnabled() bool {
+	if k, err := registry.OpenKey(registry.LOCAL_MACHINE, systemPolicyKey, registry.QUERY_VALUE); err == nil {
+		timeout, _, err := k.GetIntegerValue("InactivityTimeoutSecs")
+		k.Close()
+		if err == nil && timeout > 0 {
+			logger.Debug("Posture check: Screen lock - machine inactivity limit is %d seconds", timeout)
+			return true
+		}
+	}
+
+	root, prefix, err := consoleUserHive()
+	if err != nil {
+		logger.Debug("Posture check: Screen lock - could not find the console user's registry hive: %v", err)
+		return false
+	}
+
+	// Policy values override the user's values one by one
+	setting := func(name string) string {
+		for _, path := range []string{desktopPolicyKey, desktopSettingsKey} {
+			k, err := registry.OpenKey(root, prefix+path, registry.QUERY_VALUE)
+			if err != nil {
+				continue
+			}
+			value, _, err := k.GetStringValue(name)
+			k.Close()
+			if err == nil {
+				return strings.TrimSpace(value)
+			}
+		}
+		return ""
+	}
+
+	active := setting("ScreenSaveActive") == "1"
+	secure := setting("ScreenSaverIsSecure") == "1"
+	timeout, _ := strconv.Atoi(setting("ScreenSaveTimeOut"))
+	logger.Debug("Posture check: Screen lock - screen saver active=%v, secure=%v, timeout=%ds", active, secure, timeout)
+	return active && secure && timeout > 0
+}
+
+// consoleUserHive returns the registry root and key prefix for the signed-in
+// console user. When running as a service (LocalSystem), HKEY_CURRENT_USER is
+// the system's own hive, so the user's hive is opened under HKEY_USERS.
+func consoleUserHive() (registry.Key, string, error) {
+	self, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return 0, "", err
+	}
+	if !self.User.Sid.IsWellKnown(windows.WinLocalSystemSid) {
+		return registry.CURRENT_USER, "", nil
+	}
+
+	var userToken windows.Token
+	if err := windows.WTSQueryUserToken(windows.WTSGetActiveConsoleSessionId(), &userToken); err != nil {
+		return 0, "", err
+	}
+	defer userToken.Close()
+	tokenUser, err := userToken.GetTokenUser()
+	if err != nil {
+		return 0, "", err
+	}
+	return registry.USERS, tokenUser.User.Sid.String() + `\`, nil
+}
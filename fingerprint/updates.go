@@ -0,0 +1,102 @@
+//go:build windows
+
+package fingerprint
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fosrl/newt/logger"
+	"golang.org/x/sys/windows/registry"
+)
+
+// windowsUpdatesCacheDuration is how long a search that found no pending
+// updates is reused. The search goes through the Windows Update Agent COM API
+// and can take many seconds, while new updates arrive rarely.
+const windowsUpdatesCacheDuration = 6 * time.Hour
+
+// windowsUpdatesPendingCacheDuration is how long a search that found pending
+// updates is reused, kept short so installing them clears the result soon
+const windowsUpdatesPendingCacheDuration = 15 * time.Minute
+
+// windowsUpdatesTimeout bounds the Windows Update search
+const windowsUpdatesTimeout = 60 * time.Second
+
+// Searches the Windows Update Agent's cached scan results (no network access)
+// for missing, non-hidden software updates that are critical or security
+// updates, or rated Critical by MSRC. Prints True or False.
+const windowsUpdatesQueryScript = `
+$ErrorActionPreference = 'Stop'
+
+$searcher = (New-Object -ComObject Microsoft.Update.Session).CreateUpdateSearcher()
+$searcher.Online = $false
+$result = $searcher.Search("IsInstalled=0 and IsHidden=0 and Type='Software'")
+
+$pending = $false
+foreach ($update in $result.Updates) {
+  if ($update.MsrcSeverity -eq 'Critical') { $pending = $true; break }
+  foreach ($category in $update.Categories) {
+    if ($category.Name -eq 'Critical Updates' -or $category.Name -eq 'Security Updates') { $pending = $true; break }
+  }
+  if ($pending) { break }
+}
+$pending
+`
+
+var (
+	windowsUpdatesMu      sync.Mutex
+	windowsUpdatesPending bool
+	windowsUpdatesChecked time.Time
+	windowsUpdatesUBR     uint64
+)
+
+// updateBuildRevision returns the OS update build revision (UBR), which
+// changes when a cumulative update is installed
+func updateBuildRevision() uint64 {
+	k, err := registry.OpenKey(
+		registry.LOCAL_MACHINE,
+		`SOFTWARE\Microsoft\Windows NT\CurrentVersion`,
+		registry.QUERY_VALUE,
+	)
+	if err != nil {
+		return 0
+	}
+	defer k.Close()
+
+	ubr, _, _ := k.GetIntegerValue("UBR")
+	return ubr
+}
+
+// criticalUpdatesPending reports whether critical or security updates are
+// waiting to be installed, reusing the last result while it is fresh. A
+// failed or timed out search can't show the device is up to date, so it
+// reports true (fail closed) and is retried on the next call.
+func criticalUpdatesPending() bool {
+	windowsUpdatesMu.Lock()
+	defer windowsUpdatesMu.Unlock()
+
+	// A pending result is dropped early, and as soon as an update is installed
+	cacheDuration := windowsUpdatesCacheDuration
+	if windowsUpdatesPending {
+		cacheDuration = windowsUpdatesPendingCacheDuration
+	}
+	ubr := updateBuildRevision()
+	if !windowsUpdatesChecked.IsZero() && time.Since(windowsUpdatesChecked) < cacheDuration && ubr == windowsUpdatesUBR {
+		return windowsUpdatesPending
+	}
+
+	out, err := runPowerShellScript(windowsUpdatesQueryScript, windowsUpdatesTimeout)
+	result := strings.TrimSpace(string(out))
+	if err != nil || (!strings.EqualFold(result, "True") && !strings.EqualFold(result, "False")) {
+		logger.Warn("Posture check: Windows updates - search failed, reporting updates pending: %v (output=%q)", err, result)
+		windowsUpdatesChecked = time.Time{}
+		return true
+	}
+
+	windowsUpdatesPending = strings.EqualFold(result, "True")
+	windowsUpdatesChecked = time.Now()
+	windowsUpdatesUBR = ubr
+	logger.Debug("Posture check: Windows updates - critical updates pending=%v", windowsUpdatesPending)
+	return windowsUpdatesPending
+}
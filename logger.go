@@ -7,41 +7,28 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fosrl/windows/config"
 
 	"github.com/fosrl/newt/logger"
+	"golang.org/x/sys/windows"
 )
 
-// stringToLogLevel converts a string log level to logger.LogLevel.
-// Returns INFO as default if the string doesn't match any known level.
-func stringToLogLevel(levelStr string) logger.LogLevel {
-	switch strings.ToLower(levelStr) {
-	case "debug":
-		return logger.DEBUG
-	case "info":
-		return logger.INFO
-	case "warn":
-		return logger.WARN
-	case "error":
-		return logger.ERROR
-	case "fatal":
-		return logger.FATAL
-	default:
-		return logger.INFO
-	}
-}
+// logRotationCheckInterval is how often the log file size is checked
+const logRotationCheckInterval = 30 * time.Second
 
 // setupLogging initializes the logger and sets up log file output with rotation
 func setupLogging() {
-	// Initialize the logger and set log level FIRST, before any logging calls
-	logInstance := logger.GetLogger()
+	// Initialize the logger and set log level FIRST, before any logging calls.
+	// Its writer is the rotator, which writes to stdout until the file is open.
+	rotator := &logRotator{writer: logger.NewStandardWriter()}
+	logInstance := logger.Init(logger.NewLoggerWithWriter(rotator))
 
 	// Resolve log level from system config file (with built-in default fallback)
 	logLevelStr := config.GetSystemLogLevel()
-	logLevel := stringToLogLevel(logLevelStr)
-	logInstance.SetLevel(logLevel)
+	logInstance.SetLevel(config.ParseLogLevel(logLevelStr))
 
 	// Create log directory if it doesn't exist
 	logDir := config.GetLogDir()
@@ -52,61 +39,150 @@ func setupLogging() {
 	}
 
 	logFile := filepath.Join(logDir, "pangolin.log")
+	maxSizeMB, maxFiles := config.GetSystemLogRotation()
 
-	// Rotate log file if needed
-	err = rotateLogFile(logDir, logFile)
-	if err != nil {
-		logger.Error("Failed to rotate log file: %v", err)
-		// Continue anyway to create new log file
-	}
+	// Older versions rotated daily to pangolin-YYYY-MM-DD.log
+	cleanupOldLogFiles(logDir, 3)
 
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
+	if err := rotator.open(logFile, int64(maxSizeMB)*1024*1024, maxFiles); err != nil {
 		logger.Error("Failed to open log file: %v", err)
 		return
 	}
 
-	// Set the custom logger output
-	logInstance.SetOutput(file)
-
-	logger.Info("Pangolin logging initialized - log file: %s, log level: %s", logFile, logLevelStr)
+	logger.Info("Pangolin logging initialized - log file: %s, log level: %s, rotate at %d MB, keep %d", logFile, logLevelStr, maxSizeMB, maxFiles)
 }
 
-// rotateLogFile handles daily log rotation
-func rotateLogFile(logDir string, logFile string) error {
-	// Get current log file info
-	info, err := os.Stat(logFile)
+// openLogFile opens the log file for appending. The UI, manager and tunnel
+// processes all write to the same file, so it is opened with FILE_SHARE_DELETE
+// to let whichever process notices it has grown too large rename it while the
+// others still have it open.
+func openLogFile(path string) (*os.File, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No current log file to rotate
-		}
-		return fmt.Errorf("failed to stat log file: %v", err)
+		return nil, err
+	}
+	handle, err := windows.CreateFile(
+		pathPtr,
+		windows.FILE_APPEND_DATA|windows.SYNCHRONIZE,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|windows.FILE_SHARE_DELETE,
+		nil,
+		windows.OPEN_ALWAYS,
+		windows.FILE_ATTRIBUTE_NORMAL,
+		0,
+	)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
 	}
+	return os.NewFile(uintptr(handle), path), nil
+}
 
-	// Check if log file is from today
-	now := time.Now()
-	fileTime := info.ModTime()
+// logRotator rolls pangolin.log over to pangolin.log.1 once it reaches
+// maxSize, shifting older archives up and keeping at most maxFiles of them.
+// It is the logger's writer, so rotating and writing are serialized by mu.
+type logRotator struct {
+	mu        sync.Mutex
+	writer    *logger.StandardWriter
+	path      string
+	maxSize   int64
+	maxFiles  int
+	file      *os.File
+	lastCheck time.Time
+}
+
+// open starts writing to the log file at path, rotating it first if it is
+// already too large
+func (r *logRotator) open(path string, maxSize int64, maxFiles int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.path = path
+	r.maxSize = maxSize
+	r.maxFiles = maxFiles
+
+	var rotateErr error
+	if info, err := os.Stat(path); err == nil && info.Size() >= maxSize {
+		// Continue anyway and keep appending to the current file
+		rotateErr = r.rotate()
+	}
+	file, err := openLogFile(path)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.writer.SetOutput(file)
+	r.lastCheck = time.Now()
+	if rotateErr != nil {
+		r.writer.Write(logger.ERROR, time.Now(), fmt.Sprintf("Failed to rotate log file: %v", rotateErr))
+	}
+	return nil
+}
 
-	// If the log file is from today, no rotation needed
-	if now.Year() == fileTime.Year() && now.YearDay() == fileTime.YearDay() {
-		return nil
+// Write implements logger.LogWriter. Every logRotationCheckInterval it first
+// rotates or reopens the log file as needed.
+func (r *logRotator) Write(level logger.LogLevel, timestamp time.Time, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil && time.Since(r.lastCheck) >= logRotationCheckInterval {
+		r.lastCheck = time.Now()
+		r.check()
 	}
+	r.writer.Write(level, timestamp, message)
+}
 
-	// Create rotated filename with date
-	rotatedName := fmt.Sprintf("pangolin-%s.log", fileTime.Format("2006-01-02"))
-	rotatedPath := filepath.Join(logDir, rotatedName)
+// check rotates the log file when it is too large. If another process already
+// rotated it, the file is reopened so this process follows the new file. The
+// caller must hold mu.
+func (r *logRotator) check() {
+	info, err := os.Stat(r.path)
+	if err == nil {
+		current, statErr := r.file.Stat()
+		if statErr == nil && !os.SameFile(info, current) {
+			r.reopen()
+			return
+		}
+		if info.Size() < r.maxSize {
+			return
+		}
+		if err := r.rotate(); err != nil {
+			// Most likely the log viewer has the file open; try again next time
+			return
+		}
+	} else if !os.IsNotExist(err) {
+		return
+	}
+	r.reopen()
+}
 
-	// Rename current log file to dated filename
-	err = os.Rename(logFile, rotatedPath)
-	if err != nil {
+// rotate shifts the existing archives up by one and renames the log file to
+// pangolin.log.1, dropping the oldest archive beyond maxFiles
+func (r *logRotator) rotate() error {
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxFiles))
+	for i := r.maxFiles - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to shift log archive %d: %v", i, err)
+		}
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
 		return fmt.Errorf("failed to rotate log file: %v", err)
 	}
-
-	cleanupOldLogFiles(logDir, 3)
 	return nil
 }
 
-// cleanupOldLogFiles removes log files older than specified days
+// reopen switches to a freshly opened log file. The caller must hold mu, so
+// no write is still using the old file when it is closed.
+func (r *logRotator) reopen() {
+	file, err := openLogFile(r.path)
+	if err != nil {
+		r.writer.Write(logger.ERROR, time.Now(), fmt.Sprintf("Failed to reopen log file: %v", err))
+		return
+	}
+	r.file.Close()
+	r.file = file
+	r.writer.SetOutput(file)
+	r.writer.Write(logger.INFO, time.Now(), fmt.Sprintf("Log file rotated - log file: %s", r.path))
+}
+
+// cleanupOldLogFiles removes dated log files older than specified days
 func cleanupOldLogFiles(logDir string, daysToKeep int) {
 	cutoff := time.Now().AddDate(0, 0, -daysToKeep)
 	files, err := os.ReadDir(logDir)
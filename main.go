@@ -249,6 +249,15 @@ func main() {
 	configManager := config.NewConfigManager()
 	secretManager := secrets.NewSecretManager()
 
+	// Switch to the log level picked in preferences, here and in the manager
+	logLevel := configManager.GetLogLevel()
+	config.ApplyLogLevel(logLevel)
+	if managers.IPCClientReady() {
+		if err := managers.IPCClientSetLogLevel(logLevel); err != nil {
+			logger.Error("Failed to set manager log level: %v", err)
+		}
+	}
+
 	var hostname string
 	if activeAccount, _ := accountManager.ActiveAccount(); activeAccount != nil {
 		hostname = activeAccount.Hostname
@@ -256,7 +265,10 @@ func main() {
 		hostname = config.DefaultHostname
 	}
 
-	apiClient := api.NewAPIClient(hostname, "")
+	apiClient := api.NewAPIClient(hostname, "", config.ScaleForNetwork(api.DefaultTimeout, configManager.GetSlowNetwork()))
+	apiClient.SetMaxRetries(configManager.GetAPIRetryCount())
+	apiClient.SetProxy(secretManager.ProxyURL(configManager))
+	apiClient.SetTLS(configManager.GetRootCAs(), configManager.GetTLSSkipVerify())
 	authManager := auth.NewAuthManager(apiClient, configManager, accountManager, secretManager)
 
 	// When any authenticated request gets 401/403, set session-expired on the UI thread
@@ -264,6 +276,9 @@ func main() {
 		walk.App().Synchronize(authManager.MarkSessionExpired)
 	})
 
+	// Persist session tokens rotated by the server so the next start uses the new one
+	apiClient.SetOnSessionRotated(authManager.HandleSessionRotated)
+
 	// Initialize auth manager (loads saved session token if available)
 	if err := authManager.Initialize(); err != nil {
 		logger.Error("Failed to initialize auth manager: %v", err)
@@ -284,6 +299,8 @@ func main() {
 		// This standalone mode is mainly for development/testing
 	}
 
-	// Run the application
-	app.Run()
+	// Run the application; a non-zero code asks the manager to start the UI again
+	if exitCode := app.Run(); exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
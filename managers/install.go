@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fosrl/newt/logger"
 	"github.com/fosrl/windows/config"
 	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/svc"
@@ -135,10 +136,7 @@ func InstallTunnel(configJSON string) error {
 	}
 
 	// Create service name (Windows service names have restrictions)
-	serviceName := config.AppName + "Tunnel$" + sanitizeServiceName(name)
-	if len(serviceName) > 80 {
-		serviceName = serviceName[:80]
-	}
+	serviceName := tunnelServiceName(name)
 
 	// Check if service already exists
 	service, err := m.OpenService(serviceName)
@@ -213,10 +211,7 @@ func UninstallTunnel(name string) error {
 		return err
 	}
 
-	serviceName := config.AppName + "Tunnel$" + sanitizeServiceName(name)
-	if len(serviceName) > 80 {
-		serviceName = serviceName[:80]
-	}
+	serviceName := tunnelServiceName(name)
 
 	service, err := m.OpenService(serviceName)
 	if err != nil {
@@ -241,6 +236,51 @@ func UninstallTunnel(name string) error {
 	return nil
 }
 
+// tunnelServicePrefix is the service name prefix shared by all tunnel services
+const tunnelServicePrefix = config.AppName + "Tunnel$"
+
+// tunnelServiceName returns the Windows service name for the named tunnel
+func tunnelServiceName(name string) string {
+	serviceName := tunnelServicePrefix + sanitizeServiceName(name)
+	if len(serviceName) > 80 {
+		serviceName = serviceName[:80]
+	}
+	return serviceName
+}
+
+// UninstallStrayTunnels stops and removes tunnel services the manager is not
+// tracking, e.g. ones left behind when the manager restarted while connected
+func UninstallStrayTunnels() error {
+	m, err := serviceManager()
+	if err != nil {
+		return err
+	}
+	names, err := m.ListServices()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, serviceName := range names {
+		if !strings.HasPrefix(serviceName, tunnelServicePrefix) {
+			continue
+		}
+		service, err := m.OpenService(serviceName)
+		if err != nil {
+			continue
+		}
+		service.Control(svc.Stop)
+		err = service.Delete()
+		service.Close()
+		if err != nil && err != windows.ERROR_SERVICE_MARKED_FOR_DELETE {
+			errs = append(errs, fmt.Errorf("%s: %w", serviceName, err))
+			continue
+		}
+		logger.Info("Removed stray tunnel service %s", serviceName)
+	}
+	return errors.Join(errs...)
+}
+
 func waitForServiceStopped(service *mgr.Service, timeout time.Duration) bool {
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
@@ -2,7 +2,11 @@
 
 package managers
 
-import "github.com/fosrl/windows/tunnel"
+import (
+	"time"
+
+	"github.com/fosrl/windows/tunnel"
+)
 
 // IPCAdapter implements tunnel.IPCClient interface to avoid circular dependencies
 type IPCAdapter struct{}
@@ -32,3 +36,13 @@ func (a *IPCAdapter) RegisterStateChangeCallback(cb func(tunnel.State)) func() {
 		callback.Unregister()
 	}
 }
+
+// GetTunnelStatus returns the OLM status fetched by the manager service
+func (a *IPCAdapter) GetTunnelStatus(timeout time.Duration) (*tunnel.OLMStatusResponse, error) {
+	return IPCClientGetTunnelStatus(timeout)
+}
+
+// SwitchTunnelOrg has the manager service switch OLM's organization
+func (a *IPCAdapter) SwitchTunnelOrg(orgID string, timeout time.Duration) error {
+	return IPCClientSwitchTunnelOrg(orgID, timeout)
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/fosrl/newt/logger"
 	"github.com/fosrl/windows/fingerprint"
@@ -25,6 +26,8 @@ const (
 	UpdateFoundNotificationType
 	UpdateProgressNotificationType
 	TunnelStateChangeNotificationType
+	SystemResumedNotificationType
+	OLMReplyNotificationType
 )
 
 type MethodType int
@@ -42,6 +45,12 @@ const (
 	SaveUserSecretsMethodType
 	DeleteUserSecretsMethodType
 	GetDevicePostureMethodType
+	UpdateVersionMethodType
+	PingMethodType
+	SetLogLevelMethodType
+	GetTunnelStatusMethodType
+	CancelUpdateMethodType
+	SwitchTunnelOrgMethodType
 )
 
 var (
@@ -50,6 +59,16 @@ var (
 	rpcMutex   sync.Mutex
 )
 
+// errRPCStalled is returned by calls made while a timed out ping is still
+// waiting for its answer, since that answer would be read as their reply
+var errRPCStalled = errors.New("manager service is not responding")
+
+// stalledPipe stands in for the RPC pipes until a late ping answer is read
+type stalledPipe struct{}
+
+func (stalledPipe) Read([]byte) (int, error)  { return 0, errRPCStalled }
+func (stalledPipe) Write([]byte) (int, error) { return 0, errRPCStalled }
+
 type ManagerStoppingCallback struct {
 	cb func()
 }
@@ -74,6 +93,22 @@ type TunnelStateChangeCallback struct {
 
 var tunnelStateChangeCallbacks = make(map[*TunnelStateChangeCallback]bool)
 
+type SystemResumedCallback struct {
+	cb func()
+}
+
+var systemResumedCallbacks = make(map[*SystemResumedCallback]bool)
+
+type EventsClosedCallback struct {
+	cb func()
+}
+
+var eventsClosedCallbacks = make(map[*EventsClosedCallback]bool)
+
+// UIReconnectExitCode is the exit code of a UI that lost its events pipe while
+// the manager still runs; the manager then starts it again with new pipes
+const UIReconnectExitCode = 75
+
 func InitializeIPCClient(reader, writer, events *os.File) {
 	rpcDecoder = gob.NewDecoder(reader)
 	rpcEncoder = gob.NewEncoder(writer)
@@ -85,6 +120,9 @@ func InitializeIPCClient(reader, writer, events *os.File) {
 			var notificationType NotificationType
 			err := decoder.Decode(&notificationType)
 			if err != nil {
+				for cb := range eventsClosedCallbacks {
+					cb.cb()
+				}
 				return
 			}
 			switch notificationType {
@@ -120,13 +158,24 @@ func InitializeIPCClient(reader, writer, events *os.File) {
 				if err != nil {
 					continue
 				}
-				if len(errStr) > 0 {
+				if errStr == updater.ErrUpdateCancelled.Error() {
+					// Restore the sentinel so callers can use errors.Is
+					dp.Error = updater.ErrUpdateCancelled
+				} else if len(errStr) > 0 {
 					dp.Error = errors.New(errStr)
 				}
 				err = decoder.Decode(&dp.Complete)
 				if err != nil {
 					continue
 				}
+				err = decoder.Decode(&dp.BytesPerSecond)
+				if err != nil {
+					continue
+				}
+				err = decoder.Decode(&dp.ETA)
+				if err != nil {
+					continue
+				}
 				for cb := range updateProgressCallbacks {
 					cb.cb(dp)
 				}
@@ -139,6 +188,34 @@ func InitializeIPCClient(reader, writer, events *os.File) {
 				for cb := range tunnelStateChangeCallbacks {
 					cb.cb(state)
 				}
+			case SystemResumedNotificationType:
+				for cb := range systemResumedCallbacks {
+					cb.cb()
+				}
+			case OLMReplyNotificationType:
+				var id uint64
+				err = decoder.Decode(&id)
+				if err != nil {
+					continue
+				}
+				var reply olmReply
+				err = decoder.Decode(&reply.status)
+				if err != nil {
+					continue
+				}
+				var errStr string
+				err = decoder.Decode(&errStr)
+				if err != nil {
+					continue
+				}
+				if len(errStr) > 0 {
+					reply.err = errors.New(errStr)
+				}
+				olmRequestsLock.Lock()
+				if ch, ok := olmRequests[id]; ok {
+					ch <- reply
+				}
+				olmRequestsLock.Unlock()
 			}
 		}
 	}()
@@ -156,6 +233,54 @@ func rpcDecodeError() error {
 	return errors.New(str)
 }
 
+// IPCClientPing does a round-trip to the manager service and fails if it
+// does not answer within timeout. A late answer is still read in the
+// background; until then other calls fail with errRPCStalled instead of
+// reading it as their reply, and once it arrives the pipes are used again, so
+// a manager that recovers needs no UI restart.
+func IPCClientPing(timeout time.Duration) error {
+	rpcMutex.Lock()
+	defer rpcMutex.Unlock()
+
+	if rpcEncoder == nil {
+		return errors.New("manager IPC is not connected")
+	}
+	err := rpcEncoder.Encode(PingMethodType)
+	if err != nil {
+		return err
+	}
+
+	encoder, decoder := rpcEncoder, rpcDecoder
+	answered := make(chan error, 1)
+	go func() {
+		var pong bool
+		answered <- decoder.Decode(&pong)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err = <-answered:
+		return err
+	case <-timer.C:
+	}
+
+	logger.Error("Manager service did not answer a ping within %v", timeout)
+	rpcEncoder = gob.NewEncoder(stalledPipe{})
+	rpcDecoder = gob.NewDecoder(stalledPipe{})
+	go func() {
+		err := <-answered
+		if err != nil {
+			logger.Error("Manager IPC failed while waiting for a late ping answer: %v", err)
+			return
+		}
+		rpcMutex.Lock()
+		rpcEncoder, rpcDecoder = encoder, decoder
+		rpcMutex.Unlock()
+		logger.Info("Manager service answered a late ping, resuming IPC")
+	}()
+	return errRPCStalled
+}
+
 func IPCClientQuit(stopTunnelsOnQuit bool) (alreadyQuit bool, err error) {
 	rpcMutex.Lock()
 	defer rpcMutex.Unlock()
@@ -191,6 +316,19 @@ func IPCClientUpdateState() (updateState UpdateState, err error) {
 	return
 }
 
+// IPCClientUpdateVersion returns the version of the update found by the manager, or "" if none
+func IPCClientUpdateVersion() (updateVersion string, err error) {
+	rpcMutex.Lock()
+	defer rpcMutex.Unlock()
+
+	err = rpcEncoder.Encode(UpdateVersionMethodType)
+	if err != nil {
+		return
+	}
+	err = rpcDecoder.Decode(&updateVersion)
+	return
+}
+
 func IPCClientUpdate() error {
 	// Always stop any running tunnel services first
 	// Ignore errors from StopTunnel as it's safe to call even if no tunnel is running
@@ -202,6 +340,17 @@ func IPCClientUpdate() error {
 	return rpcEncoder.Encode(UpdateMethodType)
 }
 
+// IPCClientCancelUpdate asks the manager to abort an update that is still downloading
+func IPCClientCancelUpdate() error {
+	rpcMutex.Lock()
+	defer rpcMutex.Unlock()
+
+	if rpcEncoder == nil {
+		return errors.New("manager IPC is not connected")
+	}
+	return rpcEncoder.Encode(CancelUpdateMethodType)
+}
+
 func IPCClientRegisterManagerStopping(cb func()) *ManagerStoppingCallback {
 	s := &ManagerStoppingCallback{cb}
 	managerStoppingCallbacks[s] = true
@@ -263,6 +412,9 @@ func IPCClientStopAllTunnels() error {
 	rpcMutex.Lock()
 	defer rpcMutex.Unlock()
 
+	if rpcEncoder == nil {
+		return errors.New("manager IPC is not connected")
+	}
 	err := rpcEncoder.Encode(StopAllTunnelsMethodType)
 	if err != nil {
 		return err
@@ -307,6 +459,28 @@ func (cb *TunnelStateChangeCallback) Unregister() {
 	delete(tunnelStateChangeCallbacks, cb)
 }
 
+func IPCClientRegisterSystemResumed(cb func()) *SystemResumedCallback {
+	s := &SystemResumedCallback{cb}
+	systemResumedCallbacks[s] = true
+	return s
+}
+
+func (cb *SystemResumedCallback) Unregister() {
+	delete(systemResumedCallbacks, cb)
+}
+
+// IPCClientRegisterEventsClosed registers cb to run once the events pipe
+// breaks, e.g. because the manager dropped this UI as unresponsive
+func IPCClientRegisterEventsClosed(cb func()) *EventsClosedCallback {
+	s := &EventsClosedCallback{cb}
+	eventsClosedCallbacks[s] = true
+	return s
+}
+
+func (cb *EventsClosedCallback) Unregister() {
+	delete(eventsClosedCallbacks, cb)
+}
+
 // IPCClientReady reports whether the UI has an active RPC connection to the manager service.
 func IPCClientReady() bool {
 	rpcMutex.Lock()
@@ -408,3 +582,99 @@ func IPCClientGetDevicePosture() (fingerprint.DevicePostureSnapshot, error) {
 	}
 	return snapshot, err
 }
+
+// IPCClientSetLogLevel changes the manager service's log level
+func IPCClientSetLogLevel(level string) error {
+	rpcMutex.Lock()
+	defer rpcMutex.Unlock()
+
+	if rpcEncoder == nil {
+		return errors.New("manager IPC is not connected")
+	}
+	err := rpcEncoder.Encode(SetLogLevelMethodType)
+	if err != nil {
+		return err
+	}
+	err = rpcEncoder.Encode(level)
+	if err != nil {
+		return err
+	}
+	return rpcDecodeError()
+}
+
+// olmReplySlack is how much longer than the OLM timeout the UI waits for the
+// manager's answer, to cover the trip over the events pipe
+const olmReplySlack = 2 * time.Second
+
+type olmReply struct {
+	status tunnel.OLMStatusResponse
+	err    error
+}
+
+// olmRequests holds the OLM requests waiting for their answer on the events
+// pipe, by request ID
+var (
+	olmRequests     = make(map[uint64]chan olmReply)
+	olmRequestsLock sync.Mutex
+	lastOLMRequest  uint64
+)
+
+// olmRequest asks the manager service to make a request to OLM within
+// timeout. The manager answers on the events pipe, so a slow OLM does not
+// hold rpcMutex and block other calls, such as the health check ping.
+func olmRequest(method MethodType, timeout time.Duration, args ...any) (*tunnel.OLMStatusResponse, error) {
+	ch := make(chan olmReply, 1)
+	olmRequestsLock.Lock()
+	lastOLMRequest++
+	id := lastOLMRequest
+	olmRequests[id] = ch
+	olmRequestsLock.Unlock()
+	defer func() {
+		olmRequestsLock.Lock()
+		delete(olmRequests, id)
+		olmRequestsLock.Unlock()
+	}()
+
+	err := func() error {
+		rpcMutex.Lock()
+		defer rpcMutex.Unlock()
+
+		if rpcEncoder == nil {
+			return errors.New("manager IPC is not connected")
+		}
+		for _, arg := range append([]any{method, id, timeout}, args...) {
+			if err := rpcEncoder.Encode(arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(timeout + olmReplySlack)
+	defer timer.Stop()
+	select {
+	case reply := <-ch:
+		if reply.err != nil {
+			return nil, reply.err
+		}
+		return &reply.status, nil
+	case <-timer.C:
+		return nil, errors.New("manager service did not answer the OLM request in time")
+	}
+}
+
+// IPCClientGetTunnelStatus returns the OLM status, fetched by the manager
+// service from the OLM named pipe within timeout
+func IPCClientGetTunnelStatus(timeout time.Duration) (*tunnel.OLMStatusResponse, error) {
+	return olmRequest(GetTunnelStatusMethodType, timeout)
+}
+
+// IPCClientSwitchTunnelOrg has the manager service switch OLM to orgID over
+// the OLM named pipe within timeout
+func IPCClientSwitchTunnelOrg(orgID string, timeout time.Duration) error {
+	_, err := olmRequest(SwitchTunnelOrgMethodType, timeout, orgID)
+	return err
+}
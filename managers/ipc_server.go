@@ -16,6 +16,7 @@ import (
 	"github.com/fosrl/newt/logger"
 	"golang.org/x/sys/windows"
 
+	"github.com/fosrl/windows/config"
 	"github.com/fosrl/windows/fingerprint"
 	"github.com/fosrl/windows/managers/secretstore"
 	"github.com/fosrl/windows/tunnel"
@@ -39,11 +40,17 @@ var (
 	secretStore          = secretstore.NewStore()
 )
 
+// maxEventWriteTimeouts is how many consecutive notification writes may time
+// out before a UI's events pipe is dropped as unresponsive.
+const maxEventWriteTimeouts = 3
+
 type ManagerService struct {
 	events           *os.File
 	eventLock        sync.Mutex
+	eventTimeouts    int // consecutive notification write timeouts, protected by eventLock
 	elevatedToken    windows.Token
 	clientWindowsSID string
+	logLevel         string // level this session asked for, protected by managerServicesLock
 }
 
 func (s *ManagerService) Quit(stopTunnelsOnQuit bool) (alreadyQuit bool, err error) {
@@ -87,6 +94,10 @@ func (s *ManagerService) UpdateState() UpdateState {
 	return updateState
 }
 
+func (s *ManagerService) UpdateVersion() string {
+	return updateVersion
+}
+
 func (s *ManagerService) Update() {
 	if s.elevatedToken == 0 {
 		return
@@ -104,6 +115,11 @@ func (s *ManagerService) Update() {
 	}()
 }
 
+// CancelUpdate aborts an update that is still downloading
+func (s *ManagerService) CancelUpdate() {
+	updater.CancelUpdate()
+}
+
 func (s *ManagerService) IsCLIInstalled() bool {
 	return IsCLIInstalled()
 }
@@ -142,14 +158,17 @@ func (s *ManagerService) StartTunnel(config tunnel.Config) error {
 		return UninstallTunnel(name)
 	})
 
+	logger.Info("IPC server: [conn %s] StartTunnel requested", config.ConnectionID)
 	err := tunnel.StartTunnel(config)
 	if err != nil {
+		logger.Error("IPC server: [conn %s] StartTunnel failed: %v", config.ConnectionID, err)
 		return err
 	}
 	// Track this tunnel as active
 	activeTunnelsLock.Lock()
 	activeTunnels[config.Name] = true
 	activeTunnelsLock.Unlock()
+	watchTunnel(config.Name, config.RestartRetries)
 	// Notify UI of initial state change (starting)
 	state := tunnel.GetState()
 	IPCServerNotifyTunnelStateChange(state)
@@ -195,6 +214,21 @@ func (s *ManagerService) StopAllTunnels() error {
 		return UninstallTunnel(name)
 	})
 
+	// Stop the current tunnel through the tunnel package so its state is
+	// reset and the UI is told it stopped
+	var stopErr error
+	if name := tunnel.GetTunnelName(); name != "" {
+		logger.Info("Stopping tunnel: %s", name)
+		stopErr = tunnel.StopTunnel()
+		if stopErr != nil {
+			logger.Error("Failed to stop tunnel %s: %v", name, stopErr)
+		} else {
+			activeTunnelsLock.Lock()
+			delete(activeTunnels, name)
+			activeTunnelsLock.Unlock()
+		}
+	}
+
 	activeTunnelsLock.Lock()
 	tunnelNames := make([]string, 0, len(activeTunnels))
 	for name := range activeTunnels {
@@ -213,7 +247,12 @@ func (s *ManagerService) StopAllTunnels() error {
 			activeTunnelsLock.Unlock()
 		}
 	}
-	return nil
+
+	// Services the manager lost track of would otherwise linger
+	if err := UninstallStrayTunnels(); err != nil {
+		logger.Error("Failed to remove stray tunnel services: %v", err)
+	}
+	return stopErr
 }
 
 func (s *ManagerService) GetUserSecrets(userID string) (secretstore.UserSecrets, error) {
@@ -263,6 +302,58 @@ func (s *ManagerService) GetDevicePosture() (fingerprint.DevicePostureSnapshot,
 	return snapshot, nil
 }
 
+func init() {
+	// OLMStatusResponse.NetworkSettings holds decoded JSON, so gob needs to
+	// know the container types it may find behind interface{} values
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// GetTunnelStatus queries OLM over its named pipe on behalf of the UI, so
+// only the manager service needs access to the pipe
+func (s *ManagerService) GetTunnelStatus(timeout time.Duration) (tunnel.OLMStatusResponse, error) {
+	status, err := tunnel.FetchOLMStatus(timeout)
+	if err != nil {
+		return tunnel.OLMStatusResponse{}, err
+	}
+	return *status, nil
+}
+
+// SwitchTunnelOrg switches OLM's organization over its named pipe on behalf
+// of the UI
+func (s *ManagerService) SwitchTunnelOrg(orgID string, timeout time.Duration) error {
+	return tunnel.PostOLMSwitchOrg(orgID, timeout)
+}
+
+// SetLogLevel records the log level this session wants, e.g. to quiet the
+// posture check output once the user drops from Debug to Info. The service is
+// shared, so it logs at the most verbose level any connected session asked for.
+func (s *ManagerService) SetLogLevel(level string) error {
+	managerServicesLock.Lock()
+	s.logLevel = level
+	managerServicesLock.Unlock()
+	applySessionLogLevels()
+	return nil
+}
+
+// applySessionLogLevels sets the service's log level to the most verbose one
+// asked for by a connected session, or the system level when none has
+func applySessionLogLevels() {
+	level := ""
+	managerServicesLock.RLock()
+	for m := range managerServices {
+		if m.logLevel != "" && (level == "" || config.ParseLogLevel(m.logLevel) < config.ParseLogLevel(level)) {
+			level = m.logLevel
+		}
+	}
+	managerServicesLock.RUnlock()
+	if level == "" {
+		level = config.GetSystemLogLevel()
+	}
+	config.ApplyLogLevel(level)
+	logger.Info("Log level set to %s", level)
+}
+
 func (s *ManagerService) ServeConn(reader io.Reader, writer io.Writer) {
 	decoder := gob.NewDecoder(reader)
 	encoder := gob.NewEncoder(writer)
@@ -297,6 +388,16 @@ func (s *ManagerService) ServeConn(reader io.Reader, writer io.Writer) {
 			}
 		case UpdateMethodType:
 			s.Update()
+		case PingMethodType:
+			err = encoder.Encode(true)
+			if err != nil {
+				return
+			}
+		case UpdateVersionMethodType:
+			err = encoder.Encode(s.UpdateVersion())
+			if err != nil {
+				return
+			}
 		case StartTunnelMethodType:
 			var config tunnel.Config
 			err := decoder.Decode(&config)
@@ -389,6 +490,54 @@ func (s *ManagerService) ServeConn(reader io.Reader, writer io.Writer) {
 			if err != nil {
 				return
 			}
+		case GetTunnelStatusMethodType:
+			var id uint64
+			err := decoder.Decode(&id)
+			if err != nil {
+				return
+			}
+			var timeout time.Duration
+			err = decoder.Decode(&timeout)
+			if err != nil {
+				return
+			}
+			go func() {
+				status, retErr := s.GetTunnelStatus(timeout)
+				s.replyOLM(id, status, retErr)
+			}()
+		case SwitchTunnelOrgMethodType:
+			var id uint64
+			err := decoder.Decode(&id)
+			if err != nil {
+				return
+			}
+			var timeout time.Duration
+			err = decoder.Decode(&timeout)
+			if err != nil {
+				return
+			}
+			var orgID string
+			err = decoder.Decode(&orgID)
+			if err != nil {
+				return
+			}
+			go func() {
+				retErr := s.SwitchTunnelOrg(orgID, timeout)
+				s.replyOLM(id, tunnel.OLMStatusResponse{}, retErr)
+			}()
+		case CancelUpdateMethodType:
+			s.CancelUpdate()
+		case SetLogLevelMethodType:
+			var level string
+			err := decoder.Decode(&level)
+			if err != nil {
+				return
+			}
+			retErr := s.SetLogLevel(level)
+			err = encoder.Encode(errToString(retErr))
+			if err != nil {
+				return
+			}
 		default:
 			logger.Error("IPC server: ServeConn unknown method type %d, closing connection", methodType)
 			return
@@ -425,42 +574,87 @@ func IPCServerListen(reader, writer, events *os.File, elevatedToken windows.Toke
 		service.eventLock.Unlock()
 		delete(managerServices, service)
 		managerServicesLock.Unlock()
+		applySessionLogLevels()
 	}()
 }
 
 func notifyAll(notificationType NotificationType, adminOnly bool, ifaces ...any) {
-	if len(managerServices) == 0 {
+	managerServicesLock.RLock()
+	empty := len(managerServices) == 0
+	managerServicesLock.RUnlock()
+	if empty {
 		return
 	}
 
+	buf, err := encodeNotification(notificationType, ifaces...)
+	if err != nil {
+		return
+	}
+
+	managerServicesLock.RLock()
+	for m := range managerServices {
+		if m.elevatedToken == 0 && adminOnly {
+			continue
+		}
+		go m.writeEvent(notificationType, buf)
+	}
+	managerServicesLock.RUnlock()
+}
+
+func encodeNotification(notificationType NotificationType, ifaces ...any) ([]byte, error) {
 	var buf bytes.Buffer
 	encoder := gob.NewEncoder(&buf)
 	err := encoder.Encode(notificationType)
 	if err != nil {
-		return
+		return nil, err
 	}
 	for _, iface := range ifaces {
 		err = encoder.Encode(iface)
 		if err != nil {
-			return
+			return nil, err
 		}
 	}
+	return buf.Bytes(), nil
+}
 
-	managerServicesLock.RLock()
-	for m := range managerServices {
-		if m.elevatedToken == 0 && adminOnly {
-			continue
-		}
-		go func(m *ManagerService) {
-			m.eventLock.Lock()
-			defer m.eventLock.Unlock()
-			if m.events != nil {
-				m.events.SetWriteDeadline(time.Now().Add(time.Second))
-				m.events.Write(buf.Bytes())
-			}
-		}(m)
+// writeEvent writes an encoded notification to this UI's events pipe
+func (s *ManagerService) writeEvent(notificationType NotificationType, buf []byte) {
+	s.eventLock.Lock()
+	defer s.eventLock.Unlock()
+	if s.events == nil {
+		return
 	}
-	managerServicesLock.RUnlock()
+	s.events.SetWriteDeadline(time.Now().Add(time.Second))
+	_, err := s.events.Write(buf)
+	if err == nil {
+		s.eventTimeouts = 0
+		return
+	}
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		logger.Warn("IPC server: failed to write notification %d: %v", notificationType, err)
+		return
+	}
+	s.eventTimeouts++
+	if s.eventTimeouts < maxEventWriteTimeouts {
+		logger.Warn("IPC server: notification %d write timed out (%d/%d)", notificationType, s.eventTimeouts, maxEventWriteTimeouts)
+		return
+	}
+	// The UI is not reading its events pipe; close it so it can't stall
+	// others, and so the UI sees the pipe break and reconnects
+	logger.Error("IPC server: notification writes timed out %d times in a row, closing unresponsive UI events pipe", s.eventTimeouts)
+	s.events.Close()
+	s.events = nil
+}
+
+// replyOLM answers an OLM request on the events pipe, which keeps the RPC
+// stream free for other calls while OLM is slow to answer
+func (s *ManagerService) replyOLM(id uint64, status tunnel.OLMStatusResponse, err error) {
+	buf, encErr := encodeNotification(OLMReplyNotificationType, id, status, errToString(err))
+	if encErr != nil {
+		logger.Error("IPC server: failed to encode OLM reply: %v", encErr)
+		return
+	}
+	s.writeEvent(OLMReplyNotificationType, buf)
 }
 
 func errToString(err error) string {
@@ -475,7 +669,7 @@ func IPCServerNotifyUpdateFound(state UpdateState) {
 }
 
 func IPCServerNotifyUpdateProgress(dp updater.DownloadProgress) {
-	notifyAll(UpdateProgressNotificationType, true, dp.Activity, dp.BytesDownloaded, dp.BytesTotal, errToString(dp.Error), dp.Complete)
+	notifyAll(UpdateProgressNotificationType, true, dp.Activity, dp.BytesDownloaded, dp.BytesTotal, errToString(dp.Error), dp.Complete, dp.BytesPerSecond, dp.ETA)
 }
 
 func IPCServerNotifyManagerStopping() {
@@ -486,3 +680,7 @@ func IPCServerNotifyManagerStopping() {
 func IPCServerNotifyTunnelStateChange(state TunnelState) {
 	notifyAll(TunnelStateChangeNotificationType, false, state)
 }
+
+func IPCServerNotifySystemResumed() {
+	notifyAll(SystemResumedNotificationType, false)
+}
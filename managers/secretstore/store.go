@@ -55,6 +55,10 @@ func (s *Store) Save(windowsSID, userID string, update SecretsUpdate) error {
 	if update.SetOlmSecret {
 		current.OlmSecret = update.Secrets.OlmSecret
 	}
+	if update.SetProxyCredentials {
+		current.ProxyUsername = update.Secrets.ProxyUsername
+		current.ProxyPassword = update.Secrets.ProxyPassword
+	}
 	return s.write(windowsSID, userID, current)
 }
 
@@ -71,7 +75,11 @@ func (s *Store) Delete(windowsSID, userID string, flags DeleteSecretsFlags) erro
 		current.OlmId = ""
 		current.OlmSecret = ""
 	}
-	if current.SessionToken == "" && current.OlmId == "" && current.OlmSecret == "" {
+	if flags.ProxyCredentials {
+		current.ProxyUsername = ""
+		current.ProxyPassword = ""
+	}
+	if current == (UserSecrets{}) {
 		path, err := userSecretsPath(windowsSID, userID)
 		if err != nil {
 			return err
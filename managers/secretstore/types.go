@@ -4,22 +4,26 @@ package secretstore
 
 // UserSecrets holds per-Pangolin-account credentials for one Windows user profile.
 type UserSecrets struct {
-	SessionToken string `json:"sessionToken,omitempty"`
-	OlmId        string `json:"olmId,omitempty"`
-	OlmSecret    string `json:"olmSecret,omitempty"`
+	SessionToken  string `json:"sessionToken,omitempty"`
+	OlmId         string `json:"olmId,omitempty"`
+	OlmSecret     string `json:"olmSecret,omitempty"`
+	ProxyUsername string `json:"proxyUsername,omitempty"`
+	ProxyPassword string `json:"proxyPassword,omitempty"`
 }
 
 // SecretsUpdate describes fields to merge when saving secrets.
 type SecretsUpdate struct {
 	Secrets UserSecrets
 
-	SetSessionToken bool
-	SetOlmId        bool
-	SetOlmSecret    bool
+	SetSessionToken     bool
+	SetOlmId            bool
+	SetOlmSecret        bool
+	SetProxyCredentials bool
 }
 
 // DeleteSecretsFlags selects which credential fields to remove.
 type DeleteSecretsFlags struct {
-	SessionToken   bool
-	OlmCredentials bool
+	SessionToken     bool
+	OlmCredentials   bool
+	ProxyCredentials bool
 }
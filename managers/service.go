@@ -17,12 +17,16 @@ import (
 	"github.com/Microsoft/go-winio"
 	"github.com/fosrl/newt/logger"
 	"github.com/fosrl/windows/config"
+	"github.com/fosrl/windows/tunnel"
 	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/svc"
 )
 
 type managerService struct{}
 
+// pbtAPMResumeAutomatic is the PBT_APMRESUMEAUTOMATIC power event, sent when the system resumes from sleep
+const pbtAPMResumeAutomatic = 0x12
+
 func (service *managerService) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (svcSpecificEC bool, exitCode uint32) {
 	changes <- svc.Status{State: svc.StartPending}
 
@@ -36,6 +40,7 @@ func (service *managerService) Execute(args []string, r <-chan svc.ChangeRequest
 	}()
 
 	logger.Info("Pangolin Manager service starting")
+	tunnel.ResolveOLMPipePath()
 
 	// WTSQueryUserToken requires SeTcbPrivilege (act as part of OS); enable it for this process.
 	// Without it, WTSQueryUserToken returns error 1314 on some systems even when running as LocalSystem.
@@ -55,7 +60,7 @@ func (service *managerService) Execute(args []string, r <-chan svc.ChangeRequest
 	stoppingManager := false
 	// operatorGroupSid, _ := windows.CreateWellKnownSid(windows.WinBuiltinNetworkConfigurationOperatorsSid) // TODO: Use when LimitedOperatorUI is implemented
 
-	startProcess := func(session uint32) {
+	startProcess := func(session uint32) (relaunch bool) {
 		defer func() {
 			runtime.UnlockOSThread()
 			procsLock.Lock()
@@ -241,7 +246,8 @@ func (service *managerService) Execute(args []string, r <-chan svc.ChangeRequest
 		procs[session] = proc
 		procsLock.Unlock()
 
-		if exitCode, waitErr := proc.Wait(); waitErr == nil {
+		exitCode, waitErr := proc.Wait()
+		if waitErr == nil {
 			logger.Info("Exited UI process for user '%s@%s' for session %d with status %x", username, domain, session, exitCode)
 		} else {
 			logger.Error("Unable to wait for UI process for user '%s@%s' for session %d: %v", username, domain, session, waitErr)
@@ -249,16 +255,24 @@ func (service *managerService) Execute(args []string, r <-chan svc.ChangeRequest
 
 		procsLock.Lock()
 		delete(procs, session)
+		relaunch = waitErr == nil && exitCode == UIReconnectExitCode && !stoppingManager && aliveSessions[session]
 		procsLock.Unlock()
 		ourReader.Close()
 		ourWriter.Close()
 		ourEvents.Close()
+		return relaunch
 	}
 	procsGroup := sync.WaitGroup{}
 	goStartProcess := func(session uint32) {
 		procsGroup.Add(1)
 		go func() {
-			startProcess(session)
+			// A UI that lost its events pipe asks to be started again with new pipes
+			for startProcess(session) {
+				logger.Info("Restarting UI process for session %d to reconnect it", session)
+				procsLock.Lock()
+				aliveSessions[session] = true
+				procsLock.Unlock()
+			}
 			procsGroup.Done()
 		}()
 	}
@@ -295,7 +309,7 @@ func (service *managerService) Execute(args []string, r <-chan svc.ChangeRequest
 		go runCLISecretsPipeListener(cliSecretsListener)
 	}
 
-	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptSessionChange}
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptSessionChange | svc.AcceptPowerEvent}
 
 	// If restart-ui-after-update flag exists (written before MSI run), launch UI for active session then remove flag.
 	go func() {
@@ -370,6 +384,12 @@ loop:
 					continue
 				}
 
+			case svc.PowerEvent:
+				if c.EventType == pbtAPMResumeAutomatic {
+					logger.Info("System resumed from sleep, notifying UI")
+					IPCServerNotifySystemResumed()
+				}
+
 			default:
 				logger.Error("Unexpected service control request #%d", c)
 			}
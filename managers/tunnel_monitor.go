@@ -0,0 +1,172 @@
+//go:build windows
+
+package managers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/fosrl/windows/tunnel"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+)
+
+const (
+	// tunnelMonitorInterval is how often the tunnel services are checked
+	tunnelMonitorInterval = 5 * time.Second
+	// tunnelRestartResetAfter is how long a restarted tunnel must stay up
+	// before its restart count starts over
+	tunnelRestartResetAfter = 10 * time.Minute
+)
+
+// tunnelWatch tracks restarts of one tunnel service
+type tunnelWatch struct {
+	maxRestarts int
+	restarts    int
+	lastRestart time.Time
+}
+
+var (
+	tunnelMonitorOnce sync.Once
+	watchedTunnels    = make(map[string]*tunnelWatch)
+	watchedLock       sync.Mutex
+)
+
+// watchTunnel starts monitoring the named tunnel service for crashes,
+// restarting it at most maxRestarts times in a row
+func watchTunnel(name string, maxRestarts int) {
+	watchedLock.Lock()
+	watchedTunnels[name] = &tunnelWatch{maxRestarts: maxRestarts}
+	watchedLock.Unlock()
+
+	tunnelMonitorOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(tunnelMonitorInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				checkWatchedTunnels()
+			}
+		}()
+	})
+}
+
+// checkWatchedTunnels looks for tunnel services that stopped on their own while
+// the manager still considers them active
+func checkWatchedTunnels() {
+	watchedLock.Lock()
+	names := make([]string, 0, len(watchedTunnels))
+	for name := range watchedTunnels {
+		names = append(names, name)
+	}
+	watchedLock.Unlock()
+
+	for _, name := range names {
+		activeTunnelsLock.RLock()
+		active := activeTunnels[name]
+		activeTunnelsLock.RUnlock()
+		if !active {
+			// Stopped on purpose
+			watchedLock.Lock()
+			delete(watchedTunnels, name)
+			watchedLock.Unlock()
+			continue
+		}
+		if tunnel.GetState() == tunnel.StateStopping {
+			continue
+		}
+
+		exitCode, crashed := tunnelServiceCrashed(name)
+		if !crashed {
+			continue
+		}
+		handleTunnelCrash(name, exitCode)
+	}
+}
+
+// tunnelServiceCrashed reports whether the named tunnel service exists but has
+// stopped with an error. A missing service was removed on purpose.
+func tunnelServiceCrashed(name string) (exitCode uint32, crashed bool) {
+	m, err := serviceManager()
+	if err != nil {
+		return 0, false
+	}
+	service, err := m.OpenService(tunnelServiceName(name))
+	if err != nil {
+		return 0, false
+	}
+	defer service.Close()
+
+	status, err := service.Query()
+	if err != nil || status.State != svc.Stopped {
+		return 0, false
+	}
+	exitCode = status.Win32ExitCode
+	if exitCode == uint32(windows.ERROR_SERVICE_SPECIFIC_ERROR) {
+		exitCode = status.ServiceSpecificExitCode
+	}
+	return exitCode, exitCode != 0
+}
+
+// handleTunnelCrash restarts a crashed tunnel service, or gives up and reports
+// the tunnel as failed once it has used up its restarts
+func handleTunnelCrash(name string, exitCode uint32) {
+	watchedLock.Lock()
+	watch := watchedTunnels[name]
+	if watch == nil {
+		watchedLock.Unlock()
+		return
+	}
+	if !watch.lastRestart.IsZero() && time.Since(watch.lastRestart) > tunnelRestartResetAfter {
+		watch.restarts = 0
+	}
+	restart := watch.restarts < watch.maxRestarts
+	if restart {
+		watch.restarts++
+		watch.lastRestart = time.Now()
+	} else {
+		delete(watchedTunnels, name)
+	}
+	attempt, maxRestarts := watch.restarts, watch.maxRestarts
+	watchedLock.Unlock()
+
+	if restart {
+		logger.Error("Tunnel service %s stopped unexpectedly (exit code %d), restarting (attempt %d of %d)", name, exitCode, attempt, maxRestarts)
+		IPCServerNotifyTunnelStateChange(tunnel.StateReconnecting)
+		err := restartTunnelService(name)
+		if err == nil {
+			return
+		}
+		logger.Error("Failed to restart tunnel service %s: %v", name, err)
+		watchedLock.Lock()
+		delete(watchedTunnels, name)
+		watchedLock.Unlock()
+	} else {
+		logger.Error("Tunnel service %s stopped unexpectedly (exit code %d) after %d restarts, giving up", name, exitCode, attempt)
+	}
+
+	// Clean up the dead service, then tell the UI the tunnel crashed
+	if err := tunnel.StopTunnel(); err != nil {
+		logger.Error("Failed to remove crashed tunnel %s: %v", name, err)
+	}
+	activeTunnelsLock.Lock()
+	delete(activeTunnels, name)
+	activeTunnelsLock.Unlock()
+	tunnel.SetState(tunnel.StateError)
+	IPCServerNotifyTunnelStateChange(tunnel.StateError)
+}
+
+// restartTunnelService starts the named tunnel service again with the config
+// it was installed with
+func restartTunnelService(name string) error {
+	m, err := serviceManager()
+	if err != nil {
+		return err
+	}
+	service, err := m.OpenService(tunnelServiceName(name))
+	if err != nil {
+		return err
+	}
+	defer service.Close()
+	return service.Start()
+}
@@ -22,7 +22,10 @@ const (
 	UpdateStateUpdatesDisabledUnofficialBuild
 )
 
-var updateState = UpdateStateUnknown
+var (
+	updateState   = UpdateStateUnknown
+	updateVersion string
+)
 
 func jitterSleep(min, max time.Duration) {
 	time.Sleep(min + time.Millisecond*time.Duration(fastrandn(uint32((max-min+1)/time.Millisecond))))
@@ -57,6 +60,7 @@ func checkForUpdates() {
 		if err == nil && update != nil && !didNotify {
 			logger.Info("An update is available")
 			updateState = UpdateStateFoundUpdate
+			updateVersion = update.Version()
 			IPCServerNotifyUpdateFound(updateState)
 			didNotify = true
 		} else if err != nil && !didNotify {
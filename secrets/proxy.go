@@ -0,0 +1,65 @@
+//go:build windows
+
+package secrets
+
+import (
+	"net/url"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/fosrl/windows/config"
+	"github.com/fosrl/windows/managers/secretstore"
+)
+
+// proxySecretsID is the secret store entry holding the proxy login. The proxy
+// belongs to the Windows user rather than to a Pangolin account, and account
+// IDs never start with a dot.
+const proxySecretsID = ".proxy"
+
+// GetProxyCredentials returns the stored proxy login, or nil if there is none
+func (sm *SecretManager) GetProxyCredentials() *url.Userinfo {
+	secrets, ok := sm.load(proxySecretsID)
+	if !ok || secrets.ProxyUsername == "" {
+		return nil
+	}
+	if secrets.ProxyPassword == "" {
+		return url.User(secrets.ProxyUsername)
+	}
+	return url.UserPassword(secrets.ProxyUsername, secrets.ProxyPassword)
+}
+
+// SaveProxyCredentials stores the proxy login, or deletes it if user is nil
+func (sm *SecretManager) SaveProxyCredentials(user *url.Userinfo) bool {
+	if user == nil {
+		return sm.deleteFlags(proxySecretsID, secretstore.DeleteSecretsFlags{ProxyCredentials: true})
+	}
+	password, _ := user.Password()
+	return sm.saveUpdate(proxySecretsID, secretstore.SecretsUpdate{
+		Secrets: secretstore.UserSecrets{
+			ProxyUsername: user.Username(),
+			ProxyPassword: password,
+		},
+		SetProxyCredentials: true,
+	})
+}
+
+// ProxyURL returns the proxy set in config with the stored login added, or
+// nil if none is set. A login still written into the config by an older
+// version is moved to the secret store first.
+func (sm *SecretManager) ProxyURL(cm *config.ConfigManager) *url.URL {
+	proxy := cm.GetProxyURL()
+	if proxy == nil {
+		return nil
+	}
+	if proxy.User != nil {
+		if sm.SaveProxyCredentials(proxy.User) {
+			stripped := *proxy
+			stripped.User = nil
+			if !cm.SetProxy(stripped.String()) {
+				logger.Error("Failed to remove the proxy login from the config file")
+			}
+		}
+		return proxy
+	}
+	proxy.User = sm.GetProxyCredentials()
+	return proxy
+}
@@ -3,7 +3,10 @@
 package secrets
 
 import (
+	"errors"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/fosrl/newt/logger"
 	"github.com/fosrl/windows/managers/secretstore"
@@ -11,12 +14,27 @@ import (
 
 var credentialMigrationOnce sync.Once
 
+var errManagerNotConnected = errors.New("manager IPC is not connected")
+
+// Saves are retried because the manager can briefly fail to protect secrets,
+// e.g. while the user's logon session is still being set up after sign-in
+const (
+	saveAttempts   = 3
+	saveRetryDelay = time.Second
+)
+
 // SecretManager stores and retrieves secrets via the manager service (DPAPI files as SYSTEM).
-type SecretManager struct{}
+// Session tokens that cannot be stored are kept in memory for the lifetime of
+// the process instead, so the user can still connect until the app restarts.
+type SecretManager struct {
+	mu           sync.Mutex
+	lastSaveErr  error
+	memoryTokens map[string]string // session tokens not yet stored, keyed by user ID
+}
 
 // NewSecretManager creates a new SecretManager instance.
 func NewSecretManager() *SecretManager {
-	return &SecretManager{}
+	return &SecretManager{memoryTokens: make(map[string]string)}
 }
 
 func (sm *SecretManager) ensureReady() bool {
@@ -49,16 +67,48 @@ func (sm *SecretManager) load(userID string) (secretstore.UserSecrets, bool) {
 
 func (sm *SecretManager) saveUpdate(userID string, update secretstore.SecretsUpdate) bool {
 	if !sm.ensureReady() {
+		sm.mu.Lock()
+		sm.lastSaveErr = errManagerNotConnected
+		sm.mu.Unlock()
 		return false
 	}
-	logger.Debug("Secrets: IPC SaveUserSecrets() starting (userId=%s)", userID)
-	if err := ipc.SaveUserSecrets(userID, update); err != nil {
+	var err error
+	for attempt := 1; attempt <= saveAttempts; attempt++ {
+		logger.Debug("Secrets: IPC SaveUserSecrets() starting (userId=%s, attempt=%d)", userID, attempt)
+		if err = ipc.SaveUserSecrets(userID, update); err == nil {
+			break
+		}
+		logger.Warn("Failed to save secrets for user %s (attempt %d/%d): %v", userID, attempt, saveAttempts, err)
+		if attempt < saveAttempts {
+			time.Sleep(saveRetryDelay * time.Duration(attempt))
+		}
+	}
+
+	sm.mu.Lock()
+	sm.lastSaveErr = err
+	sm.mu.Unlock()
+
+	if err != nil {
 		logger.Error("Failed to save secrets for user %s: %v", userID, err)
 		return false
 	}
 	return true
 }
 
+// LastSaveError returns the error from the most recent failed save, or nil if it succeeded
+func (sm *SecretManager) LastSaveError() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.lastSaveErr
+}
+
+// IsLogonSessionError reports whether err means Windows could not protect the
+// secret because the user has no interactive logon session, e.g. when the app
+// was started before the user signed in or from a non-interactive context
+func IsLogonSessionError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "logon session does not exist")
+}
+
 func (sm *SecretManager) deleteFlags(userID string, flags secretstore.DeleteSecretsFlags) bool {
 	if !sm.ensureReady() {
 		return false
@@ -72,6 +122,13 @@ func (sm *SecretManager) deleteFlags(userID string, flags secretstore.DeleteSecr
 
 // GetSessionToken retrieves the session token for the given user ID.
 func (sm *SecretManager) GetSessionToken(userId string) (string, bool) {
+	sm.mu.Lock()
+	token, inMemory := sm.memoryTokens[userId]
+	sm.mu.Unlock()
+	if inMemory {
+		return token, true
+	}
+
 	secrets, ok := sm.load(userId)
 	if !ok || secrets.SessionToken == "" {
 		return "", false
@@ -97,12 +154,74 @@ func (sm *SecretManager) GetOlmSecret(userId string) (string, bool) {
 	return secrets.OlmSecret, true
 }
 
-// SaveSessionToken saves a session token for the given user ID.
+// SaveSessionToken saves a session token for the given user ID. Returns false
+// if it could not be stored; see KeepSessionToken.
 func (sm *SecretManager) SaveSessionToken(userId string, token string) bool {
-	return sm.saveUpdate(userId, secretstore.SecretsUpdate{
+	saved := sm.saveUpdate(userId, secretstore.SecretsUpdate{
 		Secrets:         secretstore.UserSecrets{SessionToken: token},
 		SetSessionToken: true,
 	})
+	if saved {
+		sm.mu.Lock()
+		delete(sm.memoryTokens, userId)
+		sm.mu.Unlock()
+	}
+	return saved
+}
+
+// KeepSessionToken keeps a session token that could not be stored in memory
+// until PersistSessionTokens stores it or the process exits. IsPersistent
+// reports false while any token is only kept in memory.
+func (sm *SecretManager) KeepSessionToken(userId string, token string) {
+	if token == "" {
+		return
+	}
+	logger.Warn("Secrets: keeping session token in memory only; the user must log in again after restart (userId=%s)", userId)
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.memoryTokens[userId] = token
+}
+
+// HasMemorySessionToken reports whether token is kept in memory for the user
+func (sm *SecretManager) HasMemorySessionToken(userId string, token string) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return token != "" && sm.memoryTokens[userId] == token
+}
+
+// IsPersistent reports whether all session tokens are stored. It is false
+// while any token is only kept in memory.
+func (sm *SecretManager) IsPersistent() bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return len(sm.memoryTokens) == 0
+}
+
+// PersistSessionTokens tries again to store the session tokens kept in
+// memory, e.g. after the user unlocked their Windows session. Returns whether
+// all tokens are now stored.
+func (sm *SecretManager) PersistSessionTokens() bool {
+	sm.mu.Lock()
+	pending := make(map[string]string, len(sm.memoryTokens))
+	for userId, token := range sm.memoryTokens {
+		pending[userId] = token
+	}
+	sm.mu.Unlock()
+
+	for userId, token := range pending {
+		saved := sm.saveUpdate(userId, secretstore.SecretsUpdate{
+			Secrets:         secretstore.UserSecrets{SessionToken: token},
+			SetSessionToken: true,
+		})
+		if saved {
+			sm.mu.Lock()
+			if sm.memoryTokens[userId] == token {
+				delete(sm.memoryTokens, userId)
+			}
+			sm.mu.Unlock()
+		}
+	}
+	return sm.IsPersistent()
 }
 
 // SaveOlmCredentials saves both OLM ID and secret for the given user ID.
@@ -128,6 +247,9 @@ func (sm *SecretManager) HasOlmCredentials(userId string) bool {
 
 // DeleteSessionToken removes a session token for a given user.
 func (sm *SecretManager) DeleteSessionToken(userId string) bool {
+	sm.mu.Lock()
+	delete(sm.memoryTokens, userId)
+	sm.mu.Unlock()
 	return sm.deleteFlags(userId, secretstore.DeleteSecretsFlags{SessionToken: true})
 }
 
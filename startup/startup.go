@@ -0,0 +1,69 @@
+//go:build windows
+
+// Package startup manages launching the app when the user signs in, via the
+// per-user Run registry key. HKCU is writable by the user, so no elevation is needed.
+package startup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fosrl/windows/config"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const runKeyPath = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+// Enabled returns whether the Run key has an entry that launches this executable
+func Enabled() (bool, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer k.Close()
+
+	value, _, err := k.GetStringValue(config.AppName)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return false, err
+	}
+	return strings.EqualFold(strings.Trim(value, `"`), exe), nil
+}
+
+// SetEnabled adds or removes the Run key entry for this executable
+func SetEnabled(enabled bool) error {
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open run key: %w", err)
+	}
+	defer k.Close()
+
+	if !enabled {
+		if err := k.DeleteValue(config.AppName); err != nil && !errors.Is(err, registry.ErrNotExist) {
+			return fmt.Errorf("failed to remove startup entry: %w", err)
+		}
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := k.SetStringValue(config.AppName, `"`+exe+`"`); err != nil {
+		return fmt.Errorf("failed to add startup entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+//go:build windows
+
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+
+	"github.com/fosrl/windows/config"
+
+	"github.com/fosrl/newt/logger"
+)
+
+// adapterDisabledErrorCode is reported through the error callback when the tunnel adapter disappears
+const adapterDisabledErrorCode = "ADAPTER_DISABLED"
+
+// tunnelCrashedErrorCode is reported through the error callback when the
+// manager gives up restarting a tunnel service that keeps crashing
+const tunnelCrashedErrorCode = "TUNNEL_CRASHED"
+
+// MaxInterfaceNameLength is the longest network adapter name accepted
+const MaxInterfaceNameLength = 32
+
+// interfaceNameRegex matches names that are safe as a Windows network
+// adapter name and as part of a service name
+var interfaceNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_=+.-]{1,32}$`)
+
+// ValidInterfaceName reports whether name can be used for the tunnel's
+// network adapter
+func ValidInterfaceName(name string) bool {
+	return interfaceNameRegex.MatchString(name)
+}
+
+// interfaceNameOrDefault returns the configured adapter name, or the default
+// if it is not a valid adapter name
+func interfaceNameOrDefault(name string) string {
+	if !ValidInterfaceName(name) {
+		logger.Warn("Invalid interface name %q, using %q", name, config.DefaultInterfaceName)
+		return config.DefaultInterfaceName
+	}
+	return name
+}
+
+// checkTunnelAdapter returns an error if the named network adapter is missing
+// or not up. Adapters disabled in Windows are not enumerated at all, so both
+// cases are treated the same.
+func checkTunnelAdapter(name string) error {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return fmt.Errorf("network adapter %q not found: %w", name, err)
+	}
+	if iface.Flags&net.FlagUp == 0 {
+		return fmt.Errorf("network adapter %q is down", name)
+	}
+	return nil
+}
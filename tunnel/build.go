@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"os"
 	"time"
 
 	"github.com/fosrl/newt/logger"
@@ -17,16 +18,22 @@ import (
 
 // buildTunnel builds the tunnel
 func (s *tunnelService) buildTunnel(config Config) error {
-	logger.Debug("Build tunnel called: config: %+v", config)
+	logger.Debug("[conn %s] Build tunnel called: config: %+v", config.ConnectionID, config)
 
 	// Create context for OLM
 	olmContext := context.Background()
 
+	// The user's log level wins over the system config file's
+	logLevel := config.LogLevel
+	if logLevel == "" {
+		logLevel = configpkg.GetSystemLogLevel()
+	}
+
 	// Create OLM GlobalConfig with values derived from system config
 	olmInitConfig := olmpkg.OlmConfig{
-		LogLevel:   configpkg.GetSystemLogLevel(),
+		LogLevel:   logLevel,
 		EnableAPI:  true,
-		SocketPath: OLMNamedPipePath,
+		SocketPath: OLMPipePath(),
 		Version:    version.Number,
 		Agent:      "Pangolin Windows",
 		OnConnected: func() {
@@ -69,6 +76,7 @@ func (s *tunnelService) buildTunnel(config Config) error {
 		MTU:      config.MTU,
 		// DNS:                  config.DNS, // this gets pulled dynamically from the host system now
 		Holepunch:            config.Holepunch,
+		DisableRelay:         config.DisableRelay,
 		PingIntervalDuration: time.Duration(config.PingIntervalSeconds) * time.Second,
 		PingTimeoutDuration:  time.Duration(config.PingTimeoutSeconds) * time.Second,
 		UserToken:            config.UserToken,
@@ -83,14 +91,28 @@ func (s *tunnelService) buildTunnel(config Config) error {
 		InitialPostures:      postures,
 	}
 
+	// olm only reads this switch from the environment, and the environment is
+	// this tunnel service's own. A private CA is only trusted by olm once it
+	// is installed in the Windows certificate store.
+	if config.TLSSkipVerify {
+		logger.Warn("[conn %s] TLS certificate verification is turned off", config.ConnectionID)
+		if err := os.Setenv("SKIP_TLS_VERIFY", "true"); err != nil {
+			logger.Error("[conn %s] Failed to turn off TLS certificate verification: %v", config.ConnectionID, err)
+		}
+	}
+
 	s.olm.StartApi()
 
-	logger.Info("Starting OLM tunnel...")
+	logger.Info("[conn %s] Starting OLM tunnel...", config.ConnectionID)
 	go func() {
 		s.olm.StartTunnel(olmConfig)
 		logger.Info("OLM tunnel stopped")
 	}()
 
+	// olm has no option for excluded routes, so they are taken back out of
+	// the routes it adds
+	s.routeExcluder = startRouteExcluder(config.InterfaceName, config.ExcludedRoutes)
+
 	logger.Debug("Build tunnel completed successfully")
 	return nil
 }
@@ -10,6 +10,9 @@ import (
 func (s *tunnelService) destroyTunnel(config Config) {
 	logger.Debug("Destroy tunnel called")
 
+	s.routeExcluder.stop()
+	s.routeExcluder = nil
+
 	s.olm.StopApi()
 	s.olm.StopTunnel()
 
@@ -0,0 +1,172 @@
+//go:build windows
+
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const diagnosticDialTimeout = 5 * time.Second
+
+// ServerCheck is the result of a single server connectivity check
+type ServerCheck struct {
+	Name   string
+	Passed bool
+	Detail string
+}
+
+// TestServerConnection runs a set of connectivity checks against the active
+// account's server: a DNS lookup, a TCP dial, an HTTP request via the API
+// client and, when connected, whether each peer endpoint has answered the
+// tunnel.
+func (tm *Manager) TestServerConnection() []ServerCheck {
+	activeAccount, _ := tm.accountManager.ActiveAccount()
+	if activeAccount == nil || activeAccount.Hostname == "" {
+		return []ServerCheck{{Name: "Server", Detail: "No account is signed in"}}
+	}
+
+	checks := []ServerCheck{}
+
+	serverURL, err := url.Parse(activeAccount.Hostname)
+	if err != nil || serverURL.Hostname() == "" {
+		return append(checks, ServerCheck{Name: "Server URL", Detail: fmt.Sprintf("Invalid server URL %q", activeAccount.Hostname)})
+	}
+	host := serverURL.Hostname()
+	port := serverURL.Port()
+	if port == "" {
+		port = "443"
+		if serverURL.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	// DNS lookup
+	addrs, err := net.LookupHost(host)
+	if err != nil {
+		checks = append(checks, ServerCheck{Name: "DNS lookup", Detail: err.Error()})
+	} else {
+		checks = append(checks, ServerCheck{Name: "DNS lookup", Passed: true, Detail: fmt.Sprintf("%s resolved to %v", host, addrs)})
+	}
+
+	// TCP dial
+	address := net.JoinHostPort(host, port)
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, diagnosticDialTimeout)
+	if err != nil {
+		checks = append(checks, ServerCheck{Name: "TCP connection", Detail: err.Error()})
+	} else {
+		conn.Close()
+		checks = append(checks, ServerCheck{Name: "TCP connection", Passed: true, Detail: fmt.Sprintf("Connected to %s in %s", address, time.Since(start).Round(time.Millisecond))})
+	}
+
+	// HTTP request via the API client
+	if apiClient := tm.authManager.APIClient(); apiClient != nil {
+		ok, err := apiClient.TestConnection()
+		switch {
+		case err != nil:
+			checks = append(checks, ServerCheck{Name: "HTTP request", Detail: err.Error()})
+		case !ok:
+			checks = append(checks, ServerCheck{Name: "HTTP request", Detail: fmt.Sprintf("%s did not respond successfully", apiClient.CurrentBaseURL())})
+		default:
+			checks = append(checks, ServerCheck{Name: "HTTP request", Passed: true, Detail: fmt.Sprintf("%s is reachable", apiClient.CurrentBaseURL())})
+		}
+	}
+
+	// Tunnel endpoints are UDP and WireGuard stays silent to anything but a
+	// handshake, so report what olm has heard from each peer instead of dialing
+	if tm.IsConnected() {
+		if status, err := tm.GetOLMStatus(); err == nil {
+			for _, peer := range status.PeerStatuses {
+				if peer == nil || peer.Endpoint == "" {
+					continue
+				}
+				name := fmt.Sprintf("Tunnel endpoint (%s)", peer.SiteName)
+				if !peer.Connected {
+					detail := fmt.Sprintf("%s has not answered", peer.Endpoint)
+					if !peer.LastSeen.IsZero() {
+						detail = fmt.Sprintf("%s last answered %s ago", peer.Endpoint, time.Since(peer.LastSeen).Round(time.Second))
+					}
+					checks = append(checks, ServerCheck{Name: name, Detail: detail})
+					continue
+				}
+				checks = append(checks, ServerCheck{Name: name, Passed: true, Detail: fmt.Sprintf("%s answered (round trip %s)", peer.Endpoint, FormatRTT(peer.RTT))})
+			}
+		}
+	}
+
+	return checks
+}
+
+// TestTunnelDNS resolves name through olm's DNS proxy while DNS over tunnel is
+// on. The proxy's address is only routed into the tunnel, and it forwards the
+// query to the upstream DNS servers through the tunnel, so this only passes
+// if those servers are reachable as Pangolin resources.
+func (tm *Manager) TestTunnelDNS(name string) []ServerCheck {
+	userID := ""
+	if activeAccount, _ := tm.accountManager.ActiveAccount(); activeAccount != nil {
+		userID = activeAccount.UserID
+	}
+	settings := tm.configManager.GetDNSSettings(userID)
+	if !settings.DNSTunnel {
+		return []ServerCheck{{Name: "DNS over tunnel", Detail: "DNS over tunnel is turned off"}}
+	}
+	if !tm.IsConnected() {
+		return []ServerCheck{{Name: "Tunnel", Detail: "Not connected; connect first so the test can resolve through the tunnel"}}
+	}
+
+	var upstreams []string
+	for _, server := range []string{settings.PrimaryDNS, settings.SecondaryDNS} {
+		if server != "" {
+			upstreams = append(upstreams, dnsServerAddress(server))
+		}
+	}
+	if len(upstreams) == 0 {
+		return []ServerCheck{{Name: "Upstream DNS server", Detail: "No upstream DNS server is set, so tunneled queries have nowhere to go"}}
+	}
+
+	status, err := tm.GetOLMStatus()
+	if err != nil {
+		return []ServerCheck{{Name: "Tunnel status", Detail: err.Error()}}
+	}
+	proxy := dnsProxyAddress(status)
+	if proxy == "" {
+		return []ServerCheck{{Name: "DNS proxy", Detail: "The tunnel did not report its DNS proxy; turn on DNS override so queries go through the tunnel"}}
+	}
+
+	server := net.JoinHostPort(proxy, "53")
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticDialTimeout)
+	defer cancel()
+	start := time.Now()
+	addrs, err := resolver.LookupHost(ctx, name)
+
+	checkName := fmt.Sprintf("Resolve via the tunnel to %s", strings.Join(upstreams, ", "))
+	if err != nil {
+		return []ServerCheck{{Name: checkName, Detail: err.Error()}}
+	}
+	return []ServerCheck{{Name: checkName, Passed: true, Detail: fmt.Sprintf("%s resolved to %v in %s", name, addrs, time.Since(start).Round(time.Millisecond))}}
+}
+
+// dnsProxyAddress returns the address of olm's DNS proxy from the network
+// settings in status, or "" if olm did not report one. olm reports it as the
+// tunnel's DNS server when DNS override is on.
+func dnsProxyAddress(status *OLMStatusResponse) string {
+	servers, _ := status.NetworkSettings["dns_servers"].([]any)
+	for _, server := range servers {
+		if address, ok := server.(string); ok && net.ParseIP(address) != nil {
+			return address
+		}
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+//go:build windows
+
+package tunnel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EventType is the kind of a connection event
+type EventType int
+
+const (
+	EventConnected EventType = iota
+	EventDisconnected
+	EventPeerUp
+	EventPeerDown
+	EventRelaySwitch
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventConnected:
+		return "Connected"
+	case EventDisconnected:
+		return "Disconnected"
+	case EventPeerUp:
+		return "PeerUp"
+	case EventPeerDown:
+		return "PeerDown"
+	case EventRelaySwitch:
+		return "RelaySwitch"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is a connection event, reported as it happens so the UI can show a
+// timeline without parsing the text log
+type Event struct {
+	Time         time.Time
+	Type         EventType
+	ConnectionID string
+	// SiteName is set for peer events
+	SiteName string
+	// Relayed is set for peer events when the site is reached through the relay
+	Relayed bool
+	// Message describes the event for display
+	Message string
+}
+
+// maxRecentEvents is how many events RecentEvents keeps
+const maxRecentEvents = 50
+
+// RegisterEventCallback registers a callback run for every connection event
+func (tm *Manager) RegisterEventCallback(cb func(Event)) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.eventCb = cb
+}
+
+// RecentEvents returns the most recent connection events, oldest first
+func (tm *Manager) RecentEvents() []Event {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return slices.Clone(tm.events)
+}
+
+func (tm *Manager) emitEvent(ev Event) {
+	ev.Time = time.Now()
+
+	tm.mu.Lock()
+	if ev.ConnectionID == "" {
+		ev.ConnectionID = tm.connectionID
+	}
+	tm.events = append(tm.events, ev)
+	if len(tm.events) > maxRecentEvents {
+		tm.events = slices.Delete(tm.events, 0, len(tm.events)-maxRecentEvents)
+	}
+	cb := tm.eventCb
+	tm.mu.Unlock()
+
+	if cb != nil {
+		cb(ev)
+	}
+}
+
+// updateStateEvent reports connect and disconnect events for state changes.
+// Transitional states are ignored so a connect attempt that fails before
+// completing does not produce a disconnect.
+func (tm *Manager) updateStateEvent(state State) {
+	tm.mu.Lock()
+	wasConnected := tm.eventConnected
+	switch state {
+	case StateRunning:
+		tm.eventConnected = true
+	case StateStopped, StateError:
+		tm.eventConnected = false
+	}
+	nowConnected := tm.eventConnected
+	tm.mu.Unlock()
+
+	switch {
+	case nowConnected && !wasConnected:
+		tm.emitEvent(Event{Type: EventConnected, Message: "Connected"})
+	case !nowConnected && wasConnected:
+		tm.emitEvent(Event{Type: EventDisconnected, Message: "Disconnected"})
+	}
+}
+
+// updatePeerEvents reports sites that came up, went down or switched between
+// relayed and direct since the previous status. Pass nil peers to forget the
+// known sites without reporting anything.
+func (tm *Manager) updatePeerEvents(connID string, peers map[int]*OLMPeerStatus) {
+	tm.mu.Lock()
+	previous := tm.eventPeers
+	current := make(map[int]OLMPeerStatus, len(peers))
+	for id, peer := range peers {
+		if peer != nil {
+			current[id] = *peer
+		}
+	}
+	if peers == nil {
+		current = nil
+	}
+	tm.eventPeers = current
+	tm.mu.Unlock()
+
+	var events []Event
+	for id, peer := range current {
+		before, known := previous[id]
+		switch {
+		case peer.Connected && (!known || !before.Connected):
+			events = append(events, Event{Type: EventPeerUp, SiteName: peer.SiteName, Relayed: peer.IsRelay,
+				Message: fmt.Sprintf("%s is up (%s)", peer.SiteName, connectionPath(peer.IsRelay))})
+		case !peer.Connected && known && before.Connected:
+			events = append(events, Event{Type: EventPeerDown, SiteName: peer.SiteName,
+				Message: fmt.Sprintf("%s is down", peer.SiteName)})
+		case peer.Connected && peer.IsRelay != before.IsRelay:
+			events = append(events, Event{Type: EventRelaySwitch, SiteName: peer.SiteName, Relayed: peer.IsRelay,
+				Message: fmt.Sprintf("%s switched to %s", peer.SiteName, connectionPath(peer.IsRelay))})
+		}
+	}
+	slices.SortFunc(events, func(a, b Event) int {
+		return strings.Compare(a.SiteName, b.SiteName)
+	})
+	for _, ev := range events {
+		ev.ConnectionID = connID
+		tm.emitEvent(ev)
+	}
+}
+
+func connectionPath(relayed bool) string {
+	if relayed {
+		return "relay"
+	}
+	return "direct"
+}
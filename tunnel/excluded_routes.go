@@ -0,0 +1,232 @@
+//go:build windows
+
+package tunnel
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/fosrl/newt/logger"
+	"golang.org/x/sys/windows"
+	"golang.zx2c4.com/wireguard/windows/tunnel/winipcfg"
+)
+
+// routeSettleDelay lets the burst of route changes olm makes when it adds
+// sites settle before the routes are checked
+const routeSettleDelay = 500 * time.Millisecond
+
+// routeExcluder keeps the excluded routes out of the tunnel. olm takes no
+// exclusions and adds routes as sites come and go, so route changes are
+// watched: tunnel routes inside an excluded prefix are deleted, and where a
+// broader tunnel route covers one, a more specific route sends it the way it
+// went without the tunnel.
+type routeExcluder struct {
+	interfaceName string
+	prefixes      []netip.Prefix
+	callback      *winipcfg.RouteChangeCallback
+	changed       chan struct{}
+	done          chan struct{}
+
+	mu       sync.Mutex
+	bypasses map[netip.Prefix]bypassRoute // routes added for excluded prefixes
+	stopped  bool
+}
+
+// bypassRoute is a route added for an excluded prefix outside the tunnel
+type bypassRoute struct {
+	luid    winipcfg.LUID
+	nextHop netip.Addr
+	metric  uint32
+}
+
+// startRouteExcluder starts keeping routes, in CIDR notation, out of the
+// tunnel adapter named interfaceName. Returns nil if there is nothing to
+// exclude or routes cannot be watched.
+func startRouteExcluder(interfaceName string, routes []string) *routeExcluder {
+	var prefixes []netip.Prefix
+	for _, route := range routes {
+		prefix, err := netip.ParsePrefix(route)
+		if err != nil {
+			logger.Warn("Tunnel: ignoring invalid excluded route %q: %v", route, err)
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	re := &routeExcluder{
+		interfaceName: interfaceName,
+		prefixes:      prefixes,
+		changed:       make(chan struct{}, 1),
+		done:          make(chan struct{}),
+		bypasses:      make(map[netip.Prefix]bypassRoute),
+	}
+	callback, err := winipcfg.RegisterRouteChangeCallback(func(notificationType winipcfg.MibNotificationType, route *winipcfg.MibIPforwardRow2) {
+		re.notify()
+	})
+	if err != nil {
+		logger.Error("Tunnel: failed to watch routes, excluded routes will go through the tunnel: %v", err)
+		return nil
+	}
+	re.callback = callback
+
+	logger.Info("Tunnel: keeping %v out of the tunnel", prefixes)
+	re.notify()
+	go re.run()
+	return re
+}
+
+func (re *routeExcluder) notify() {
+	select {
+	case re.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (re *routeExcluder) run() {
+	for {
+		select {
+		case <-re.done:
+			return
+		case <-re.changed:
+		}
+		select {
+		case <-re.done:
+			return
+		case <-time.After(routeSettleDelay):
+		}
+		// Changes made meanwhile are covered by this pass, including our own
+		select {
+		case <-re.changed:
+		default:
+		}
+		re.apply()
+	}
+}
+
+// apply removes tunnel routes inside excluded prefixes and adds or updates
+// the bypass routes for excluded prefixes that a tunnel route covers
+func (re *routeExcluder) apply() {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+	if re.stopped {
+		return
+	}
+
+	// The adapter only exists once olm has brought it up
+	iface, err := net.InterfaceByName(re.interfaceName)
+	if err != nil {
+		return
+	}
+	tunnelLUID, err := winipcfg.LUIDFromIndex(uint32(iface.Index))
+	if err != nil {
+		return
+	}
+
+	routes, err := winipcfg.GetIPForwardTable2(windows.AF_UNSPEC)
+	if err != nil {
+		logger.Error("Tunnel: failed to read routes for excluded routes: %v", err)
+		return
+	}
+
+	covered := make(map[netip.Prefix]bool)
+	for i := range routes {
+		route := &routes[i]
+		if route.InterfaceLUID != tunnelLUID {
+			continue
+		}
+		dest := route.DestinationPrefix.Prefix()
+		for _, excluded := range re.prefixes {
+			if dest.Bits() >= excluded.Bits() && excluded.Contains(dest.Addr()) {
+				logger.Info("Tunnel: removing route to %s, which is excluded by %s", dest, excluded)
+				if err := route.Delete(); err != nil {
+					logger.Error("Tunnel: failed to remove excluded route to %s: %v", dest, err)
+				}
+				break
+			}
+			if dest.Bits() < excluded.Bits() && dest.Contains(excluded.Addr()) {
+				covered[excluded] = true
+			}
+		}
+	}
+
+	for _, excluded := range re.prefixes {
+		have, added := re.bypasses[excluded]
+		var want bypassRoute
+		var needed bool
+		if covered[excluded] {
+			want, needed = bypassFor(routes, excluded, tunnelLUID, have, added)
+		}
+		if added && (!needed || have != want) {
+			if err := have.luid.DeleteRoute(excluded, have.nextHop); err != nil && !errors.Is(err, windows.ERROR_NOT_FOUND) {
+				logger.Error("Tunnel: failed to remove route for excluded %s: %v", excluded, err)
+			}
+			delete(re.bypasses, excluded)
+		}
+		if needed && (!added || have != want) {
+			if err := want.luid.AddRoute(excluded, want.nextHop, want.metric); err != nil {
+				// A route that already exists isn't ours to remove later
+				if !errors.Is(err, windows.ERROR_OBJECT_ALREADY_EXISTS) {
+					logger.Error("Tunnel: failed to route excluded %s outside the tunnel: %v", excluded, err)
+				}
+				continue
+			}
+			logger.Info("Tunnel: routing excluded %s via %s outside the tunnel", excluded, want.nextHop)
+			re.bypasses[excluded] = want
+		}
+	}
+}
+
+// bypassFor picks the route excluded would take without the tunnel: the most
+// specific, then lowest-metric, route covering it on another adapter. own is
+// the bypass route already added for excluded, which is skipped. Returns
+// false if no route is needed because one for excluded itself exists.
+func bypassFor(routes []winipcfg.MibIPforwardRow2, excluded netip.Prefix, tunnelLUID winipcfg.LUID, own bypassRoute, haveOwn bool) (bypassRoute, bool) {
+	var best *winipcfg.MibIPforwardRow2
+	var bestDest netip.Prefix
+	for i := range routes {
+		route := &routes[i]
+		dest := route.DestinationPrefix.Prefix()
+		if route.InterfaceLUID == tunnelLUID || route.Loopback || dest.Bits() > excluded.Bits() || !dest.Contains(excluded.Addr()) {
+			continue
+		}
+		if haveOwn && dest == excluded && route.InterfaceLUID == own.luid && route.NextHop.Addr() == own.nextHop {
+			continue
+		}
+		if best == nil || dest.Bits() > bestDest.Bits() || (dest.Bits() == bestDest.Bits() && route.Metric < best.Metric) {
+			best = route
+			bestDest = dest
+		}
+	}
+	if best == nil || bestDest.Bits() == excluded.Bits() {
+		return bypassRoute{}, false
+	}
+	return bypassRoute{luid: best.InterfaceLUID, nextHop: best.NextHop.Addr(), metric: best.Metric}, true
+}
+
+// stop stops watching routes and removes the bypass routes. Tunnel routes
+// that were removed are not restored; olm tears the adapter down anyway.
+func (re *routeExcluder) stop() {
+	if re == nil {
+		return
+	}
+	close(re.done)
+	if err := re.callback.Unregister(); err != nil {
+		logger.Warn("Tunnel: failed to stop watching routes: %v", err)
+	}
+
+	re.mu.Lock()
+	defer re.mu.Unlock()
+	re.stopped = true
+	for prefix, bypass := range re.bypasses {
+		if err := bypass.luid.DeleteRoute(prefix, bypass.nextHop); err != nil && !errors.Is(err, windows.ERROR_NOT_FOUND) {
+			logger.Warn("Tunnel: failed to remove route for excluded %s: %v", prefix, err)
+		}
+	}
+	re.bypasses = nil
+}
@@ -5,15 +5,20 @@ package tunnel
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Microsoft/go-winio"
+	"github.com/fosrl/windows/api"
 	"github.com/fosrl/windows/auth"
 	"github.com/fosrl/windows/config"
 	"github.com/fosrl/windows/secrets"
@@ -27,6 +32,8 @@ type IPCClient interface {
 	StartTunnel(config Config) error
 	StopTunnel() error
 	RegisterStateChangeCallback(cb func(State)) func() // Returns unregister function
+	GetTunnelStatus(timeout time.Duration) (*OLMStatusResponse, error)
+	SwitchTunnelOrg(orgID string, timeout time.Duration) error
 }
 
 // Manager manages tunnel connection state and operations
@@ -37,12 +44,23 @@ type Manager struct {
 	isConnected    bool
 	stateCallback  func(State)
 	errorCallback  func(*OLMStatusError)
+	connectErrCb   func(*ConnectionError)
 	unregisterCb   func()
 	ipcClient      IPCClient
 	authManager    *auth.AuthManager
 	configManager  *config.ConfigManager
 	accountManager *config.AccountManager
 	secretManager  *secrets.SecretManager
+	interfaceName  string
+	connectionID   string
+	stalePeers     []string
+	stalePeersCb   func([]string)
+	peerDownSince  map[int]time.Time
+	eventCb        func(Event)
+	events         []Event
+	eventPeers     map[int]OLMPeerStatus
+	eventConnected bool
+	orgDeniedCb    func(*auth.OrgAccessDeniedError)
 	// Status polling fields
 	pollCtx       context.Context
 	pollCancel    context.CancelFunc
@@ -78,11 +96,18 @@ func NewManager(
 			tm.currentState = state
 			tm.isConnected = (state == StateRunning)
 			tm.mu.Unlock()
+			tm.updateStateEvent(state)
 
 			// Call user-provided callback if set
 			if tm.stateCallback != nil {
 				tm.stateCallback(state)
 			}
+
+			// The manager only reports StateError once the tunnel service
+			// crashed more often than it is allowed to restart
+			if state == StateError {
+				tm.handleTunnelCrashed()
+			}
 		})
 	}
 
@@ -135,6 +160,14 @@ func (tm *Manager) RegisterStateChangeCallback(cb func(State)) {
 	tm.stateCallback = cb
 }
 
+// RegisterConnectErrorCallback registers a callback that will be called when a
+// connect attempt fails after Connect returned, e.g. when it times out
+func (tm *Manager) RegisterConnectErrorCallback(cb func(*ConnectionError)) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.connectErrCb = cb
+}
+
 // RegisterErrorCallback registers a callback that will be called when an error is detected in OLM status
 func (tm *Manager) RegisterErrorCallback(cb func(*OLMStatusError)) {
 	tm.mu.Lock()
@@ -152,6 +185,7 @@ func (tm *Manager) setLocalState(state State) {
 	tm.isConnected = (state == StateRunning)
 	callback := tm.stateCallback
 	tm.mu.Unlock()
+	tm.updateStateEvent(state)
 
 	if callback != nil {
 		callback(state)
@@ -178,55 +212,101 @@ func (tm *Manager) buildConfig() (Config, error) {
 	userId := tm.authManager.CurrentUser().UserId
 	olmId, found := tm.secretManager.GetOlmId(userId)
 	if !found || olmId == "" {
-		return Config{}, fmt.Errorf("OLM ID not found")
+		return Config{}, errOlmIDNotFound
 	}
 	olmSecret, found := tm.secretManager.GetOlmSecret(userId)
 	if !found || olmSecret == "" {
-		return Config{}, fmt.Errorf("OLM secret not found")
+		return Config{}, errOlmSecretNotFound
 	}
 
-	// Get DNS settings from config manager
-	primaryDNS := tm.configManager.GetPrimaryDNS()
-	secondaryDNS := tm.configManager.GetSecondaryDNS()
-	dnsOverride := tm.configManager.GetDNSOverride()
-	dnsTunnel := tm.configManager.GetDNSTunnel()
+	// Get DNS settings for this account, falling back to the global settings
+	dnsSettings := tm.configManager.GetDNSSettings(userId)
+	primaryDNS := dnsSettings.PrimaryDNS
+	secondaryDNS := dnsSettings.SecondaryDNS
+	dnsOverride := dnsSettings.DNSOverride
+	dnsTunnel := dnsSettings.DNSTunnel
 	preferLocalRoutes := tm.configManager.GetPreferLocalRoutes()
 
-	// Build UpstreamDNS array with :53 appended to each. If no DNS servers are
+	// Build UpstreamDNS array with port 53 added to each. If no DNS servers are
 	// configured, this stays empty, telling olm to use the system DNS.
 	upstreamDNS := []string{}
 	if primaryDNS != "" {
-		upstreamDNS = append(upstreamDNS, primaryDNS+":53")
+		upstreamDNS = append(upstreamDNS, dnsServerAddress(primaryDNS))
 	}
 	if secondaryDNS != "" {
-		upstreamDNS = append(upstreamDNS, secondaryDNS+":53")
+		upstreamDNS = append(upstreamDNS, dnsServerAddress(secondaryDNS))
 	}
 
+	connectionMode := tm.configManager.GetConnectionMode()
 	config := Config{
 		Name:                "olm",
 		ID:                  olmId,
 		Secret:              olmSecret,
 		UserToken:           userToken,
 		MTU:                 tm.configManager.GetMTU(),
-		Holepunch:           true,
-		PingIntervalSeconds: 5,
-		PingTimeoutSeconds:  5,
+		Holepunch:           connectionMode != config.ConnectionModeRelay,
+		DisableRelay:        connectionMode == config.ConnectionModeDirect,
+		PingIntervalSeconds: tm.configManager.GetPingIntervalSeconds(),
+		PingTimeoutSeconds:  tm.configManager.GetPingTimeoutSeconds(),
 		Endpoint:            activeAccount.Hostname,
 		//  DNS:                 "1.1.1.1", // this gets pulled dynamically from the host system now
 		OrgID:             currentOrg.Id,
-		InterfaceName:     "Pangolin",
-		UpstreamDNS:       upstreamDNS, // Each value has :53 appended
+		InterfaceName:     interfaceNameOrDefault(tm.configManager.GetInterfaceName()),
+		UpstreamDNS:       upstreamDNS, // Each value is host:port, IPv6 bracketed
 		MatchDomains:      tm.configManager.GetMatchDomains(),
 		OverrideDNS:       dnsOverride,
 		TunnelDNS:         dnsTunnel,
 		PreferLocalRoutes: preferLocalRoutes,
+		ExcludedRoutes:    tm.configManager.GetExcludedRoutes(),
+		LogLevel:          tm.configManager.GetLogLevel(),
+		TLSSkipVerify:     tm.configManager.GetTLSSkipVerify(),
+		RestartRetries:    tm.configManager.GetTunnelRestartRetries(),
 	}
 
 	return config, nil
 }
 
+// dnsServerAddress returns the DNS server as a host:port pair suitable for olm.
+// Entries that already carry a port are returned unchanged; IPv6 literals are
+// bracketed so "2620:fe::fe" becomes "[2620:fe::fe]:53".
+func dnsServerAddress(server string) string {
+	server = strings.TrimSpace(server)
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(server, "["), "]")
+	return net.JoinHostPort(host, "53")
+}
+
+// ConnectionErrorCode classifies a ConnectionError so callers can decide whether retrying makes sense
+type ConnectionErrorCode int
+
+const (
+	ConnectionErrorUnknown ConnectionErrorCode = iota
+	ConnectionErrorNetwork
+	ConnectionErrorIPC
+	ConnectionErrorAuth
+	ConnectionErrorAccessDenied
+	ConnectionErrorConfig
+	ConnectionErrorState
+	// ConnectionErrorCredentialsMissing means the stored OLM ID or secret is gone;
+	// re-registering the device fixes it
+	ConnectionErrorCredentialsMissing
+	// ConnectionErrorTimeout means OLM did not register within the connect timeout
+	ConnectionErrorTimeout
+	// ConnectionErrorNoOrganization means no organization is selected
+	ConnectionErrorNoOrganization
+)
+
+// Errors from buildConfig when the stored device credentials are incomplete
+var (
+	errOlmIDNotFound     = errors.New("OLM ID not found")
+	errOlmSecretNotFound = errors.New("OLM secret not found")
+)
+
 // ConnectionError represents a connection error with a user-friendly message
 type ConnectionError struct {
+	Code    ConnectionErrorCode
 	Title   string
 	Message string
 	Err     error
@@ -239,15 +319,43 @@ func (e *ConnectionError) Error() string {
 	return e.Message
 }
 
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransient returns true if the error is likely to go away on its own (network or IPC failures)
+func (e *ConnectionError) IsTransient() bool {
+	return e.Code == ConnectionErrorNetwork || e.Code == ConnectionErrorIPC
+}
+
 // formatConnectionError creates a user-friendly error message
-func formatConnectionError(title, message string, err error) *ConnectionError {
+func formatConnectionError(code ConnectionErrorCode, title, message string, err error) *ConnectionError {
 	return &ConnectionError{
+		Code:    code,
 		Title:   title,
 		Message: message,
 		Err:     err,
 	}
 }
 
+// credentialsErrorCode classifies an error from setting up OLM credentials
+func credentialsErrorCode(err error) ConnectionErrorCode {
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		return ConnectionErrorUnknown
+	}
+	switch {
+	case apiErr.Type == api.ErrorTypeNetworkError, apiErr.Type == api.ErrorTypeMaintenance:
+		return ConnectionErrorNetwork
+	case apiErr.Status == 401 || apiErr.Status == 403:
+		return ConnectionErrorAuth
+	case apiErr.Type == api.ErrorTypeHTTPError && (apiErr.Status == 0 || apiErr.Status >= 500):
+		return ConnectionErrorNetwork
+	default:
+		return ConnectionErrorUnknown
+	}
+}
+
 // Connect starts the tunnel, building the configuration internally
 func (tm *Manager) Connect() error {
 	tm.mu.RLock()
@@ -258,6 +366,7 @@ func (tm *Manager) Connect() error {
 	if currentState == StateRunning {
 		logger.Info("Tunnel is already running")
 		return formatConnectionError(
+			ConnectionErrorState,
 			"Tunnel Already Running",
 			"The tunnel is already running. Please disconnect it before connecting again.",
 			nil,
@@ -266,6 +375,7 @@ func (tm *Manager) Connect() error {
 	if currentState == StateStarting || currentState == StateRegistering || currentState == StateRegistered {
 		logger.Info("Tunnel is already starting/connecting")
 		return formatConnectionError(
+			ConnectionErrorState,
 			"Tunnel Already Starting",
 			"The tunnel is already starting. Please wait for it to complete.",
 			nil,
@@ -277,21 +387,34 @@ func (tm *Manager) Connect() error {
 	if currentOrg == nil {
 		logger.Error("No organization selected, aborting connection")
 		return formatConnectionError(
+			ConnectionErrorNoOrganization,
 			"No Organization Selected",
 			"Please select an organization before connecting.",
 			nil,
 		)
 	}
 
+	// Tag every log line for this attempt so it can be followed across the UI, manager and tunnel service
+	connID := newConnectionID()
+	tm.mu.Lock()
+	tm.connectionID = connID
+	tm.mu.Unlock()
+	logger.Info("[conn %s] Starting connection attempt to organization %s", connID, currentOrg.Id)
+
 	tm.setLocalState(StateStarting)
 
+	if !tm.secretManager.IsPersistent() {
+		logger.Warn("[conn %s] Session token is only kept in memory; the user will have to log in again after restart", connID)
+	}
+
 	// Ensure OLM credentials exist before connecting
 	currentUser := tm.authManager.CurrentUser()
 	if currentUser != nil && currentUser.UserId != "" {
 		if err := tm.authManager.EnsureOlmCredentials(currentUser.UserId); err != nil {
-			logger.Error("Failed to ensure OLM credentials: %v", err)
+			logger.Error("[conn %s] Failed to ensure OLM credentials: %v", connID, err)
 			tm.setLocalState(StateStopped)
 			return formatConnectionError(
+				credentialsErrorCode(err),
 				"OLM Credentials Error",
 				fmt.Sprintf("Failed to set up device credentials: %v", err),
 				err,
@@ -300,9 +423,10 @@ func (tm *Manager) Connect() error {
 	} else {
 		activeAccount, err := tm.accountManager.ActiveAccount()
 		if err != nil {
-			logger.Error("Failed to get active account: %v", err)
+			logger.Error("[conn %s] Failed to get active account: %v", connID, err)
 			tm.setLocalState(StateStopped)
 			return formatConnectionError(
+				ConnectionErrorAuth,
 				"Authentication Error",
 				"No user ID available. Please log in again.",
 				err,
@@ -310,9 +434,10 @@ func (tm *Manager) Connect() error {
 		}
 
 		if err := tm.authManager.EnsureOlmCredentials(activeAccount.UserID); err != nil {
-			logger.Error("Failed to ensure OLM credentials: %v", err)
+			logger.Error("[conn %s] Failed to ensure OLM credentials: %v", connID, err)
 			tm.setLocalState(StateStopped)
 			return formatConnectionError(
+				credentialsErrorCode(err),
 				"OLM Credentials Error",
 				fmt.Sprintf("Failed to set up device credentials: %v", err),
 				err,
@@ -323,49 +448,142 @@ func (tm *Manager) Connect() error {
 	// Build config from dependencies
 	config, err := tm.buildConfig()
 	if err != nil {
-		logger.Error("Failed to build tunnel config: %v", err)
+		logger.Error("[conn %s] Failed to build tunnel config: %v", connID, err)
 		tm.setLocalState(StateStopped)
 		// Format config build errors
 		if err.Error() == "session token not found" {
 			return formatConnectionError(
+				ConnectionErrorAuth,
 				"Authentication Error",
 				"Session token not found. Please log in again.",
 				err,
 			)
 		}
+		if errors.Is(err, errOlmIDNotFound) || errors.Is(err, errOlmSecretNotFound) {
+			return formatConnectionError(
+				ConnectionErrorCredentialsMissing,
+				"Device Credentials Missing",
+				"Device credentials are missing — click Re-register Device to create new ones.",
+				err,
+			)
+		}
 		return formatConnectionError(
+			ConnectionErrorConfig,
 			"Configuration Error",
 			fmt.Sprintf("Failed to build tunnel configuration: %v", err),
 			err,
 		)
 	}
 
-	logger.Info("Connecting tunnel with config: Name=%s, Endpoint=%s", config.Name, config.Endpoint)
+	config.ConnectionID = connID
+
+	if err := config.Validate(); err != nil {
+		logger.Error("[conn %s] Invalid tunnel config: %v", connID, err)
+		tm.setLocalState(StateStopped)
+		return formatConnectionError(
+			ConnectionErrorConfig,
+			"Configuration Error",
+			fmt.Sprintf("The tunnel configuration is incomplete: %v", err),
+			err,
+		)
+	}
+
+	logger.Info("[conn %s] Connecting tunnel with config: Name=%s, Endpoint=%s", connID, config.Name, config.Endpoint)
 	if tm.ipcClient == nil {
 		tm.setLocalState(StateStopped)
 		return formatConnectionError(
+			ConnectionErrorUnknown,
 			"Connection Error",
 			"IPC client not initialized. Please restart the application.",
 			nil,
 		)
 	}
+	tm.mu.Lock()
+	tm.interfaceName = config.InterfaceName
+	tm.mu.Unlock()
+
 	err = tm.ipcClient.StartTunnel(config)
 	if err != nil {
-		logger.Error("Failed to start tunnel: %v", err)
+		logger.Error("[conn %s] Failed to start tunnel: %v", connID, err)
 		tm.setLocalState(StateStopped)
 		return formatConnectionError(
+			ConnectionErrorIPC,
 			"Connection Failed",
 			fmt.Sprintf("Failed to start the tunnel: %v", err),
 			err,
 		)
 	}
 
-	logger.Info("Starting status polling")
+	logger.Info("[conn %s] Starting status polling", connID)
 	tm.StartStatusPolling()
 
 	return nil
 }
 
+// newConnectionID returns a short random ID used to correlate the log lines of one connect attempt
+func newConnectionID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Backoff bounds between automatic connect retries
+const (
+	connectRetryInitialBackoff = 2 * time.Second
+	connectRetryMaxBackoff     = 30 * time.Second
+)
+
+// ErrConnectRetryCancelled is returned by ConnectWithRetry, wrapping the last
+// connect error, when the tunnel state changed while it waited to retry
+var ErrConnectRetryCancelled = errors.New("connect retry cancelled")
+
+// ConnectWithRetry calls Connect and, when automatic retry is enabled in
+// preferences, retries transient (network/IPC) failures with exponential
+// backoff. Non-transient errors such as auth or access-denied are returned
+// immediately.
+func (tm *Manager) ConnectWithRetry() error {
+	err := tm.Connect()
+	if err == nil || tm.configManager == nil || !tm.configManager.GetAutoRetryConnect() {
+		return err
+	}
+
+	retries := tm.configManager.GetConnectRetryCount()
+	backoff := connectRetryInitialBackoff
+	for attempt := 1; attempt <= retries; attempt++ {
+		var connErr *ConnectionError
+		if !errors.As(err, &connErr) || !connErr.IsTransient() {
+			return err
+		}
+
+		// A server in maintenance won't be back in seconds, so wait the longest interval
+		if api.IsMaintenance(err) {
+			backoff = connectRetryMaxBackoff
+		}
+
+		logger.Info("Connect failed with transient error, retrying in %v (attempt %d of %d): %v", backoff, attempt, retries, err)
+		time.Sleep(backoff)
+
+		// Stop retrying if the user started or cancelled a connection in the meantime
+		if tm.State() != StateStopped {
+			logger.Info("Tunnel state changed while waiting to retry, giving up")
+			return fmt.Errorf("%w: %w", ErrConnectRetryCancelled, err)
+		}
+
+		if err = tm.Connect(); err == nil {
+			return nil
+		}
+
+		backoff *= 2
+		if backoff > connectRetryMaxBackoff {
+			backoff = connectRetryMaxBackoff
+		}
+	}
+
+	return err
+}
+
 // Disconnect stops the tunnel
 func (tm *Manager) Disconnect() error {
 	tm.mu.RLock()
@@ -403,6 +621,77 @@ func (tm *Manager) Disconnect() error {
 	return nil
 }
 
+// disconnectWaitTimeout bounds how long DisconnectAndWait waits for the tunnel to stop
+const disconnectWaitTimeout = 30 * time.Second
+
+// DisconnectAndWait disconnects the tunnel and waits for it to reach the stopped state
+func (tm *Manager) DisconnectAndWait() error {
+	if err := tm.Disconnect(); err != nil {
+		return formatConnectionError(
+			ConnectionErrorIPC,
+			"Disconnect Failed",
+			fmt.Sprintf("Failed to stop the tunnel: %v", err),
+			err,
+		)
+	}
+
+	deadline := time.Now().Add(disconnectWaitTimeout)
+	for tm.State() != StateStopped {
+		if time.Now().After(deadline) {
+			logger.Error("Timed out waiting for tunnel to stop")
+			return formatConnectionError(
+				ConnectionErrorIPC,
+				"Disconnect Failed",
+				"Timed out waiting for the tunnel to stop. Please try again.",
+				nil,
+			)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return nil
+}
+
+// Reconnect disconnects the tunnel, waits for it to reach the stopped state, and connects again
+func (tm *Manager) Reconnect() error {
+	logger.Info("Reconnecting tunnel")
+	if err := tm.DisconnectAndWait(); err != nil {
+		if connErr, ok := err.(*ConnectionError); ok {
+			connErr.Title = "Reconnect Failed"
+		}
+		return err
+	}
+
+	return tm.ConnectWithRetry()
+}
+
+// resumeSettleDelay gives the network time to come back after resume before the tunnel is verified
+const resumeSettleDelay = 5 * time.Second
+
+// VerifyAfterResume re-checks a tunnel that was connected before the system
+// slept and reconnects it if OLM reports it is no longer up
+func (tm *Manager) VerifyAfterResume() error {
+	state := tm.State()
+	if state != StateRunning && state != StateReconnecting {
+		return nil
+	}
+
+	time.Sleep(resumeSettleDelay)
+
+	status, err := tm.GetOLMStatus()
+	if err == nil && status.Connected && status.Registered {
+		logger.Info("Tunnel is still connected after resume")
+		return nil
+	}
+	if err != nil {
+		logger.Info("Tunnel status unavailable after resume, reconnecting: %v", err)
+	} else {
+		logger.Info("Tunnel is down after resume, reconnecting")
+	}
+
+	return tm.Reconnect()
+}
+
 // OLMStatusError represents an error in the OLM status response
 type OLMStatusError struct {
 	Code    string `json:"code"`
@@ -445,6 +734,32 @@ type OLMPeerStatus struct {
 	PeerIP    string        `json:"peerAddress,omitempty"`
 }
 
+// maxPlausibleRTT is the largest round-trip time shown as a value; anything
+// above it (e.g. an unset sentinel) is displayed as a timeout instead.
+const maxPlausibleRTT = time.Minute
+
+// FormatRTT formats a peer round-trip time for display: "pending" when not yet
+// measured, "timeout" for implausibly large or negative values, otherwise
+// whole milliseconds (or fractional below 1ms).
+func FormatRTT(rtt time.Duration) string {
+	switch {
+	case rtt == 0:
+		return "pending"
+	case rtt < 0 || rtt > maxPlausibleRTT:
+		return "timeout"
+	case rtt < time.Millisecond:
+		return fmt.Sprintf("%.1fms", float64(rtt)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%dms", rtt.Milliseconds())
+	}
+}
+
+// IsMeasuredRTT reports whether rtt is an actual measurement, i.e. FormatRTT
+// would show it as a value rather than "pending" or "timeout".
+func IsMeasuredRTT(rtt time.Duration) bool {
+	return rtt > 0 && rtt <= maxPlausibleRTT
+}
+
 // SwitchOrgRequest represents the request body for switching organizations
 type SwitchOrgRequest struct {
 	OrgID string `json:"org_id"`
@@ -452,11 +767,28 @@ type SwitchOrgRequest struct {
 
 // getOLMPipePath returns the Windows named pipe path for OLM
 func getOLMPipePath() string {
-	return OLMNamedPipePath
+	return OLMPipePath()
+}
+
+// olmPipeTimeout bounds a request to OLM over the named pipe
+const olmPipeTimeout = 10 * time.Second
+
+// olmRequestTimeout returns how long a request to OLM may take for this
+// user, stretched in slow network mode. It travels with each request, since
+// the manager service making them is shared by every signed-in user.
+func (tm *Manager) olmRequestTimeout() time.Duration {
+	slow := tm.configManager != nil && tm.configManager.GetSlowNetwork()
+	return config.ScaleForNetwork(olmPipeTimeout, slow)
 }
 
-// createOLMHTTPClient creates an HTTP client that can connect to OLM via named pipe
-func createOLMHTTPClient() (*http.Client, error) {
+// createOLMHTTPClient creates an HTTP client that can connect to OLM via named
+// pipe. A timeout outside what slow network mode allows is replaced with
+// olmPipeTimeout.
+func createOLMHTTPClient(timeout time.Duration) (*http.Client, error) {
+	if timeout <= 0 || timeout > config.ScaleForNetwork(olmPipeTimeout, true) {
+		timeout = olmPipeTimeout
+	}
+
 	pipePath := getOLMPipePath()
 
 	// Create a custom transport that dials the named pipe
@@ -472,15 +804,26 @@ func createOLMHTTPClient() (*http.Client, error) {
 
 	client := &http.Client{
 		Transport: transport,
-		Timeout:   10 * time.Second,
+		Timeout:   timeout,
 	}
 
 	return client, nil
 }
 
-// GetOLMStatus retrieves the status from OLM via the named pipe API
+// GetOLMStatus retrieves the OLM status through the manager service, which
+// owns access to the OLM named pipe
 func (tm *Manager) GetOLMStatus() (*OLMStatusResponse, error) {
-	client, err := createOLMHTTPClient()
+	if tm.ipcClient == nil {
+		return nil, fmt.Errorf("manager service is not connected")
+	}
+	return tm.ipcClient.GetTunnelStatus(tm.olmRequestTimeout())
+}
+
+// FetchOLMStatus retrieves the status from OLM via the named pipe API. Only
+// the manager service calls this, with the timeout the UI asked for; the UI
+// goes through GetOLMStatus.
+func FetchOLMStatus(timeout time.Duration) (*OLMStatusResponse, error) {
+	client, err := createOLMHTTPClient(timeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OLM HTTP client: %w", err)
 	}
@@ -511,7 +854,22 @@ func (tm *Manager) GetOLMStatus() (*OLMStatusResponse, error) {
 	return &statusResp, nil
 }
 
-// SwitchOLMOrg switches the organization in OLM via the named pipe API
+// ListResources returns the currently selected organization and the resources
+// the tunnel routes to in it
+func (tm *Manager) ListResources() (*api.Org, []api.Resource, error) {
+	org := tm.authManager.CurrentOrg()
+	if org == nil {
+		return nil, nil, fmt.Errorf("no organization is selected")
+	}
+	resp, err := tm.authManager.APIClient().ListResources(org.Id)
+	if err != nil {
+		return org, nil, err
+	}
+	return org, resp.Resources, nil
+}
+
+// SwitchOLMOrg switches the organization in OLM. Like status polling it goes
+// through the manager service, the only process that knows the OLM pipe path.
 func (tm *Manager) SwitchOLMOrg(orgID string) error {
 	tm.mu.RLock()
 	currentState := tm.currentState
@@ -527,9 +885,24 @@ func (tm *Manager) SwitchOLMOrg(orgID string) error {
 		return fmt.Errorf("orgID cannot be empty")
 	}
 
+	if tm.ipcClient == nil {
+		return fmt.Errorf("manager service is not connected")
+	}
+
 	logger.Info("Switching tunnel organization to: %s", orgID)
 
-	client, err := createOLMHTTPClient()
+	if err := tm.ipcClient.SwitchTunnelOrg(orgID, tm.olmRequestTimeout()); err != nil {
+		return err
+	}
+
+	logger.Info("Successfully switched OLM organization to: %s", orgID)
+	return nil
+}
+
+// PostOLMSwitchOrg asks OLM over its named pipe to switch to orgID within
+// timeout; the manager service calls this, the UI goes through SwitchOLMOrg.
+func PostOLMSwitchOrg(orgID string, timeout time.Duration) error {
+	client, err := createOLMHTTPClient(timeout)
 	if err != nil {
 		return fmt.Errorf("failed to create OLM HTTP client: %w", err)
 	}
@@ -560,16 +933,21 @@ func (tm *Manager) SwitchOLMOrg(orgID string) error {
 		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("OLM API returned status %d: %s", resp.StatusCode, string(body))
 	}
-
-	logger.Info("Successfully switched OLM organization to: %s", orgID)
 	return nil
 }
 
-// How many consecutive 1s poll failures (or lost-connection reports) while
+// How many consecutive poll failures (or lost-connection reports) while
 // StateRunning before we treat the tunnel as dead and disconnect.
 const statusUnreachableThreshold = 3
 
-// StartStatusPolling starts polling the OLM status endpoint every 1 second
+// Status poll cadence; failed polls back off exponentially up to the max
+// so a pipe that isn't ready yet doesn't flood the log.
+const (
+	statusPollInterval    = 1 * time.Second
+	statusPollMaxInterval = 10 * time.Second
+)
+
+// StartStatusPolling starts polling the OLM status endpoint every second, backing off on failures
 func (tm *Manager) StartStatusPolling() {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
@@ -586,37 +964,78 @@ func (tm *Manager) StartStatusPolling() {
 	// Start polling goroutine
 	// Capture context to avoid race conditions
 	pollCtx := tm.pollCtx
+	connID := tm.connectionID
+	connectTimeout := time.Duration(config.DefaultConnectTimeout) * time.Second
+	if tm.configManager != nil {
+		connectTimeout = time.Duration(tm.configManager.GetConnectTimeoutSeconds()) * time.Second
+	}
+	connectDeadline := time.Now().Add(connectTimeout)
+	// Poll less often on slow networks, where OLM is slower to answer too
+	slowNetwork := tm.configManager != nil && tm.configManager.GetSlowNetwork()
+	basePollInterval := config.ScaleForNetwork(statusPollInterval, slowNetwork)
+	maxPollInterval := config.ScaleForNetwork(statusPollMaxInterval, slowNetwork)
+	go tm.monitorOrgAccess(pollCtx, connID)
 	go func() {
-		ticker := time.NewTicker(1 * time.Second)
+		ticker := time.NewTicker(basePollInterval)
 		defer ticker.Stop()
 
+		pollInterval := basePollInterval
+		pollErrors := 0
+		lastPollErr := ""
 		consecutiveFailures := 0
 		consecutiveLost := 0
+		consecutiveAdapterDown := 0
 
 		for {
 			select {
 			case <-pollCtx.Done():
-				logger.Info("Status polling stopped")
+				logger.Info("[conn %s] Status polling stopped", connID)
 				tm.mu.Lock()
 				tm.pollingActive = false
 				tm.mu.Unlock()
+				tm.updateStalePeers(connID, nil)
+				tm.updatePeerEvents(connID, nil)
 				return
 			case <-ticker.C:
+				// Give up if registration never completes
+				if isTransitionalConnectState(tm.State()) && time.Now().After(connectDeadline) {
+					tm.handleConnectTimeout(connID, connectTimeout)
+					return
+				}
+
 				// Poll the status
 				status, err := tm.GetOLMStatus()
 				if err != nil {
-					logger.Error("Failed to poll OLM status: %v", err)
+					// Only log an error once per distinct failure; repeats go to debug
+					if err.Error() != lastPollErr {
+						logger.Error("[conn %s] Failed to poll OLM status: %v", connID, err)
+						lastPollErr = err.Error()
+					} else {
+						logger.Debug("[conn %s] Failed to poll OLM status: %v", connID, err)
+					}
+					pollErrors++
 					tm.mu.RLock()
 					currentState := tm.currentState
 					tm.mu.RUnlock()
 					// Only treat pipe failures as fatal once we were fully connected.
-					// During startup the named pipe may not be ready yet.
-					if currentState == StateRunning {
+					// During startup the named pipe may not be ready yet, so back off
+					// instead. Once running, keep the base interval so the failure
+					// threshold still means a few seconds.
+					if currentState != StateRunning {
+						if pollInterval < maxPollInterval {
+							pollInterval = min(pollInterval*2, maxPollInterval)
+							ticker.Reset(pollInterval)
+						}
+					} else {
+						if pollInterval != basePollInterval {
+							pollInterval = basePollInterval
+							ticker.Reset(pollInterval)
+						}
 						consecutiveFailures++
 						if consecutiveFailures >= statusUnreachableThreshold {
-							logger.Info("OLM unreachable after %d consecutive poll failures, disconnecting", consecutiveFailures)
+							logger.Info("[conn %s] OLM unreachable after %d consecutive poll failures, disconnecting", connID, consecutiveFailures)
 							if discErr := tm.Disconnect(); discErr != nil {
-								logger.Error("Failed to disconnect tunnel after poll failures: %v", discErr)
+								logger.Error("[conn %s] Failed to disconnect tunnel after poll failures: %v", connID, discErr)
 							}
 							consecutiveFailures = 0
 							consecutiveLost = 0
@@ -624,6 +1043,15 @@ func (tm *Manager) StartStatusPolling() {
 					}
 					continue
 				}
+				if pollErrors > 0 {
+					logger.Info("[conn %s] OLM status poll succeeded after %d failed attempts", connID, pollErrors)
+					pollErrors = 0
+					lastPollErr = ""
+				}
+				if pollInterval != basePollInterval {
+					pollInterval = basePollInterval
+					ticker.Reset(pollInterval)
+				}
 				consecutiveFailures = 0
 
 				// This should be checked before checking termination or state updates
@@ -635,13 +1063,13 @@ func (tm *Manager) StartStatusPolling() {
 
 					// Only handle errors during registration phase (not yet fully connected)
 					if currentState != StateRunning {
-						logger.Error("OLM status indicates error during registration: code=%s, message=%s", status.Error.Code, status.Error.Message)
+						logger.Error("[conn %s] OLM status indicates error during registration: code=%s, message=%s", connID, status.Error.Code, status.Error.Message)
 						if _, isSessionExpired := sessionExpiredErrorCodes[status.Error.Code]; isSessionExpired {
 							tm.authManager.MarkSessionExpired()
 						}
 						// Stop the tunnel immediately
 						if err := tm.Disconnect(); err != nil {
-							logger.Error("Failed to disconnect tunnel after error: %v", err)
+							logger.Error("[conn %s] Failed to disconnect tunnel after error: %v", connID, err)
 						}
 						// Notify UI of the error
 						tm.mu.Lock()
@@ -656,9 +1084,9 @@ func (tm *Manager) StartStatusPolling() {
 
 				// If terminated, disconnect the tunnel
 				if status.Terminated {
-					logger.Info("OLM status indicates terminated, disconnecting tunnel")
+					logger.Info("[conn %s] OLM status indicates terminated, disconnecting tunnel", connID)
 					if err := tm.Disconnect(); err != nil {
-						logger.Error("Failed to disconnect tunnel after termination: %v", err)
+						logger.Error("[conn %s] Failed to disconnect tunnel after termination: %v", connID, err)
 					}
 					continue
 				}
@@ -682,9 +1110,9 @@ func (tm *Manager) StartStatusPolling() {
 					if currentState == StateRunning {
 						consecutiveLost++
 						if consecutiveLost >= statusUnreachableThreshold {
-							logger.Info("OLM reports not connected/registered after %d polls, disconnecting", consecutiveLost)
+							logger.Info("[conn %s] OLM reports not connected/registered after %d polls, disconnecting", connID, consecutiveLost)
 							if discErr := tm.Disconnect(); discErr != nil {
-								logger.Error("Failed to disconnect tunnel after lost connection: %v", discErr)
+								logger.Error("[conn %s] Failed to disconnect tunnel after lost connection: %v", connID, discErr)
 							}
 							consecutiveLost = 0
 						}
@@ -692,6 +1120,34 @@ func (tm *Manager) StartStatusPolling() {
 					continue
 				}
 
+				// OLM can keep reporting connected after the user disables the
+				// tunnel adapter in Windows, so verify the adapter is still there.
+				if newState == StateRunning {
+					tm.mu.RLock()
+					currentState := tm.currentState
+					interfaceName := tm.interfaceName
+					tm.mu.RUnlock()
+					if currentState == StateRunning && interfaceName != "" {
+						if adapterErr := checkTunnelAdapter(interfaceName); adapterErr != nil {
+							consecutiveAdapterDown++
+							if consecutiveAdapterDown >= statusUnreachableThreshold {
+								logger.Error("[conn %s] Tunnel adapter check failed after %d polls: %v", connID, consecutiveAdapterDown, adapterErr)
+								tm.handleAdapterDisabled()
+								return
+							}
+							continue
+						}
+					}
+					consecutiveAdapterDown = 0
+				}
+
+				// Flag sites that stopped answering while OLM still reports connected
+				if newState == StateRunning {
+					tm.updateStalePeers(connID, status.PeerStatuses)
+				} else {
+					tm.updateStalePeers(connID, nil)
+				}
+
 				// Update the global tunnel state (for consistency with GetState())
 				SetState(newState)
 
@@ -706,6 +1162,10 @@ func (tm *Manager) StartStatusPolling() {
 				tm.isConnected = (newState == StateRunning)
 				callback := tm.stateCallback
 				tm.mu.Unlock()
+				tm.updateStateEvent(newState)
+				if newState == StateRunning {
+					tm.updatePeerEvents(connID, status.PeerStatuses)
+				}
 
 				// Only trigger callback if state actually changed
 				if oldState != newState && callback != nil {
@@ -715,7 +1175,65 @@ func (tm *Manager) StartStatusPolling() {
 		}
 	}()
 
-	logger.Info("Started OLM status polling (every 1 second)")
+	logger.Info("[conn %s] Started OLM status polling (every 1 second)", connID)
+}
+
+// handleAdapterDisabled moves the tunnel to StateError and reports the disabled
+// adapter through the error callback. Polling is stopped so the state is not
+// flipped back to running by a stale OLM status.
+func (tm *Manager) handleAdapterDisabled() {
+	tm.StopStatusPolling()
+	SetState(StateError)
+	tm.setLocalState(StateError)
+
+	tm.mu.RLock()
+	errorCb := tm.errorCallback
+	tm.mu.RUnlock()
+	if errorCb != nil {
+		errorCb(&OLMStatusError{
+			Code:    adapterDisabledErrorCode,
+			Message: "The Pangolin network adapter was disabled.",
+		})
+	}
+}
+
+// handleConnectTimeout abandons a connect attempt that did not register in
+// time: the tunnel is moved to StateError and stopped, and the timeout is
+// reported through the connect error callback
+func (tm *Manager) handleConnectTimeout(connID string, timeout time.Duration) {
+	logger.Error("[conn %s] Tunnel did not register within %v, disconnecting", connID, timeout)
+	tm.setLocalState(StateError)
+	if err := tm.Disconnect(); err != nil {
+		logger.Error("[conn %s] Failed to disconnect tunnel after connect timeout: %v", connID, err)
+	}
+
+	tm.mu.RLock()
+	connectErrCb := tm.connectErrCb
+	tm.mu.RUnlock()
+	if connectErrCb != nil {
+		connectErrCb(formatConnectionError(
+			ConnectionErrorTimeout,
+			"Connection Timed Out",
+			fmt.Sprintf("The tunnel did not finish connecting within %d seconds. Check your network connection and try again.", int(timeout.Seconds())),
+			nil,
+		))
+	}
+}
+
+// handleTunnelCrashed stops polling the crashed tunnel and reports the crash
+// through the error callback
+func (tm *Manager) handleTunnelCrashed() {
+	tm.StopStatusPolling()
+
+	tm.mu.RLock()
+	errorCb := tm.errorCallback
+	tm.mu.RUnlock()
+	if errorCb != nil {
+		errorCb(&OLMStatusError{
+			Code:    tunnelCrashedErrorCode,
+			Message: "Tunnel crashed. The Pangolin tunnel service stopped unexpectedly and could not be restarted.",
+		})
+	}
 }
 
 // StopStatusPolling stops the status polling
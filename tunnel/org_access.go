@@ -0,0 +1,71 @@
+//go:build windows
+
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fosrl/windows/auth"
+
+	"github.com/fosrl/newt/logger"
+)
+
+// orgAccessCheckInterval is how often organization access is rechecked while
+// connected, so policy changes such as a failed posture check take effect
+// mid-session
+const orgAccessCheckInterval = 5 * time.Minute
+
+// RegisterOrgAccessLostCallback registers a callback run after the tunnel was
+// disconnected because an organization policy now denies access
+func (tm *Manager) RegisterOrgAccessLostCallback(cb func(*auth.OrgAccessDeniedError)) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.orgDeniedCb = cb
+}
+
+// monitorOrgAccess rechecks access to the connected organization until ctx is
+// done. Only a policy denial disconnects; other failures, such as the server
+// being unreachable, are left to the next check.
+func (tm *Manager) monitorOrgAccess(ctx context.Context, connID string) {
+	ticker := time.NewTicker(orgAccessCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if tm.State() != StateRunning || tm.authManager == nil {
+				continue
+			}
+			org := tm.authManager.CurrentOrg()
+			if org == nil {
+				continue
+			}
+
+			_, err := tm.authManager.CheckOrgAccess(org.Id)
+			var deniedErr *auth.OrgAccessDeniedError
+			if !errors.As(err, &deniedErr) {
+				if err != nil {
+					logger.Debug("[conn %s] Organization access recheck failed: %v", connID, err)
+				}
+				continue
+			}
+
+			logger.Warn("[conn %s] Access to organization %s was revoked by policy, disconnecting: %s", connID, org.Id, deniedErr.Reason)
+			if err := tm.Disconnect(); err != nil {
+				logger.Error("[conn %s] Failed to disconnect after losing organization access: %v", connID, err)
+			}
+
+			tm.mu.RLock()
+			cb := tm.orgDeniedCb
+			tm.mu.RUnlock()
+			if cb != nil {
+				cb(deniedErr)
+			}
+			return
+		}
+	}
+}
@@ -23,12 +23,14 @@ func RunTunnelService(configJSON string) error {
 type tunnelService struct {
 	configJSON string
 
-	olm *olm.Olm
+	olm           *olm.Olm
+	routeExcluder *routeExcluder
 }
 
 func (s *tunnelService) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (svcSpecificEC bool, exitCode uint32) {
 	changes <- svc.Status{State: svc.StartPending}
 	logger.Info("Tunnel service: Service starting")
+	ResolveOLMPipePath()
 
 	// Parse and log config
 	config, err := ConfigFromJSON(s.configJSON)
@@ -36,6 +38,7 @@ func (s *tunnelService) Execute(args []string, r <-chan svc.ChangeRequest, chang
 		logger.Error("Tunnel service: Failed to parse config: %v", err)
 		return false, 1
 	}
+	logger.Info("Tunnel service: [conn %s] Config loaded", config.ConnectionID)
 
 	// Set state to registering when service starts (before OLM initialization)
 	SetState(StateRegistering)
@@ -44,7 +47,7 @@ func (s *tunnelService) Execute(args []string, r <-chan svc.ChangeRequest, chang
 
 	// Build and start the tunnel
 	if err := s.buildTunnel(config); err != nil {
-		logger.Error("Tunnel service: Failed to build tunnel: %v", err)
+		logger.Error("Tunnel service: [conn %s] Failed to build tunnel: %v", config.ConnectionID, err)
 		SetState(StateStopped)
 		notifyStateChange(StateStopped)
 		return false, 1
@@ -0,0 +1,113 @@
+//go:build windows
+
+package tunnel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fosrl/newt/logger"
+)
+
+// scheduleCheckInterval is how often the connect schedule is evaluated
+const scheduleCheckInterval = 30 * time.Second
+
+// ErrAutoConnectOrgUnavailable is returned by ConnectOnLaunch when the
+// organization pinned for automatic connects is gone or access is denied
+var ErrAutoConnectOrgUnavailable = errors.New("auto-connect organization is no longer available")
+
+// StartScheduler evaluates the connect schedule from preferences in the
+// background. The tunnel is connected when a scheduled window starts and
+// disconnected when it ends; in between, manual connects and disconnects
+// are left alone.
+func (tm *Manager) StartScheduler() {
+	go func() {
+		wasActive := false
+		for {
+			schedule := tm.configManager.GetConnectSchedule()
+			active := schedule.Active(time.Now())
+			if active != wasActive {
+				if active {
+					tm.scheduledConnect()
+				} else if schedule.Enabled {
+					tm.scheduledDisconnect()
+				}
+				wasActive = active
+			}
+			time.Sleep(scheduleCheckInterval)
+		}
+	}()
+}
+
+func (tm *Manager) scheduledConnect() {
+	if !tm.authManager.IsAuthenticated() || tm.authManager.SessionExpired() {
+		logger.Info("Scheduled connect skipped: not signed in")
+		return
+	}
+	if tm.State() != StateStopped {
+		return
+	}
+	_ = tm.selectAutoConnectOrg()
+	logger.Info("Connecting tunnel for scheduled window")
+	if err := tm.ConnectWithRetry(); err != nil {
+		logger.Error("Scheduled connect failed: %v", err)
+	}
+}
+
+func (tm *Manager) scheduledDisconnect() {
+	if tm.State() == StateStopped {
+		return
+	}
+	logger.Info("Disconnecting tunnel at end of scheduled window")
+	if err := tm.Disconnect(); err != nil {
+		logger.Error("Scheduled disconnect failed: %v", err)
+	}
+}
+
+// ConnectOnLaunch connects the tunnel when the app starts, after selecting
+// the organization pinned for automatic connects. If that org is no longer
+// accessible it does not connect and returns ErrAutoConnectOrgUnavailable.
+func (tm *Manager) ConnectOnLaunch() error {
+	if tm.State() != StateStopped {
+		return nil
+	}
+	if tm.configManager.GetAutoConnectOrgID() != "" {
+		if err := tm.authManager.RefreshOrganizations(); err != nil {
+			logger.Warn("Failed to refresh organizations before connecting on launch: %v", err)
+		}
+	}
+	if err := tm.selectAutoConnectOrg(); err != nil {
+		return err
+	}
+	logger.Info("Connecting tunnel on launch")
+	return tm.ConnectWithRetry()
+}
+
+// selectAutoConnectOrg selects the organization pinned for automatic connects,
+// so they don't depend on whichever org happened to be selected last. If the
+// pinned org is gone or access is denied, the current org is kept and
+// ErrAutoConnectOrgUnavailable is returned.
+func (tm *Manager) selectAutoConnectOrg() error {
+	orgID := tm.configManager.GetAutoConnectOrgID()
+	if orgID == "" {
+		return nil
+	}
+	if currentOrg := tm.authManager.CurrentOrg(); currentOrg != nil && currentOrg.Id == orgID {
+		return nil
+	}
+
+	for _, org := range tm.authManager.Organizations() {
+		if org.Id != orgID {
+			continue
+		}
+		err := tm.authManager.SelectOrganization(&org)
+		if currentOrg := tm.authManager.CurrentOrg(); err != nil || currentOrg == nil || currentOrg.Id != orgID {
+			logger.Warn("Could not select auto-connect organization %s, keeping current organization: %v", orgID, err)
+			return ErrAutoConnectOrgUnavailable
+		}
+		logger.Info("Selected auto-connect organization %s", orgID)
+		return nil
+	}
+	logger.Warn("Auto-connect organization %s is no longer available, keeping current organization", orgID)
+	return ErrAutoConnectOrgUnavailable
+}
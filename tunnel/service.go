@@ -4,8 +4,12 @@ package tunnel
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"sync"
 
+	"github.com/fosrl/windows/config"
+
 	"github.com/fosrl/newt/logger"
 )
 
@@ -34,9 +38,41 @@ func notifyStateChange(state State) {
 	}
 }
 
-// OLMNamedPipePath is the Windows named pipe path for OLM API communication
+// OLMNamedPipePath is the default Windows named pipe path for OLM API communication
 const OLMNamedPipePath = `\\.\pipe\pangolin-olm`
 
+// olmPipePrefix is the prefix every local named pipe path starts with
+const olmPipePrefix = `\\.\pipe\`
+
+// olmPipePath is the named pipe path for OLM API communication, set once by
+// ResolveOLMPipePath when the service starts
+var olmPipePath = OLMNamedPipePath
+
+// ResolveOLMPipePath reads the OLM pipe path from the system config file,
+// e.g. for a side-by-side test install, falling back to OLMNamedPipePath.
+// Both the tunnel service, which serves the pipe, and the manager service,
+// which makes every request to it for the UIs, call it once at start so they
+// agree.
+func ResolveOLMPipePath() {
+	path := config.GetSystemOLMPipePath()
+	if path == "" {
+		olmPipePath = OLMNamedPipePath
+		return
+	}
+	if !strings.HasPrefix(strings.ToLower(path), olmPipePrefix) || len(path) == len(olmPipePrefix) {
+		logger.Warn("Ignoring invalid OLM pipe path %q, using %s", path, OLMNamedPipePath)
+		olmPipePath = OLMNamedPipePath
+		return
+	}
+	logger.Info("Using OLM pipe path %s from the system config", path)
+	olmPipePath = path
+}
+
+// OLMPipePath returns the named pipe path for OLM API communication
+func OLMPipePath() string {
+	return olmPipePath
+}
+
 // State represents the state of a tunnel
 type State int
 
@@ -114,6 +150,7 @@ type Config struct {
 	MTU                 int      `json:"mtu"`
 	DNS                 string   `json:"dns"`
 	Holepunch           bool     `json:"holepunch"`
+	DisableRelay        bool     `json:"disableRelay,omitempty"`
 	PingIntervalSeconds int      `json:"pingIntervalSeconds"`
 	PingTimeoutSeconds  int      `json:"pingTimeoutSeconds"`
 	UserToken           string   `json:"userToken"`
@@ -124,16 +161,46 @@ type Config struct {
 	OverrideDNS         bool     `json:"overrideDns"`
 	TunnelDNS           bool     `json:"tunnelDns"`
 	PreferLocalRoutes   bool     `json:"preferLocalRoutes"`
+	ConnectionID        string   `json:"connectionId,omitempty"` // correlates log lines for one connect attempt
+	ExcludedRoutes      []string `json:"excludedRoutes,omitempty"`
+	LogLevel            string   `json:"logLevel,omitempty"`
+	TLSSkipVerify       bool     `json:"tlsSkipVerify,omitempty"`
+	RestartRetries      int      `json:"restartRetries"` // times the manager restarts a crashed tunnel service
 
 	InitialFingerprint json.RawMessage `json:"initialFingerprint,omitempty"`
 	InitialPostures    json.RawMessage `json:"initialPostures,omitempty"`
 }
 
+// Validate checks that the fields required to start the tunnel are set
+func (c Config) Validate() error {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"ID", c.ID},
+		{"Secret", c.Secret},
+		{"UserToken", c.UserToken},
+		{"Endpoint", c.Endpoint},
+		{"OrgID", c.OrgID},
+	}
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			return fmt.Errorf("%s is missing", field.name)
+		}
+	}
+	return nil
+}
+
 func StartTunnel(config Config) error {
 	logger.Info("Tunnel: StartTunnel called")
 
+	if err := config.Validate(); err != nil {
+		logger.Error("Tunnel: Invalid tunnel config: %v", err)
+		return err
+	}
+
 	// Log the config
-	logger.Info("Tunnel: Starting tunnel with config")
+	logger.Info("Tunnel: [conn %s] Starting tunnel with config", config.ConnectionID)
 
 	// Store tunnel name for later use
 	tunnelNameLock.Lock()
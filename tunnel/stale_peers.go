@@ -0,0 +1,111 @@
+//go:build windows
+
+package tunnel
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/fosrl/windows/config"
+
+	"github.com/fosrl/newt/logger"
+)
+
+// stalePeerPingMultiple is how many ping intervals a peer may be reported down
+// before it is flagged as stale
+const stalePeerPingMultiple = 2
+
+// StalePeerThreshold returns how long a peer may be reported down before it
+// is considered stale
+func (tm *Manager) StalePeerThreshold() time.Duration {
+	interval := config.DefaultPingInterval
+	if tm.configManager != nil {
+		interval = tm.configManager.GetPingIntervalSeconds()
+	}
+	return time.Duration(interval*stalePeerPingMultiple) * time.Second
+}
+
+// IsPeerStale reports whether a site that was connected earlier has been down
+// since downSince for longer than threshold, i.e. the site stopped answering
+// while the tunnel itself still reports running. A zero downSince means the
+// site is up or never came up.
+//
+// OLM's LastSeen is not used: it only changes with the peer's status, so it
+// goes stale for healthy sites, relayed ones in particular.
+func IsPeerStale(downSince time.Time, threshold time.Duration, now time.Time) bool {
+	return !downSince.IsZero() && now.Sub(downSince) > threshold
+}
+
+// PeerDownSince returns when a site that had been connected was reported
+// down, or the zero time if it is up or never came up
+func (tm *Manager) PeerDownSince(siteID int) time.Time {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.peerDownSince[siteID]
+}
+
+// StalePeers returns the names of the sites currently flagged as stale
+func (tm *Manager) StalePeers() []string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return slices.Clone(tm.stalePeers)
+}
+
+// RegisterStalePeersCallback registers a callback run with the stale site
+// names whenever the set of stale sites changes
+func (tm *Manager) RegisterStalePeersCallback(cb func([]string)) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.stalePeersCb = cb
+}
+
+// updateStalePeers records when connected sites go down, flags those down for
+// longer than the threshold and notifies the callback when the set changes.
+// Pass nil peers to clear it.
+func (tm *Manager) updateStalePeers(connID string, peers map[int]*OLMPeerStatus) {
+	threshold := tm.StalePeerThreshold()
+	now := time.Now()
+	stale := []string{}
+
+	tm.mu.Lock()
+	downSince := make(map[int]time.Time, len(peers))
+	for id, peer := range peers {
+		if peer == nil {
+			continue
+		}
+		since, known := tm.peerDownSince[id]
+		switch {
+		case peer.Connected:
+			since = time.Time{}
+		case !known:
+			// Never came up in this connection, so it is still connecting
+			continue
+		case since.IsZero():
+			since = now
+		}
+		downSince[id] = since
+		if IsPeerStale(since, threshold, now) {
+			stale = append(stale, peer.SiteName)
+		}
+	}
+	tm.peerDownSince = downSince
+	sort.Strings(stale)
+
+	if slices.Equal(stale, tm.stalePeers) {
+		tm.mu.Unlock()
+		return
+	}
+	tm.stalePeers = stale
+	cb := tm.stalePeersCb
+	tm.mu.Unlock()
+
+	if len(stale) > 0 {
+		logger.Warn("[conn %s] %d site(s) down for over %v: %v", connID, len(stale), threshold, stale)
+	} else if peers != nil {
+		logger.Info("[conn %s] All sites are reachable again", connID)
+	}
+	if cb != nil {
+		cb(slices.Clone(stale))
+	}
+}
@@ -0,0 +1,59 @@
+//go:build windows
+
+package ui
+
+import (
+	"fmt"
+
+	"github.com/fosrl/newt/logger"
+)
+
+// refreshAccountNow re-fetches the organizations and the user's device state
+// on demand, for users who just changed their memberships on the dashboard.
+// The menu action is disabled while it runs and a notification reports the
+// outcome.
+func refreshAccountNow() {
+	if authManager == nil || !authManager.IsAuthenticated() {
+		return
+	}
+
+	accountRefreshingM.Lock()
+	if accountRefreshing {
+		accountRefreshingM.Unlock()
+		return
+	}
+	accountRefreshing = true
+	accountRefreshingM.Unlock()
+	updateMenu()
+
+	defer func() {
+		accountRefreshingM.Lock()
+		accountRefreshing = false
+		accountRefreshingM.Unlock()
+		updateMenu()
+	}()
+
+	err := authManager.RefreshOrganizations()
+	if err == nil {
+		if olmId, found := authManager.GetOlmId(); found && olmId != "" {
+			err = authManager.RefreshFromMyDevice(olmId)
+		}
+	}
+	if err != nil {
+		logger.Error("Failed to refresh account: %v", err)
+		backOffIfRateLimited(err)
+	}
+	disconnectIfLoggedOut()
+
+	if trayIcon == nil {
+		return
+	}
+	switch {
+	case err != nil:
+		trayIcon.ShowError("Refresh Failed", fmt.Sprintf("Could not refresh your account: %v", err))
+	case !authManager.IsAuthenticated():
+		trayIcon.ShowWarning("Logged Out", "Your session is no longer valid. Log in again to continue.")
+	default:
+		trayIcon.ShowInfo("Account Refreshed", fmt.Sprintf("Your account and organizations are up to date (%d organizations).", len(authManager.Organizations())))
+	}
+}
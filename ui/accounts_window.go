@@ -0,0 +1,187 @@
+//go:build windows
+
+package ui
+
+import (
+	"path/filepath"
+
+	"github.com/fosrl/windows/config"
+	"github.com/fosrl/windows/ui/theme"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	. "github.com/tailscale/walk/declarative"
+)
+
+// showManageAccountsDialog lets the user give accounts friendly labels and
+// change the order they are listed in. Must be called on the UI thread.
+func showManageAccountsDialog() {
+	if accountManager == nil {
+		return
+	}
+
+	var dlg *walk.Dialog
+	var accountList *walk.ListBox
+	var labelEdit *walk.LineEdit
+	var upButton, downButton, saveLabelButton, closeButton *walk.PushButton
+	var accounts []config.Account
+
+	names := func() []string {
+		emailCounts := map[string]int{}
+		for _, account := range accounts {
+			emailCounts[account.Email]++
+		}
+		items := make([]string, len(accounts))
+		for i := range accounts {
+			items[i] = accountMenuText(&accounts[i], emailCounts[accounts[i].Email] > 1)
+		}
+		return items
+	}
+
+	selected := func() *config.Account {
+		index := accountList.CurrentIndex()
+		if index < 0 || index >= len(accounts) {
+			return nil
+		}
+		return &accounts[index]
+	}
+
+	updateButtons := func() {
+		index := accountList.CurrentIndex()
+		upButton.SetEnabled(index > 0)
+		downButton.SetEnabled(index >= 0 && index < len(accounts)-1)
+		saveLabelButton.SetEnabled(index >= 0)
+		labelEdit.SetEnabled(index >= 0)
+	}
+
+	// reload refreshes the list from the account store and selects userID
+	reload := func(userID string) {
+		accounts = accountManager.OrderedAccounts()
+		accountList.SetModel(names())
+		for i, account := range accounts {
+			if account.UserID == userID {
+				accountList.SetCurrentIndex(i)
+				break
+			}
+		}
+		updateButtons()
+		updateMenu()
+	}
+
+	move := func(offset int) {
+		account := selected()
+		if account == nil {
+			return
+		}
+		userID := account.UserID
+		if err := accountManager.MoveAccount(userID, offset); err != nil {
+			logger.Error("Failed to reorder accounts: %v", err)
+			return
+		}
+		reload(userID)
+	}
+
+	err := Dialog{
+		AssignTo:     &dlg,
+		Title:        "Manage Accounts",
+		CancelButton: &closeButton,
+		MinSize:      Size{Width: 420, Height: 360},
+		Layout:       VBox{Margins: Margins{Left: 12, Top: 12, Right: 12, Bottom: 12}, Spacing: 8},
+		Children: []Widget{
+			Composite{
+				Layout: HBox{MarginsZero: true, Spacing: 8},
+				Children: []Widget{
+					ListBox{
+						AssignTo: &accountList,
+						OnCurrentIndexChanged: func() {
+							if account := selected(); account != nil {
+								labelEdit.SetText(account.Label)
+							} else {
+								labelEdit.SetText("")
+							}
+							updateButtons()
+						},
+					},
+					Composite{
+						Layout: VBox{MarginsZero: true, Spacing: 6},
+						Children: []Widget{
+							PushButton{
+								AssignTo:  &upButton,
+								Text:      "Move Up",
+								OnClicked: func() { move(-1) },
+							},
+							PushButton{
+								AssignTo:  &downButton,
+								Text:      "Move Down",
+								OnClicked: func() { move(1) },
+							},
+							VSpacer{},
+						},
+					},
+				},
+			},
+			Label{
+				Text: "Label (shown instead of the email; leave empty to show the email):",
+			},
+			Composite{
+				Layout: HBox{MarginsZero: true, Spacing: 8},
+				Children: []Widget{
+					LineEdit{
+						AssignTo:  &labelEdit,
+						CueBanner: "e.g. Work or Personal",
+						MaxLength: 64,
+					},
+					PushButton{
+						AssignTo: &saveLabelButton,
+						Text:     "Set Label",
+						OnClicked: func() {
+							account := selected()
+							if account == nil {
+								return
+							}
+							userID := account.UserID
+							if err := accountManager.SetAccountLabel(userID, labelEdit.Text()); err != nil {
+								logger.Error("Failed to save account label: %v", err)
+								return
+							}
+							reload(userID)
+						},
+					},
+				},
+			},
+			Composite{
+				Layout: HBox{MarginsZero: true, Spacing: 8},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						AssignTo: &closeButton,
+						Text:     "Close",
+						MinSize:  Size{Width: 75, Height: 0},
+						OnClicked: func() {
+							dlg.Cancel()
+						},
+					},
+				},
+			},
+		},
+	}.Create(mainWindow)
+	if err != nil {
+		logger.Error("Failed to create manage accounts dialog: %v", err)
+		return
+	}
+
+	if icon, err := walk.NewIconFromFile(filepath.Join(getIconsPath(), "icon-orange.ico")); err == nil {
+		dlg.SetIcon(icon)
+	}
+	palette := theme.Current()
+	theme.ApplyTitleBar(dlg.Handle(), palette)
+	theme.ApplyDark(dlg, palette)
+
+	activeUserID := ""
+	if account, err := accountManager.ActiveAccount(); err == nil {
+		activeUserID = account.UserID
+	}
+	reload(activeUserID)
+
+	dlg.Run()
+}
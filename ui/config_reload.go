@@ -0,0 +1,73 @@
+//go:build windows
+
+package ui
+
+import (
+	"github.com/fosrl/windows/config"
+	"github.com/fosrl/windows/managers"
+	"github.com/fosrl/windows/tunnel"
+	"github.com/fosrl/windows/ui/preferences"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	"github.com/tailscale/win"
+)
+
+// reloadConfiguration re-reads pangolin.json (and machine-wide config and
+// policy), applies the settings that take effect immediately and, when
+// connected, offers to reconnect so tunnel settings such as DNS apply too.
+// Must be called on the UI thread.
+func reloadConfiguration() {
+	if configManager == nil {
+		return
+	}
+	configManager.Load()
+	logger.Info("Configuration reloaded")
+
+	level := configManager.GetLogLevel()
+	config.ApplyLogLevel(level)
+	if managers.IPCClientReady() {
+		go func() {
+			if err := managers.IPCClientSetLogLevel(level); err != nil {
+				logger.Error("Failed to set manager log level: %v", err)
+			}
+		}()
+	}
+	applyStatusWindowSetting()
+	applyNetworkSettings()
+	preferences.ReloadAccountSettings()
+
+	if tunnelManager == nil || tunnelManager.State() != tunnel.StateRunning {
+		if trayIcon != nil {
+			trayIcon.ShowInfo("Configuration Reloaded", "Your settings will be used the next time you connect.")
+		}
+		return
+	}
+
+	td := walk.NewTaskDialog()
+	opts := walk.TaskDialogOpts{
+		Owner:         mainWindow,
+		Title:         "Configuration Reloaded",
+		Instruction:   "Reconnect to apply the reloaded settings?",
+		Content:       "Tunnel settings such as DNS and routes only take effect when the tunnel connects. Reconnecting briefly interrupts your connections.",
+		IconSystem:    walk.TaskDialogSystemIconInformation,
+		CommonButtons: win.TDCBF_YES_BUTTON | win.TDCBF_NO_BUTTON,
+		DefaultButton: walk.TaskDialogDefaultButtonYes,
+	}
+	reconnect := false
+	opts.CommonButtonClicked(win.TDCBF_YES_BUTTON).Attach(func() bool {
+		reconnect = true
+		return false
+	})
+	_, _ = td.Show(opts)
+	if !reconnect {
+		return
+	}
+
+	go func() {
+		if err := tunnelManager.Reconnect(); err != nil {
+			logger.Error("Failed to reconnect after reloading configuration: %v", err)
+			showConnectionErrorDialog(err, "Reconnect Failed")
+		}
+	}()
+}
@@ -0,0 +1,96 @@
+//go:build windows
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fosrl/windows/fingerprint"
+	"github.com/fosrl/windows/managers"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	"github.com/tailscale/win"
+)
+
+// formatFingerprint renders the fingerprint as "name: value" lines. When the
+// manager reports a different registration fingerprint it is listed too, since
+// a mismatch explains unexpected device re-registration.
+func formatFingerprint(fp *fingerprint.Fingerprint, managerFP string) string {
+	var b strings.Builder
+	field := func(name, value string) {
+		if value == "" {
+			value = "(unknown)"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, value)
+	}
+	field("Platform fingerprint", fp.PlatformFingerprint)
+	if managerFP != "" && managerFP != fp.PlatformFingerprint {
+		field("Registered fingerprint (manager)", managerFP)
+	}
+	field("Hostname", fp.Hostname)
+	field("Username", fp.Username)
+	field("Platform", fp.Platform)
+	field("OS version", fp.OSVersion)
+	field("Kernel version", fp.KernelVersion)
+	field("Architecture", fp.Architecture)
+	field("Device model", fp.DeviceModel)
+	field("Serial number", fp.SerialNumber)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// showDeviceFingerprint gathers the device fingerprint and shows it with an
+// option to copy it to the clipboard. Gathering runs PowerShell, so this must
+// be called from a background goroutine.
+func showDeviceFingerprint() {
+	fp := fingerprint.GatherFingerprintInfo()
+
+	// The manager computes the fingerprint used for registration
+	var managerFP string
+	if snapshot, err := managers.IPCClientGetDevicePosture(); err == nil {
+		managerFP, _ = snapshot.PlatformFingerprint()
+	} else {
+		logger.Debug("Could not get platform fingerprint from manager: %v", err)
+	}
+
+	walk.App().Synchronize(func() {
+		redact := true
+		text := func() string {
+			if redact {
+				return formatFingerprint(fp.Redacted(), managerFP)
+			}
+			return formatFingerprint(fp, managerFP)
+		}
+
+		td := walk.NewTaskDialog()
+		opts := walk.TaskDialogOpts{
+			Owner:            mainWindow,
+			Title:            "Device Fingerprint",
+			Content:          text(),
+			IconSystem:       walk.TaskDialogSystemIconInformation,
+			CommonButtons:    win.TDCBF_CLOSE_BUTTON,
+			VerificationText: "Redact serial number",
+			InitiallyChecked: redact,
+			CustomButtons: []walk.TaskDialogCustomButton{
+				{MainText: "Copy to Clipboard", Default: true},
+			},
+		}
+		td.VerificationClicked().Attach(func(checked bool) bool {
+			redact = checked
+			td.SetContent(text())
+			return true
+		})
+		opts.CustomButtons[0].Clicked().Attach(func() bool {
+			if err := walk.Clipboard().SetText(text()); err != nil {
+				logger.Error("Failed to copy device fingerprint: %v", err)
+				return true
+			}
+			if trayIcon != nil {
+				trayIcon.ShowInfo("Device Fingerprint Copied", "The device fingerprint has been copied to the clipboard.")
+			}
+			return false
+		})
+		_, _ = td.Show(opts)
+	})
+}
@@ -3,6 +3,7 @@
 package ui
 
 import (
+	"hash/fnv"
 	"path/filepath"
 
 	"github.com/fosrl/newt/logger"
@@ -145,3 +146,55 @@ func loadSystemIcon(dll string, index int32, size int) (icon *walk.Icon, err err
 	}
 	return
 }
+
+type initialsAndWidth struct {
+	key      string
+	initials string
+	width    int
+}
+
+var cachedInitialsIcons = make(map[initialsAndWidth]walk.Image)
+
+// initialsIconColors are the circle colors for initials icons. White text is
+// legible on all of them.
+var initialsIconColors = []walk.Color{
+	walk.RGB(0xE0, 0x6C, 0x1F), // Pangolin orange
+	walk.RGB(0x2B, 0x7A, 0xC8),
+	walk.RGB(0x2E, 0x8B, 0x57),
+	walk.RGB(0x8E, 0x44, 0xAD),
+	walk.RGB(0xC0, 0x39, 0x2B),
+	walk.RGB(0x16, 0x8A, 0x8A),
+	walk.RGB(0x6D, 0x6D, 0x6D),
+}
+
+// initialsIcon draws a colored circle with the given initials. The color is
+// picked from key (e.g. the user ID) so an account keeps the same color.
+func initialsIcon(key, initials string, size int) (walk.Image, error) {
+	cacheKey := initialsAndWidth{key, initials, size}
+	if icon := cachedInitialsIcons[cacheKey]; icon != nil {
+		return icon, nil
+	}
+
+	font, err := walk.NewFont("Segoe UI", size*3/8, walk.FontBold)
+	if err != nil {
+		return nil, err
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	color := initialsIconColors[h.Sum32()%uint32(len(initialsIconColors))]
+
+	icon := walk.NewPaintFuncImage(walk.Size{Width: size, Height: size}, func(canvas *walk.Canvas, bounds walk.Rectangle) error {
+		brush, err := walk.NewSolidColorBrush(color)
+		if err != nil {
+			return err
+		}
+		defer brush.Dispose()
+		if err := canvas.FillEllipse(brush, bounds); err != nil {
+			return err
+		}
+		return canvas.DrawText(initials, font, walk.RGB(0xFF, 0xFF, 0xFF), bounds, walk.TextCenter|walk.TextVCenter|walk.TextSingleLine)
+	})
+	cachedInitialsIcons[cacheKey] = icon
+	return icon, nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package ui
+
+import (
+	"github.com/skip2/go-qrcode"
+	"github.com/tailscale/walk"
+)
+
+// loginQRCodeSize is the size of the device login QR code at 96 DPI
+const loginQRCodeSize = 120
+
+// newLoginQRCode renders content as a QR code bitmap for a window at dpi. The
+// code is always black on white, with its quiet zone, so phone cameras read
+// it in dark mode too.
+func newLoginQRCode(content string, dpi int) (*walk.Bitmap, error) {
+	q, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	return walk.NewBitmapFromImageForDPI(q.Image(walk.IntFrom96DPI(loginQRCodeSize, dpi)), dpi)
+}
@@ -12,13 +12,14 @@ import (
 	"strings"
 	"sync"
 	"time"
-	"unsafe"
 
 	"github.com/fosrl/windows/api"
 	"github.com/fosrl/windows/auth"
 	"github.com/fosrl/windows/config"
 	"github.com/fosrl/windows/managers"
+	"github.com/fosrl/windows/secrets"
 	"github.com/fosrl/windows/tunnel"
+	"github.com/fosrl/windows/ui/theme"
 
 	"github.com/fosrl/newt/logger"
 	browser "github.com/pkg/browser"
@@ -45,25 +46,43 @@ const (
 	stateSuccess
 )
 
+const (
+	loginDialogWidth  = 450
+	loginDialogHeight = 330
+	// loginDialogCodeHeight leaves room for the QR code next to the device code
+	loginDialogCodeHeight = 470
+)
+
 var (
 	openLoginDialog      *walk.Dialog
 	openLoginDialogMutex sync.Mutex
 )
 
-// normalizeURL ensures the URL has a protocol prefix, defaulting to https:// if none is provided
-func normalizeURL(url string) string {
-	url = strings.TrimSpace(url)
-	if url == "" {
-		return url
+// normalizeURL ensures the URL has a protocol prefix, defaulting to https:// if none is provided,
+// and reduces it to scheme, host and port
+func normalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return rawURL
 	}
 	// Remove trailing slashes
-	url = strings.TrimRight(url, "/")
+	rawURL = strings.TrimRight(rawURL, "/")
 	// Check if URL already has a protocol
-	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
 		// Add https:// prefix if no protocol is present
-		url = "https://" + url
+		rawURL = "https://" + rawURL
+	}
+
+	// Keep only scheme, host and port; a pasted path (e.g. /auth/login) would
+	// otherwise end up in every API URL
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
 	}
-	return url
+	if parsed.User != nil || strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		logger.Warn("Ignoring path, query or credentials in server URL, using %s://%s", parsed.Scheme, parsed.Host)
+	}
+	return parsed.Scheme + "://" + parsed.Host
 }
 
 // appendAuthPathToURL appends the authPath query param to baseURL when authPath is non-empty
@@ -99,30 +118,6 @@ func getIconsPath() string {
 	return config.GetIconsPath()
 }
 
-// isDarkMode detects if Windows is in dark mode
-func isDarkMode() bool {
-	var key windows.Handle
-	keyPath := windows.StringToUTF16Ptr(`Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`)
-	err := windows.RegOpenKeyEx(windows.HKEY_CURRENT_USER, keyPath, 0, windows.KEY_READ, &key)
-	if err != nil {
-		// Default to light mode if we can't detect
-		return false
-	}
-	defer windows.RegCloseKey(key)
-
-	var value uint32
-	var valueLen uint32 = 4
-	valueName := windows.StringToUTF16Ptr("AppsUseLightTheme")
-	err = windows.RegQueryValueEx(key, valueName, nil, nil, (*byte)(unsafe.Pointer(&value)), &valueLen)
-	if err != nil {
-		// Default to light mode if we can't read the value
-		return false
-	}
-
-	// AppsUseLightTheme: 0 = dark mode, 1 = light mode
-	return value == 0
-}
-
 // ShowLoginDialog shows the login dialog with full authentication flow
 func ShowLoginDialog(
 	parent walk.Form,
@@ -167,6 +162,7 @@ func ShowLoginDialog(
 	hasAutoOpenedBrowser := false
 	loginSucceeded := false
 	includeUsernameInDeviceURL := false // true only when entering from re-auth (start device auth immediately)
+	droppedTopmost := false             // true while topmost is lifted so the auth browser can come forward
 	// Initialize temporary hostname from config (will be used for login flow, only persisted after successful login)
 	temporaryHostname := config.DefaultHostname
 	if activeAccount != nil {
@@ -186,6 +182,9 @@ func ShowLoginDialog(
 	var manualURLLabel *walk.Label
 	var manualURLComposite *walk.Composite
 	var progressBar *walk.ProgressBar
+	var qrImageView *walk.ImageView
+	var qrBitmap *walk.Bitmap
+	qrContent := "" // what qrBitmap encodes
 	var backButton, cancelButton, loginButton *walk.PushButton
 	var logoContainer *walk.Composite
 	var termsLabel, andLabel *walk.Label
@@ -266,17 +265,62 @@ func ShowLoginDialog(
 			if progressBar != nil {
 				progressBar.SetVisible(showDeviceAuthCode)
 			}
+			if qrImageView != nil {
+				qrImageView.SetVisible(showDeviceAuthCode && qrBitmap != nil)
+			}
+
+			// Grow the dialog while the code is shown to make room for the QR code
+			if dlg != nil {
+				size := walk.Size{Width: loginDialogWidth, Height: loginDialogHeight}
+				if showDeviceAuthCode {
+					size.Height = loginDialogCodeHeight
+				}
+				if dlg.Size() != size {
+					dlg.SetMinMaxSize(size, size)
+					dlg.SetSize(size)
+				}
+			}
 
 			// Show terms notice only on hosting selection page
 			if termsComposite != nil {
 				termsComposite.SetVisible(showHostingSelection)
 			}
 
+			// Enter triggers the primary button for the current state, and focus
+			// starts on the first control the user needs
+			if dlg != nil {
+				switch currentState {
+				case stateHostingSelection:
+					dlg.SetDefaultButton(nil)
+					if cloudButton != nil {
+						cloudButton.SetFocus()
+					}
+				case stateReadyToLogin:
+					dlg.SetDefaultButton(loginButton)
+					if urlLineEdit != nil {
+						urlLineEdit.SetFocus()
+					}
+				case stateDeviceAuthCode:
+					dlg.SetDefaultButton(openBrowserButton)
+				}
+			}
+
 			// Update buttons
 			updateButtons()
 		})
 	}
 
+	// releaseTopmost lifts the dialog out of the topmost band before opening
+	// the browser, so the page the user needs to interact with isn't covered.
+	// The flag is restored when the user comes back to the dialog.
+	releaseTopmost := func() {
+		if dlg == nil || win.GetWindowLong(dlg.Handle(), win.GWL_EXSTYLE)&win.WS_EX_TOPMOST == 0 {
+			return
+		}
+		win.SetWindowPos(dlg.Handle(), win.HWND_NOTOPMOST, 0, 0, 0, 0, win.SWP_NOMOVE|win.SWP_NOSIZE|win.SWP_NOACTIVATE)
+		droppedTopmost = true
+	}
+
 	updateCodeDisplay := func() {
 		walk.App().Synchronize(func() {
 			code := authManager.DeviceAuthCode()
@@ -304,10 +348,33 @@ func ShowLoginDialog(
 						if configManager != nil {
 							autoOpenURL = appendAuthPathToURL(autoOpenURL, configManager.GetAuthPath())
 						}
+						releaseTopmost()
 						openBrowser(autoOpenURL)
 					}
 				}
 			}
+			// QR code of the login page, so the code can be entered on a phone
+			loginURL := authManager.DeviceAuthLoginURL()
+			if code != nil && loginURL != nil && qrImageView != nil {
+				content := fmt.Sprintf("%s?code=%s", *loginURL, strings.ReplaceAll(*code, "-", ""))
+				if configManager != nil {
+					content = appendAuthPathToURL(content, configManager.GetAuthPath())
+				}
+				if content != qrContent {
+					bitmap, err := newLoginQRCode(content, dlg.DPI())
+					if err != nil {
+						logger.Error("Failed to create login QR code: %v", err)
+					} else {
+						qrImageView.SetImage(bitmap)
+						if qrBitmap != nil {
+							qrBitmap.Dispose()
+						}
+						qrBitmap = bitmap
+						qrContent = content
+						qrImageView.SetVisible(currentState == stateDeviceAuthCode)
+					}
+				}
+			}
 			// Update manual URL label
 			if temporaryHostname != "" && manualURLLabel != nil {
 				manualURL := fmt.Sprintf("%s/auth/login/device", temporaryHostname)
@@ -346,6 +413,17 @@ func ShowLoginDialog(
 
 		// Pass temporary hostname to login (it will use a temporary API client internally)
 		err := authManager.LoginWithDeviceAuth(loginCtx, &temporaryHostname)
+		for errors.Is(err, auth.ErrSessionTokenNotSaved) {
+			choice := promptSessionSaveRetry(dlg, err)
+			if choice == sessionSaveCancel {
+				break
+			}
+			if choice == sessionSaveContinue {
+				err = authManager.ContinuePendingAuth()
+				break
+			}
+			err = authManager.RetryPendingAuth()
+		}
 		if err != nil {
 			// Don't show error dialog if context was canceled (user closed dialog)
 			if errors.Is(err, context.Canceled) {
@@ -357,15 +435,18 @@ func ShowLoginDialog(
 			}
 			walk.App().Synchronize(func() {
 				isLoggingIn = false
-				errorMsg := err.Error()
-				td := walk.NewTaskDialog()
-				td.Show(walk.TaskDialogOpts{
-					Owner:         dlg,
-					Title:         "Login Error",
-					Content:       errorMsg,
-					IconSystem:    walk.TaskDialogSystemIconError,
-					CommonButtons: win.TDCBF_OK_BUTTON,
-				})
+				// The save failure was already explained by promptSessionSaveRetry
+				if !errors.Is(err, auth.ErrSessionTokenNotSaved) {
+					errorMsg := err.Error()
+					td := walk.NewTaskDialog()
+					td.Show(walk.TaskDialogOpts{
+						Owner:         dlg,
+						Title:         "Login Error",
+						Content:       errorMsg,
+						IconSystem:    walk.TaskDialogSystemIconError,
+						CommonButtons: win.TDCBF_OK_BUTTON,
+					})
+				}
 				hasAutoOpenedBrowser = false
 				includeUsernameInDeviceURL = false
 				if hostingOpt == hostingCloud {
@@ -401,11 +482,12 @@ func ShowLoginDialog(
 	}
 
 	Dialog{
-		AssignTo: &dlg,
-		Title:    "Login to Pangolin",
-		MinSize:  Size{Width: 450, Height: 330},
-		MaxSize:  Size{Width: 450, Height: 330},
-		Layout:   VBox{Margins: Margins{Left: 20, Top: 10, Right: 20, Bottom: 10}, Spacing: 5},
+		AssignTo:     &dlg,
+		CancelButton: &cancelButton, // Escape closes the dialog
+		Title:        "Login to Pangolin",
+		MinSize:      Size{Width: loginDialogWidth, Height: loginDialogHeight},
+		MaxSize:      Size{Width: loginDialogWidth, Height: loginDialogHeight},
+		Layout:       VBox{Margins: Margins{Left: 20, Top: 10, Right: 20, Bottom: 10}, Spacing: 5},
 		Children: []Widget{
 			// Logo container at top
 			Composite{
@@ -507,12 +589,21 @@ func ShowLoginDialog(
 										if configManager != nil {
 											u = appendAuthPathToURL(u, configManager.GetAuthPath())
 										}
+										releaseTopmost()
 										openBrowser(u)
 									}
 								},
 							},
 						},
 					},
+					ImageView{
+						AssignTo:    &qrImageView,
+						Mode:        ImageViewModeCenter,
+						MinSize:     Size{Width: loginQRCodeSize, Height: loginQRCodeSize},
+						MaxSize:     Size{Width: loginQRCodeSize, Height: loginQRCodeSize},
+						ToolTipText: "Scan to log in on your phone",
+						Visible:     false,
+					},
 					Composite{
 						AssignTo: &manualURLComposite,
 						Layout:   VBox{Margins: Margins{Top: 10}, MarginsZero: true},
@@ -613,6 +704,10 @@ func ShowLoginDialog(
 						MaxSize:  Size{Width: 75, Height: 0},
 						Visible:  false,
 						OnClicked: func() {
+							// Also reached via Enter, so ignore it unless the button is usable
+							if currentState != stateReadyToLogin || isLoggingIn || !isReadyToLogin() {
+								return
+							}
 							currentState = stateDeviceAuthCode
 							isLoggingIn = true
 							updateUI()
@@ -639,7 +734,15 @@ func ShowLoginDialog(
 	win.SetWindowLong(dlg.Handle(), GWL_EXSTYLE, exStyle)
 
 	// Set fixed size
-	dlg.SetSize(walk.Size{Width: 450, Height: 330})
+	dlg.SetSize(walk.Size{Width: loginDialogWidth, Height: loginDialogHeight})
+
+	// Restore topmost once the user returns from the browser
+	dlg.Activating().Attach(func() {
+		if droppedTopmost {
+			droppedTopmost = false
+			win.SetWindowPos(dlg.Handle(), win.HWND_TOPMOST, 0, 0, 0, 0, win.SWP_NOMOVE|win.SWP_NOSIZE|win.SWP_NOACTIVATE)
+		}
+	})
 
 	// Set window icon
 	iconsPath := getIconsPath()
@@ -653,8 +756,12 @@ func ShowLoginDialog(
 		}
 	}
 
-	// Set background color (always light mode)
-	bgBrush, _ := walk.NewSolidColorBrush(walk.RGB(0xFC, 0xFC, 0xFC)) // #FCFCFC
+	// Match the Windows app theme, read each time the dialog opens
+	palette := theme.Current()
+	theme.ApplyTitleBar(dlg.Handle(), palette)
+
+	// Set background color
+	bgBrush, _ := walk.NewSolidColorBrush(palette.Background)
 	if bgBrush != nil {
 		dlg.SetBackground(bgBrush)
 		if contentComposite != nil {
@@ -673,9 +780,9 @@ func ShowLoginDialog(
 
 	// Load and display word mark logo
 	if logoContainer != nil {
-		// Always use black word mark (light mode)
+		// Word mark that is legible on the current background
 		iconsPath := getIconsPath()
-		imagePath := filepath.Join(iconsPath, "word_mark_black.png")
+		imagePath := filepath.Join(iconsPath, palette.WordMarkFile())
 
 		// Create ImageView widget
 		logoImageView, err := walk.NewImageView(logoContainer)
@@ -692,6 +799,9 @@ func ShowLoginDialog(
 		}
 	}
 
+	// Light label text for dark mode
+	theme.ApplyDark(dlg, palette)
+
 	// Initial UI update
 	updateUI()
 
@@ -706,6 +816,10 @@ func ShowLoginDialog(
 		cancelLogin()
 		cancelPoll()
 
+		if qrBitmap != nil {
+			qrBitmap.Dispose()
+		}
+
 		// Clear device auth state and re-auth flag if login didn't succeed
 		if !loginSucceeded {
 			authManager.ClearDeviceAuth()
@@ -782,6 +896,26 @@ func ShowLoginDialog(
 				go performLogin()
 				return
 			}
+			// Continue a login that was closed moments ago with the same code;
+			// the browser page for it was already opened
+			if hostname := authManager.ResumableDeviceAuthHostname(); hostname != "" {
+				temporaryHostname = hostname
+				if hostname == config.DefaultHostname {
+					hostingOpt = hostingCloud
+				} else {
+					hostingOpt = hostingSelfHosted
+					selfHostedURL = hostname
+					if urlLineEdit != nil {
+						urlLineEdit.SetText(hostname)
+					}
+				}
+				hasAutoOpenedBrowser = true
+				currentState = stateDeviceAuthCode
+				isLoggingIn = true
+				updateUI()
+				go performLogin()
+				return
+			}
 			if configManager != nil {
 				defaultURL := configManager.GetDefaultServerURL()
 				normalizedURL := normalizeURL(defaultURL)
@@ -799,6 +933,15 @@ func ShowLoginDialog(
 	}()
 
 	dlg.Run()
+
+	if loginSucceeded {
+		loggedOutMutex.Lock()
+		isLoggedOut = false
+		loggedOutMutex.Unlock()
+	}
+	if loginSucceeded && authManager.HasNoOrganizations() {
+		showNoOrganizationsDialog()
+	}
 }
 
 // openBrowser opens a URL in the default browser
@@ -850,3 +993,53 @@ func copyToClipboard(text string) {
 		return
 	}
 }
+
+// sessionSaveChoice is the user's answer to promptSessionSaveRetry
+type sessionSaveChoice int
+
+const (
+	sessionSaveCancel sessionSaveChoice = iota
+	sessionSaveRetry
+	sessionSaveContinue
+)
+
+// promptSessionSaveRetry explains that the session token could not be stored
+// and asks whether to try again or sign in for this session only. Called from
+// a background goroutine; blocks until the user answers.
+func promptSessionSaveRetry(owner walk.Form, err error) sessionSaveChoice {
+	content := "You signed in successfully, but Pangolin could not store your session securely, so you would be signed out again on restart.\n\n" +
+		fmt.Sprintf("Details: %v", err)
+	if secrets.IsLogonSessionError(err) {
+		content = "You signed in successfully, but Windows could not protect your session because there is no interactive logon session for your account. " +
+			"This can happen when Pangolin is started before you sign in to Windows, from a remote or scheduled session, or while your credentials are locked.\n\n" +
+			"Sign in to or unlock Windows interactively, then choose Retry."
+	}
+
+	answer := make(chan sessionSaveChoice, 1)
+	walk.App().Synchronize(func() {
+		choice := sessionSaveCancel
+		td := walk.NewTaskDialog()
+		opts := walk.TaskDialogOpts{
+			Owner:         owner,
+			Title:         "Could Not Save Session",
+			Content:       content,
+			IconSystem:    walk.TaskDialogSystemIconWarning,
+			CommonButtons: win.TDCBF_CANCEL_BUTTON,
+			CustomButtons: []walk.TaskDialogCustomButton{
+				{MainText: "Retry", Default: true},
+				{MainText: "Continue Without Saving"},
+			},
+		}
+		opts.CustomButtons[0].Clicked().Attach(func() bool {
+			choice = sessionSaveRetry
+			return false
+		})
+		opts.CustomButtons[1].Clicked().Attach(func() bool {
+			choice = sessionSaveContinue
+			return false
+		})
+		_, _ = td.Show(opts)
+		answer <- choice
+	})
+	return <-answer
+}
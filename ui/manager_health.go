@@ -0,0 +1,97 @@
+//go:build windows
+
+package ui
+
+import (
+	"os"
+	"time"
+
+	"github.com/fosrl/windows/elevate"
+	"github.com/fosrl/windows/managers"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	managerHealthInterval = 30 * time.Second
+	managerPingTimeout    = 5 * time.Second
+)
+
+var (
+	managerDownAction    *walk.Action
+	restartManagerAction *walk.Action
+)
+
+// addManagerHealthActions adds the (initially hidden) manager warning and restart actions to the menu
+func addManagerHealthActions(actions *walk.ActionList) {
+	managerDownAction = walk.NewAction()
+	managerDownAction.SetText("Manager service not responding")
+	managerDownAction.SetEnabled(false)
+	managerDownAction.SetVisible(false)
+	actions.Add(managerDownAction)
+
+	restartManagerAction = walk.NewAction()
+	restartManagerAction.SetText("Restart Service")
+	restartManagerAction.SetVisible(false)
+	restartManagerAction.Triggered().Attach(func() {
+		go restartManagerService()
+	})
+	actions.Add(restartManagerAction)
+}
+
+// monitorManagerHealth periodically pings the manager service and shows the
+// warning and restart actions while it does not respond
+func monitorManagerHealth() {
+	responding := true
+	for {
+		time.Sleep(managerHealthInterval)
+
+		err := managers.IPCClientPing(managerPingTimeout)
+		if (err == nil) == responding {
+			continue
+		}
+		responding = err == nil
+		if responding {
+			logger.Info("Manager service is responding again")
+		} else {
+			logger.Error("Manager service is not responding: %v", err)
+		}
+
+		walk.App().Synchronize(func() {
+			if managerDownAction != nil {
+				managerDownAction.SetVisible(!responding)
+			}
+			if restartManagerAction != nil {
+				restartManagerAction.SetVisible(!responding)
+			}
+		})
+	}
+}
+
+// restartManagerService re-runs the elevated manager service installer, which
+// starts the service and launches a new UI, then exits this UI
+func restartManagerService() {
+	path, err := os.Executable()
+	if err != nil {
+		logger.Error("Failed to locate executable to restart manager service: %v", err)
+		return
+	}
+
+	err = elevate.ShellExecute(path, "/installmanagerservice", "", windows.SW_SHOW)
+	if err == windows.ERROR_CANCELLED {
+		logger.Info("User cancelled elevation, manager service not restarted")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to restart manager service: %v", err)
+		showConnectionErrorDialog(err, "Restart Failed")
+		return
+	}
+
+	logger.Info("Manager service restart requested, exiting UI")
+	walk.App().Synchronize(func() {
+		walk.App().Exit(0)
+	})
+}
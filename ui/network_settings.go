@@ -0,0 +1,20 @@
+//go:build windows
+
+package ui
+
+import (
+	"github.com/fosrl/windows/api"
+	"github.com/fosrl/windows/config"
+)
+
+// applyNetworkSettings points the API client at the proxy, TLS and timeout
+// settings currently configured, so a changed setting applies without
+// restarting
+func applyNetworkSettings() {
+	if apiClient == nil || configManager == nil || secretManager == nil {
+		return
+	}
+	apiClient.SetProxy(secretManager.ProxyURL(configManager))
+	apiClient.SetTLS(configManager.GetRootCAs(), configManager.GetTLSSkipVerify())
+	apiClient.SetTimeout(config.ScaleForNetwork(api.DefaultTimeout, configManager.GetSlowNetwork()))
+}
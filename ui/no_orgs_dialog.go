@@ -0,0 +1,72 @@
+//go:build windows
+
+package ui
+
+import (
+	"github.com/fosrl/windows/config"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	"github.com/tailscale/win"
+)
+
+// dashboardURL returns the dashboard of the active account's server
+func dashboardURL() string {
+	if accountManager != nil {
+		if active, _ := accountManager.ActiveAccount(); active != nil && active.Hostname != "" {
+			return active.Hostname
+		}
+	}
+	return config.DefaultHostname
+}
+
+// showNoOrganizationsDialog tells a user who is not a member of any
+// organization to create or join one in the dashboard, and offers to check
+// again afterwards
+func showNoOrganizationsDialog() {
+	walk.App().Synchronize(func() {
+		recheck := false
+		td := walk.NewTaskDialog()
+		opts := walk.TaskDialogOpts{
+			Owner:         mainWindow,
+			Title:         "No Organizations",
+			Instruction:   "Your account isn't a member of any organization yet",
+			Content:       "To connect, create an organization or accept an invitation to one in the Pangolin dashboard at " + dashboardURL() + ", then choose Check Again.",
+			IconSystem:    walk.TaskDialogSystemIconInformation,
+			CommonButtons: win.TDCBF_CLOSE_BUTTON,
+			CustomButtons: []walk.TaskDialogCustomButton{
+				{MainText: "Open Dashboard", Default: true},
+				{MainText: "Check Again"},
+			},
+		}
+		opts.CustomButtons[0].Clicked().Attach(func() bool {
+			openURL(dashboardURL())
+			return true // keep the dialog open so the user can check again
+		})
+		opts.CustomButtons[1].Clicked().Attach(func() bool {
+			recheck = true
+			return false
+		})
+		_, _ = td.Show(opts)
+
+		if !recheck {
+			return
+		}
+
+		go func() {
+			if err := authManager.RefreshOrganizations(); err != nil {
+				logger.Error("Failed to check organizations again: %v", err)
+			}
+			orgs := authManager.Organizations()
+			if len(orgs) == 0 {
+				showNoOrganizationsDialog()
+				return
+			}
+			if authManager.CurrentOrg() == nil {
+				selectOrganization(orgs[0])
+				return
+			}
+			walk.App().Synchronize(updateMenu)
+		}()
+	})
+}
@@ -0,0 +1,85 @@
+//go:build windows
+
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fosrl/windows/auth"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	"github.com/tailscale/win"
+)
+
+// showOrgAccessDeniedDialog shows an org policy denial with a "Resolve now" button that
+// opens the resolution URL and a "Recheck" button that re-runs CheckOrgAccess.
+// onAllowed is called from a background goroutine once a recheck grants access.
+func showOrgAccessDeniedDialog(deniedErr *auth.OrgAccessDeniedError, onAllowed func()) {
+	walk.App().Synchronize(func() {
+		reason := "Access denied due to organization policy violations."
+		if deniedErr.Reason != "" {
+			reason = fmt.Sprintf("Access denied: %s", deniedErr.Reason)
+		}
+
+		recheck := false
+		td := walk.NewTaskDialog()
+		opts := walk.TaskDialogOpts{
+			Owner:         mainWindow,
+			Title:         "Organization Access Denied",
+			Content:       reason + "\n\nResolve the issues in your browser, then choose Recheck.",
+			IconSystem:    walk.TaskDialogSystemIconWarning,
+			CommonButtons: win.TDCBF_CLOSE_BUTTON,
+			CustomButtons: []walk.TaskDialogCustomButton{
+				{MainText: "Resolve now", Default: true},
+				{MainText: "Recheck"},
+			},
+		}
+		opts.CustomButtons[0].Clicked().Attach(func() bool {
+			openURL(deniedErr.ResolutionURL)
+			return true // keep the dialog open so the user can recheck
+		})
+		opts.CustomButtons[1].Clicked().Attach(func() bool {
+			recheck = true
+			return false
+		})
+		_, _ = td.Show(opts)
+
+		if !recheck {
+			return
+		}
+
+		go func() {
+			hasAccess, err := authManager.CheckOrgAccess(deniedErr.OrgID)
+			if hasAccess {
+				logger.Info("Organization access granted after recheck")
+				if onAllowed != nil {
+					onAllowed()
+				}
+				return
+			}
+
+			var stillDenied *auth.OrgAccessDeniedError
+			if errors.As(err, &stillDenied) {
+				showOrgAccessDeniedDialog(stillDenied, onAllowed)
+				return
+			}
+
+			if err == nil {
+				err = errors.New("access to this organization is still denied")
+			}
+			logger.Error("Organization access recheck failed: %v", err)
+			walk.App().Synchronize(func() {
+				td := walk.NewTaskDialog()
+				_, _ = td.Show(walk.TaskDialogOpts{
+					Owner:         mainWindow,
+					Title:         "Organization Access Denied",
+					Content:       fmt.Sprintf("Failed to verify organization access: %v", err),
+					IconSystem:    walk.TaskDialogSystemIconError,
+					CommonButtons: win.TDCBF_OK_BUTTON,
+				})
+			})
+		}()
+	})
+}
@@ -0,0 +1,98 @@
+//go:build windows
+
+package ui
+
+import (
+	"github.com/fosrl/windows/api"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+)
+
+var (
+	favoriteOrgsMenu       *walk.Menu
+	favoriteOrgsMenuAction *walk.Action
+	favoriteOrgActions     = make(map[string]*walk.Action)
+)
+
+// isFavoriteOrg returns whether the user starred the organization
+func isFavoriteOrg(orgID string) bool {
+	return configManager != nil && configManager.IsFavoriteOrg(orgID)
+}
+
+// orderOrgsForMenu returns orgs in menu order: favorites first, then, when
+// there are many orgs, recently selected ones, then the rest alphabetically
+func orderOrgsForMenu(orgs []api.Org) []api.Org {
+	ordered := sortOrgsByName(orgs)
+	if len(ordered) > orgSearchThreshold {
+		ordered = recentOrgsFirst(ordered)
+	}
+
+	favorites := make([]api.Org, 0, len(ordered))
+	rest := make([]api.Org, 0, len(ordered))
+	for _, org := range ordered {
+		if isFavoriteOrg(org.Id) {
+			favorites = append(favorites, org)
+		} else {
+			rest = append(rest, org)
+		}
+	}
+	return append(favorites, rest...)
+}
+
+// orgMenuText returns the org menu label, starred for favorites
+func orgMenuText(org api.Org) string {
+	if isFavoriteOrg(org.Id) {
+		return "★ " + org.Name
+	}
+	return org.Name
+}
+
+// updateFavoriteOrgsMenu keeps the "Favorite Organizations" submenu (at the end
+// of the org menu) in sync with orgs. Each item toggles the org's favorite state.
+func updateFavoriteOrgsMenu(orgs []api.Org) {
+	if favoriteOrgsMenu == nil {
+		menu, err := walk.NewMenu()
+		if err != nil {
+			logger.Error("Failed to create favorite orgs menu: %v", err)
+			return
+		}
+		favoriteOrgsMenu = menu
+		favoriteOrgsMenuAction = walk.NewMenuAction(favoriteOrgsMenu)
+		favoriteOrgsMenuAction.SetText("Favorite Organizations")
+		orgMenu.Actions().Add(favoriteOrgsMenuAction)
+	}
+
+	orgSet := make(map[string]bool)
+	for _, org := range orgs {
+		orgSet[org.Id] = true
+	}
+	for orgId, action := range favoriteOrgActions {
+		if !orgSet[orgId] {
+			favoriteOrgsMenu.Actions().Remove(action)
+			delete(favoriteOrgActions, orgId)
+		}
+	}
+
+	for _, org := range orgs {
+		action, exists := favoriteOrgActions[org.Id]
+		if !exists {
+			action = walk.NewAction()
+			action.SetCheckable(true)
+			action.Triggered().Attach(func() {
+				org := org
+				favorite := !isFavoriteOrg(org.Id)
+				if !configManager.SetFavoriteOrg(org.Id, favorite) {
+					logger.Error("Failed to save favorite organization %s", org.Id)
+				}
+				updateMenu()
+			})
+			favoriteOrgActions[org.Id] = action
+			favoriteOrgsMenu.Actions().Add(action)
+		}
+		action.SetText(org.Name)
+		action.SetChecked(isFavoriteOrg(org.Id))
+	}
+
+	favoriteOrgsMenuAction.SetVisible(len(orgs) > 0)
+}
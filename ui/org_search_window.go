@@ -0,0 +1,200 @@
+//go:build windows
+
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/fosrl/windows/api"
+	"github.com/fosrl/windows/ui/theme"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	. "github.com/tailscale/walk/declarative"
+)
+
+// orgSearchThreshold is the number of organizations above which the org menu
+// offers a search dialog and lists recent organizations first
+const orgSearchThreshold = 10
+
+// recentOrgsFirst returns orgs with the active account's recently selected
+// organizations moved to the front, most recent first. The remaining orgs keep
+// their order.
+func recentOrgsFirst(orgs []api.Org) []api.Org {
+	if accountManager == nil {
+		return orgs
+	}
+	account, err := accountManager.ActiveAccount()
+	if err != nil || len(account.RecentOrgIDs) == 0 {
+		return orgs
+	}
+
+	byID := make(map[string]api.Org, len(orgs))
+	for _, org := range orgs {
+		byID[org.Id] = org
+	}
+
+	ordered := make([]api.Org, 0, len(orgs))
+	recent := make(map[string]bool)
+	for _, id := range account.RecentOrgIDs {
+		if org, ok := byID[id]; ok && !recent[id] {
+			ordered = append(ordered, org)
+			recent[id] = true
+		}
+	}
+	for _, org := range orgs {
+		if !recent[org.Id] {
+			ordered = append(ordered, org)
+		}
+	}
+	return ordered
+}
+
+// filterOrgs returns the orgs whose name or ID contains query, ignoring case
+func filterOrgs(orgs []api.Org, query string) []api.Org {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return orgs
+	}
+
+	var matches []api.Org
+	for _, org := range orgs {
+		if strings.Contains(strings.ToLower(org.Name), query) || strings.Contains(strings.ToLower(org.Id), query) {
+			matches = append(matches, org)
+		}
+	}
+	return matches
+}
+
+// showOrgSearchDialog lets the user type to narrow the organization list and
+// select one. Must be called on the UI thread.
+func showOrgSearchDialog() {
+	if authManager == nil {
+		return
+	}
+
+	orgs := recentOrgsFirst(sortOrgsByName(authManager.Organizations()))
+	currentOrgID := ""
+	if currentOrg := authManager.CurrentOrg(); currentOrg != nil {
+		currentOrgID = currentOrg.Id
+	}
+
+	var dlg *walk.Dialog
+	var searchEdit *walk.LineEdit
+	var orgList *walk.ListBox
+	var selectButton, cancelButton *walk.PushButton
+	filtered := orgs
+
+	names := func(orgs []api.Org) []string {
+		items := make([]string, len(orgs))
+		for i, org := range orgs {
+			items[i] = org.Name
+			if org.Id == currentOrgID {
+				items[i] += " (current)"
+			}
+		}
+		return items
+	}
+
+	applyFilter := func() {
+		filtered = filterOrgs(orgs, searchEdit.Text())
+		orgList.SetModel(names(filtered))
+		if len(filtered) > 0 {
+			orgList.SetCurrentIndex(0)
+		}
+		selectButton.SetEnabled(len(filtered) > 0)
+	}
+
+	err := Dialog{
+		AssignTo:      &dlg,
+		Title:         "Search Organizations",
+		DefaultButton: &selectButton,
+		CancelButton:  &cancelButton,
+		MinSize:       Size{Width: 400, Height: 420},
+		Layout:        VBox{Margins: Margins{Left: 12, Top: 12, Right: 12, Bottom: 12}, Spacing: 8},
+		Children: []Widget{
+			LineEdit{
+				AssignTo:  &searchEdit,
+				CueBanner: "Type to filter organizations",
+				OnTextChanged: func() {
+					applyFilter()
+				},
+				OnKeyDown: func(key walk.Key) {
+					// Let the arrow keys move through the list while typing
+					index := orgList.CurrentIndex()
+					switch key {
+					case walk.KeyDown:
+						if index < len(filtered)-1 {
+							orgList.SetCurrentIndex(index + 1)
+						}
+					case walk.KeyUp:
+						if index > 0 {
+							orgList.SetCurrentIndex(index - 1)
+						}
+					}
+				},
+			},
+			ListBox{
+				AssignTo: &orgList,
+				Model:    names(filtered),
+				OnItemActivated: func() {
+					dlg.Accept()
+				},
+			},
+			Composite{
+				Layout: HBox{MarginsZero: true, Spacing: 8},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						AssignTo: &cancelButton,
+						Text:     "Cancel",
+						MinSize:  Size{Width: 75, Height: 0},
+						OnClicked: func() {
+							dlg.Cancel()
+						},
+					},
+					PushButton{
+						AssignTo: &selectButton,
+						Text:     "Select",
+						MinSize:  Size{Width: 75, Height: 0},
+						OnClicked: func() {
+							dlg.Accept()
+						},
+					},
+				},
+			},
+		},
+	}.Create(mainWindow)
+	if err != nil {
+		logger.Error("Failed to create organization search dialog: %v", err)
+		return
+	}
+
+	if icon, err := walk.NewIconFromFile(filepath.Join(getIconsPath(), "icon-orange.ico")); err == nil {
+		dlg.SetIcon(icon)
+	}
+	palette := theme.Current()
+	theme.ApplyTitleBar(dlg.Handle(), palette)
+	theme.ApplyDark(dlg, palette)
+
+	if len(filtered) > 0 {
+		orgList.SetCurrentIndex(0)
+	}
+	selectButton.SetEnabled(len(filtered) > 0)
+	searchEdit.SetFocus()
+
+	if dlg.Run() != walk.DlgCmdOK {
+		return
+	}
+
+	index := orgList.CurrentIndex()
+	if index < 0 || index >= len(filtered) {
+		return
+	}
+	org := filtered[index]
+	if org.Id == currentOrgID {
+		return
+	}
+	go selectOrganization(org)
+}
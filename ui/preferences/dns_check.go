@@ -0,0 +1,141 @@
+//go:build windows
+
+package preferences
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/fosrl/windows/ui/theme"
+	"github.com/tailscale/walk"
+	. "github.com/tailscale/walk/declarative"
+	"github.com/tailscale/win"
+)
+
+// tunnelDNSHelp explains why tunneled DNS can fail, shown when a test fails
+const tunnelDNSHelp = "With DNS over tunnel, queries only reach the upstream DNS server if it is " +
+	"defined as a Pangolin private resource you can access, and its address is entered as an " +
+	"Upstream DNS Server. Without such a resource name resolution stops working while connected."
+
+// promptTunnelDNSName asks for the internal name to resolve. It returns false
+// if the user cancelled.
+func (pt *PreferencesTab) promptTunnelDNSName() (string, bool) {
+	var dlg *walk.Dialog
+	var nameEdit *walk.LineEdit
+	var testButton, cancelButton *walk.PushButton
+
+	name := ""
+	if domains := pt.configManager.GetMatchDomains(); len(domains) > 0 {
+		name = domains[0]
+	}
+
+	err := Dialog{
+		AssignTo:      &dlg,
+		Title:         "Test DNS Over Tunnel",
+		DefaultButton: &testButton,
+		CancelButton:  &cancelButton,
+		MinSize:       Size{Width: 380, Height: 0},
+		Layout:        VBox{Margins: Margins{Left: 12, Top: 12, Right: 12, Bottom: 12}, Spacing: 8},
+		Children: []Widget{
+			Label{
+				Text: "Enter an internal name to resolve through the tunnel.\nThe test uses your saved DNS settings and the running tunnel.",
+			},
+			LineEdit{
+				AssignTo:  &nameEdit,
+				Text:      name,
+				CueBanner: "e.g. server.internal.example.com",
+			},
+			Composite{
+				Layout: HBox{MarginsZero: true, Spacing: 8},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						AssignTo: &cancelButton,
+						Text:     "Cancel",
+						MinSize:  Size{Width: 75, Height: 0},
+						OnClicked: func() {
+							dlg.Cancel()
+						},
+					},
+					PushButton{
+						AssignTo: &testButton,
+						Text:     "Test",
+						MinSize:  Size{Width: 75, Height: 0},
+						OnClicked: func() {
+							dlg.Accept()
+						},
+					},
+				},
+			},
+		},
+	}.Create(pt.tabPage.Form())
+	if err != nil {
+		logger.Error("Failed to create DNS test dialog: %v", err)
+		return "", false
+	}
+	palette := theme.Current()
+	theme.ApplyTitleBar(dlg.Handle(), palette)
+	theme.ApplyDark(dlg, palette)
+
+	if dlg.Run() != walk.DlgCmdOK {
+		return "", false
+	}
+	name = strings.TrimSpace(nameEdit.Text())
+	return name, name != ""
+}
+
+// onTestTunnelDNS resolves an internal name through the tunnel's DNS proxy and
+// upstream DNS servers and warns, with the private resource requirement, if it fails
+func (pt *PreferencesTab) onTestTunnelDNS() {
+	if pt.window == nil || pt.window.tunnelManager == nil {
+		return
+	}
+	name, ok := pt.promptTunnelDNSName()
+	if !ok {
+		return
+	}
+
+	pt.dnsTunnelTestButton.SetEnabled(false)
+	pt.dnsTunnelTestButton.SetText("Testing…")
+
+	tm := pt.window.tunnelManager
+	go func() {
+		checks := tm.TestTunnelDNS(name)
+
+		passed := len(checks) > 0
+		var summary strings.Builder
+		for _, check := range checks {
+			result := "PASS"
+			if !check.Passed {
+				result = "FAIL"
+				passed = false
+			}
+			summary.WriteString(fmt.Sprintf("[%s] %s: %s\n", result, check.Name, check.Detail))
+			logger.Info("DNS over tunnel test: [%s] %s: %s", result, check.Name, check.Detail)
+		}
+
+		walk.App().Synchronize(func() {
+			pt.dnsTunnelTestButton.SetEnabled(pt.dnsTunnelCheckBox.Checked())
+			pt.dnsTunnelTestButton.SetText("Test…")
+
+			title := fmt.Sprintf("%s resolved through the tunnel", name)
+			icon := walk.TaskDialogSystemIconInformation
+			content := strings.TrimSpace(summary.String())
+			if !passed {
+				title = fmt.Sprintf("Could not resolve %s through the tunnel", name)
+				icon = walk.TaskDialogSystemIconWarning
+				content += "\n\n" + tunnelDNSHelp
+			}
+			td := walk.NewTaskDialog()
+			_, _ = td.Show(walk.TaskDialogOpts{
+				Owner:         pt.tabPage.Form(),
+				Title:         "DNS Over Tunnel Test",
+				Instruction:   title,
+				Content:       content,
+				IconSystem:    icon,
+				CommonButtons: win.TDCBF_OK_BUTTON,
+			})
+		})
+	}()
+}
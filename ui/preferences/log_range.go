@@ -0,0 +1,122 @@
+//go:build windows
+
+package preferences
+
+import (
+	"time"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/fosrl/windows/ui/theme"
+	"github.com/tailscale/walk"
+	. "github.com/tailscale/walk/declarative"
+)
+
+// logRangeFormat is the date and time format of the log range pickers
+const logRangeFormat = "yyyy-MM-dd HH:mm:ss"
+
+// promptLogRange asks which time range of the log to save. A zero from or to
+// leaves that end open. It returns false if the user cancelled.
+func (lt *LogsTab) promptLogRange() (from, to time.Time, ok bool) {
+	var dlg *walk.Dialog
+	var fromEdit, toEdit *walk.DateEdit
+	var nextButton, cancelButton *walk.PushButton
+
+	err := Dialog{
+		AssignTo:      &dlg,
+		Title:         "Save Log",
+		DefaultButton: &nextButton,
+		CancelButton:  &cancelButton,
+		MinSize:       Size{Width: 360, Height: 0},
+		Layout:        VBox{Margins: Margins{Left: 12, Top: 12, Right: 12, Bottom: 12}, Spacing: 8},
+		Children: []Widget{
+			Label{
+				Text: "To save only the lines around an incident, tick and set a start\nand/or end time. Leave both unticked to save the whole log.",
+			},
+			Composite{
+				Layout: Grid{Columns: 2, MarginsZero: true, Spacing: 8},
+				Children: []Widget{
+					Label{Text: "From:"},
+					DateEdit{
+						AssignTo: &fromEdit,
+						Format:   logRangeFormat,
+						Optional: true,
+					},
+					Label{Text: "To:"},
+					DateEdit{
+						AssignTo: &toEdit,
+						Format:   logRangeFormat,
+						Optional: true,
+					},
+				},
+			},
+			Composite{
+				Layout: HBox{MarginsZero: true, Spacing: 8},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						AssignTo: &cancelButton,
+						Text:     "Cancel",
+						MinSize:  Size{Width: 75, Height: 0},
+						OnClicked: func() {
+							dlg.Cancel()
+						},
+					},
+					PushButton{
+						AssignTo: &nextButton,
+						Text:     "Save…",
+						MinSize:  Size{Width: 75, Height: 0},
+						OnClicked: func() {
+							dlg.Accept()
+						},
+					},
+				},
+			},
+		},
+	}.Create(lt.window)
+	if err != nil {
+		logger.Error("Failed to create log range dialog: %v", err)
+		return time.Time{}, time.Time{}, false
+	}
+	palette := theme.Current()
+	theme.ApplyTitleBar(dlg.Handle(), palette)
+	theme.ApplyDark(dlg, palette)
+
+	if dlg.Run() != walk.DlgCmdOK {
+		return time.Time{}, time.Time{}, false
+	}
+	from = logWallClock(fromEdit.Date())
+	to = logWallClock(toEdit.Date())
+	if !to.IsZero() {
+		// The pickers have one second resolution; include the whole last second
+		to = to.Add(time.Second - time.Nanosecond)
+	}
+	return from, to, true
+}
+
+// logWallClock moves a picked local time to UTC with the same wall clock, to
+// match log stamps, which are parsed without a time zone
+func logWallClock(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// logLinesInRange returns the lines stamped within from and to, either of
+// which may be zero to leave that end open
+func logLinesInRange(items []LogLine, from, to time.Time) []LogLine {
+	if from.IsZero() && to.IsZero() {
+		return items
+	}
+	lines := make([]LogLine, 0, len(items))
+	for _, item := range items {
+		if !from.IsZero() && item.Stamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && item.Stamp.After(to) {
+			continue
+		}
+		lines = append(lines, item)
+	}
+	return lines
+}
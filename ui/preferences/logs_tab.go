@@ -4,13 +4,16 @@ package preferences
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fosrl/windows/config"
@@ -31,6 +34,9 @@ const (
 type LogsTab struct {
 	tabPage     *walk.TabPage
 	logView     *walk.TableView
+	detailEdit  *walk.TextEdit
+	pauseButton *walk.PushButton
+	onTopBox    *walk.CheckBox
 	clearButton *walk.PushButton
 	saveButton  *walk.PushButton
 	model       *logModel
@@ -45,6 +51,11 @@ type LogLine struct {
 	Line  string
 }
 
+// String returns the line as it appears in the log file
+func (l LogLine) String() string {
+	return fmt.Sprintf("%s [%s] %s", l.Stamp.Format("2006-01-02 15:04:05.000"), l.Level, l.Line)
+}
+
 // NewLogsTab creates a new logs tab
 func NewLogsTab() *LogsTab {
 	return &LogsTab{}
@@ -115,9 +126,20 @@ func (lt *LogsTab) Create(parent *walk.TabWidget) (*walk.TabPage, error) {
 	msgCol.SetTitle("Log message")
 	lt.logView.Columns().Add(msgCol)
 
+	// Detail pane with the whole selected line, which the message column
+	// may cut off
+	if lt.detailEdit, err = walk.NewTextEditWithStyle(lt.tabPage, win.WS_VSCROLL); err != nil {
+		return nil, err
+	}
+	lt.detailEdit.SetReadOnly(true)
+	lt.detailEdit.SetMinMaxSize(walk.Size{Width: 0, Height: 60}, walk.Size{Width: 0, Height: 60})
+	lt.logView.SelectedIndexesChanged().Attach(lt.updateDetail)
+
 	lt.model = newLogModel(lt)
 	lt.model.RowsReset().Attach(setSelectionStatus)
+	lt.model.RowsReset().Attach(lt.updateDetail)
 	lt.logView.SetModel(lt.model)
+	lt.logView.SetCellStyler(lt.model)
 	setSelectionStatus()
 
 	// Buttons will be created in AfterAdd() after tab is added to widget tree
@@ -146,8 +168,29 @@ func (lt *LogsTab) AfterAdd() {
 	buttonsContainer.SetLayout(walk.NewHBoxLayout())
 	buttonsContainer.Layout().SetMargins(walk.Margins{})
 
+	if lt.onTopBox, err = walk.NewCheckBox(buttonsContainer); err != nil {
+		logger.Error("Failed to create always on top checkbox: %v", err)
+		return
+	}
+	lt.onTopBox.SetText("Always on &top")
+	if lt.window != nil && lt.window.configManager != nil {
+		lt.onTopBox.SetChecked(lt.window.configManager.GetPreferencesOnTop())
+	}
+	lt.onTopBox.CheckedChanged().Attach(func() {
+		lt.onToggleOnTop()
+	})
+
 	walk.NewHSpacer(buttonsContainer)
 
+	if lt.pauseButton, err = walk.NewPushButton(buttonsContainer); err != nil {
+		logger.Error("Failed to create pause button: %v", err)
+		return
+	}
+	lt.pauseButton.SetText("&Pause")
+	lt.pauseButton.Clicked().Attach(func() {
+		lt.onTogglePause()
+	})
+
 	if lt.clearButton, err = walk.NewPushButton(buttonsContainer); err != nil {
 		logger.Error("Failed to create clear button: %v", err)
 		return
@@ -208,19 +251,57 @@ func (lt *LogsTab) onCopy() {
 		return
 	}
 	for i := 0; i < len(selectedItemIndexes); i++ {
-		logItem := lt.model.items[selectedItemIndexes[i]]
-		logLines.WriteString(fmt.Sprintf("%s [%s] %s\r\n",
-			logItem.Stamp.Format("2006-01-02 15:04:05.000"),
-			logItem.Level,
-			logItem.Line))
+		logLines.WriteString(lt.model.items[selectedItemIndexes[i]].String() + "\r\n")
 	}
 	walk.Clipboard().SetText(logLines.String())
 }
 
+// updateDetail shows the selected line in the detail pane, or clears it when
+// no line or several lines are selected
+func (lt *LogsTab) updateDetail() {
+	text := ""
+	if indexes := lt.logView.SelectedIndexes(); len(indexes) == 1 && indexes[0] < len(lt.model.items) {
+		text = lt.model.items[indexes[0]].String()
+	}
+	if lt.detailEdit.Text() != text {
+		lt.detailEdit.SetText(text)
+	}
+}
+
 func (lt *LogsTab) onSelectAll() {
 	lt.logView.SetSelectedIndexes([]int{-1})
 }
 
+// onTogglePause stops or resumes following the log file. While paused the
+// view does not change; on resume the lines written meanwhile are read in.
+func (lt *LogsTab) onTogglePause() {
+	paused := !lt.model.paused.Load()
+	lt.model.paused.Store(paused)
+	if paused {
+		lt.pauseButton.SetText("&Resume")
+		return
+	}
+	lt.pauseButton.SetText("&Pause")
+	lt.scrollToBottom()
+}
+
+// onToggleOnTop pins the window above other apps, e.g. to compare the log
+// against another app during a support session. It is the same setting as
+// Keep Window on Top in Preferences and is saved right away.
+func (lt *LogsTab) onToggleOnTop() {
+	if lt.window == nil {
+		return
+	}
+	onTop := lt.onTopBox.Checked()
+	lt.window.setTopmost(onTop)
+	if cm := lt.window.configManager; cm != nil && cm.GetPreferencesOnTop() != onTop && !cm.SetPreferencesOnTop(onTop) {
+		logger.Error("Failed to save always on top setting")
+	}
+	if lt.window.prefsTab != nil && lt.window.prefsTab.onTopCheckBox != nil {
+		lt.window.prefsTab.onTopCheckBox.SetChecked(onTop)
+	}
+}
+
 func (lt *LogsTab) onClear() {
 	// Clear all log items from the model
 	lt.model.mu.Lock()
@@ -234,17 +315,33 @@ func (lt *LogsTab) onClear() {
 }
 
 func (lt *LogsTab) onSave() {
-	fd := walk.FileDialog{
-		Filter:   "Text Files (*.txt)|*.txt|All Files (*.*)|*.*",
-		FilePath: fmt.Sprintf("pangolin-log-%s.txt", time.Now().Format("2006-01-02T150405")),
-		Title:    "Export log to file",
-	}
-
 	// Get the parent window for the dialog
 	if lt.window == nil {
 		return
 	}
 
+	from, to, ok := lt.promptLogRange()
+	if !ok {
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		td := walk.NewTaskDialog()
+		_, _ = td.Show(walk.TaskDialogOpts{
+			Owner:         lt.window,
+			Title:         "Invalid Input",
+			Content:       "The end of the time range must not be before its start.",
+			IconSystem:    walk.TaskDialogSystemIconError,
+			CommonButtons: win.TDCBF_OK_BUTTON,
+		})
+		return
+	}
+
+	fd := walk.FileDialog{
+		Filter:   "Text Files (*.txt)|*.txt|JSON Files (*.json)|*.json|All Files (*.*)|*.*",
+		FilePath: fmt.Sprintf("pangolin-log-%s.txt", time.Now().Format("2006-01-02T150405")),
+		Title:    "Export log to file",
+	}
+
 	if ok, _ := fd.ShowSave(lt.window); !ok {
 		return
 	}
@@ -252,14 +349,25 @@ func (lt *LogsTab) onSave() {
 	if fd.FilterIndex == 1 && !strings.HasSuffix(fd.FilePath, ".txt") {
 		fd.FilePath = fd.FilePath + ".txt"
 	}
+	asJSON := fd.FilterIndex == 2 || strings.EqualFold(filepath.Ext(fd.FilePath), ".json")
+	if fd.FilterIndex == 2 && !strings.HasSuffix(fd.FilePath, ".json") {
+		fd.FilePath = strings.TrimSuffix(fd.FilePath, ".txt") + ".json"
+	}
+
+	lt.model.mu.Lock()
+	items := slices.Clone(logLinesInRange(lt.model.items, from, to))
+	lt.model.mu.Unlock()
+
+	if asJSON {
+		writeFileWithOverwriteHandling(lt.window, fd.FilePath, func(file *os.File) error {
+			return writeLogJSON(file, items)
+		})
+		return
+	}
 
 	writeFileWithOverwriteHandling(lt.window, fd.FilePath, func(file *os.File) error {
-		for _, item := range lt.model.items {
-			line := fmt.Sprintf("%s [%s] %s\r\n",
-				item.Stamp.Format("2006-01-02 15:04:05.000"),
-				item.Level,
-				item.Line)
-			if _, err := file.WriteString(line); err != nil {
+		for _, item := range items {
+			if _, err := file.WriteString(item.String() + "\r\n"); err != nil {
 				return fmt.Errorf("failed to write log line: %w", err)
 			}
 		}
@@ -267,6 +375,32 @@ func (lt *LogsTab) onSave() {
 	})
 }
 
+// logLineJSON is a log line as written by the JSON export. The stamp has no
+// time zone, like the log file itself.
+type logLineJSON struct {
+	Stamp string `json:"stamp"`
+	Level string `json:"level"`
+	Line  string `json:"line"`
+}
+
+// writeLogJSON writes items as a JSON array of {stamp, level, line} objects
+func writeLogJSON(file *os.File, items []LogLine) error {
+	lines := make([]logLineJSON, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, logLineJSON{
+			Stamp: item.Stamp.Format("2006-01-02T15:04:05.000"),
+			Level: item.Level,
+			Line:  item.Line,
+		})
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(lines); err != nil {
+		return fmt.Errorf("failed to write log JSON: %w", err)
+	}
+	return nil
+}
+
 type logModel struct {
 	walk.ReflectTableModelBase
 	lt       *LogsTab
@@ -274,6 +408,7 @@ type logModel struct {
 	items    []LogLine
 	filePos  int64
 	lastSize int64
+	paused   atomic.Bool
 	mu       sync.Mutex
 }
 
@@ -361,6 +496,11 @@ func (mdl *logModel) loadInitialLogs() {
 }
 
 func (mdl *logModel) readNewLines() {
+	// Leave the file position alone while paused so resuming catches up
+	if mdl.paused.Load() {
+		return
+	}
+
 	logFile := filepath.Join(config.GetLogDir(), "pangolin.log")
 	file, err := os.Open(logFile)
 	if err != nil {
@@ -548,6 +688,36 @@ func (mdl *logModel) Items() any {
 	return mdl.items
 }
 
+// StyleCell colors rows by log level so errors and warnings stand out
+func (mdl *logModel) StyleCell(style *walk.CellStyle) {
+	mdl.mu.Lock()
+	row := style.Row()
+	if row < 0 || row >= len(mdl.items) {
+		mdl.mu.Unlock()
+		return
+	}
+	level := mdl.items[row].Level
+	mdl.mu.Unlock()
+
+	if color, ok := logLevelColor(level); ok {
+		style.TextColor = color
+	}
+}
+
+// logLevelColor returns the text color for rows of a log level, if any
+func logLevelColor(level string) (walk.Color, bool) {
+	switch strings.ToUpper(level) {
+	case "ERROR", "FATAL":
+		return walk.RGB(0xD0, 0x30, 0x30), true // Error red
+	case "WARN", "WARNING":
+		return walk.RGB(0xD0, 0x80, 0x00), true // Warning amber
+	case "DEBUG":
+		return walk.RGB(0x80, 0x80, 0x80), true // Secondary gray
+	default:
+		return 0, false
+	}
+}
+
 // writeFileWithOverwriteHandling handles file overwrite confirmation
 func writeFileWithOverwriteHandling(owner walk.Form, filePath string, writeFunc func(*os.File) error) {
 	if _, err := os.Stat(filePath); err == nil {
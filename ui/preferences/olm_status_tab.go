@@ -12,6 +12,7 @@ import (
 
 	"github.com/fosrl/newt/logger"
 	"github.com/fosrl/windows/tunnel"
+	"github.com/fosrl/windows/ui/theme"
 
 	"github.com/tailscale/walk"
 	"github.com/tailscale/win"
@@ -44,6 +45,7 @@ type peerWidgets struct {
 	endpointLabel *walk.Label
 	indicator     *walk.Label
 	statusLabel   *walk.Label
+	sparkline     *walk.CustomWidget
 	firstSeen     time.Time
 	rowVisible    bool
 }
@@ -65,6 +67,8 @@ type OLMStatusTab struct {
 	// JSON view
 	jsonEdit *walk.TextEdit
 
+	testConnectionButton *walk.PushButton
+
 	// Formatted view
 	formattedContainer *walk.Composite
 	statusContainer    *walk.Composite
@@ -74,6 +78,8 @@ type OLMStatusTab struct {
 	// Widget references for updating (protected by mu)
 	statusWidgets *statusWidgets
 	peerWidgets   map[int]*peerWidgets // keyed by siteID
+	// RTT samples per peer for the sparklines, keyed by siteID (protected by mu)
+	rttHistories map[int]*rttHistory
 
 	// Current status (protected by mu)
 	currentStatus *tunnel.OLMStatusResponse
@@ -94,6 +100,7 @@ func NewOLMStatusTab(tm *tunnel.Manager) *OLMStatusTab {
 		tunnelManager: tm,
 		quit:          make(chan bool),
 		peerWidgets:   make(map[int]*peerWidgets),
+		rttHistories:  make(map[int]*rttHistory),
 		currentTunnelState: state,
 		displayMode:   DisplayModeFormatted, // Default to formatted view
 	}
@@ -378,7 +385,71 @@ func (ost *OLMStatusTab) createStatusWidgets() error {
 
 // AfterAdd is called after the tab page is added to the tab widget
 func (ost *OLMStatusTab) AfterAdd() {
-	// Nothing to do for OLM status tab
+	buttonsContainer, err := walk.NewComposite(ost.tabPage)
+	if err != nil {
+		logger.Error("Failed to create buttons container: %v", err)
+		return
+	}
+	buttonsContainer.SetLayout(walk.NewHBoxLayout())
+	buttonsContainer.Layout().SetMargins(walk.Margins{})
+
+	walk.NewHSpacer(buttonsContainer)
+
+	if ost.testConnectionButton, err = walk.NewPushButton(buttonsContainer); err != nil {
+		logger.Error("Failed to create test connection button: %v", err)
+		return
+	}
+	ost.testConnectionButton.SetText("&Test Server Connection")
+	ost.testConnectionButton.Clicked().Attach(func() {
+		ost.onTestConnection()
+	})
+}
+
+// onTestConnection runs the server connectivity checks in the background and shows a summary
+func (ost *OLMStatusTab) onTestConnection() {
+	if ost.tunnelManager == nil {
+		return
+	}
+
+	ost.testConnectionButton.SetEnabled(false)
+	ost.testConnectionButton.SetText("Testing…")
+
+	go func() {
+		checks := ost.tunnelManager.TestServerConnection()
+
+		passed := true
+		var summary strings.Builder
+		for _, check := range checks {
+			result := "PASS"
+			if !check.Passed {
+				result = "FAIL"
+				passed = false
+			}
+			summary.WriteString(fmt.Sprintf("[%s] %s: %s\n", result, check.Name, check.Detail))
+			logger.Info("Server connection test: [%s] %s: %s", result, check.Name, check.Detail)
+		}
+
+		walk.App().Synchronize(func() {
+			ost.testConnectionButton.SetEnabled(true)
+			ost.testConnectionButton.SetText("&Test Server Connection")
+
+			title := "All Checks Passed"
+			icon := walk.TaskDialogSystemIconInformation
+			if !passed {
+				title = "Some Checks Failed"
+				icon = walk.TaskDialogSystemIconWarning
+			}
+			td := walk.NewTaskDialog()
+			_, _ = td.Show(walk.TaskDialogOpts{
+				Owner:         ost.tabPage.Form(),
+				Title:         "Server Connection Test",
+				Instruction:   title,
+				Content:       strings.TrimSpace(summary.String()),
+				IconSystem:    icon,
+				CommonButtons: win.TDCBF_OK_BUTTON,
+			})
+		})
+	}()
 }
 
 // Cleanup cleans up resources when the tab is closed
@@ -425,6 +496,10 @@ func (ost *OLMStatusTab) pollOLMStatus() {
 			if stateChanged {
 				ost.mu.Lock()
 				ost.currentTunnelState = state
+				// History restarts with each connection
+				if state == tunnel.StateStopped || state == tunnel.StateError {
+					ost.resetRTT()
+				}
 				ost.mu.Unlock()
 			}
 
@@ -454,6 +529,9 @@ func (ost *OLMStatusTab) pollOLMStatus() {
 			// Update current status
 			ost.mu.Lock()
 			ost.currentStatus = status
+			if state == tunnel.StateRunning {
+				ost.recordRTT(status)
+			}
 			ost.mu.Unlock()
 
 			// Update UI (state or OLM details changed)
@@ -610,6 +688,7 @@ func (ost *OLMStatusTab) updatePeersList(status *tunnel.OLMStatusResponse) {
 		name      string
 		endpoint  string
 		connected bool
+		rtt       time.Duration
 	}, 0)
 
 	ost.mu.Lock()
@@ -625,7 +704,8 @@ func (ost *OLMStatusTab) updatePeersList(status *tunnel.OLMStatusResponse) {
 				name      string
 				endpoint  string
 				connected bool
-			}{siteID, peer.SiteName, peer.Endpoint, peer.Connected})
+				rtt       time.Duration
+			}{siteID, peer.SiteName, peer.Endpoint, peer.Connected, peer.RTT})
 		} else {
 			// Update existing peer widget
 			if pw.nameLabel != nil {
@@ -653,14 +733,26 @@ func (ost *OLMStatusTab) updatePeersList(status *tunnel.OLMStatusResponse) {
 				pw.firstSeen = time.Now()
 			}
 			pw.rowVisible = true
+			if pw.sparkline != nil {
+				pw.sparkline.Invalidate()
+			}
 
 			// Update per-site status with a 10-second connecting window.
-			if peer.Connected {
+			downSince := ost.tunnelManager.PeerDownSince(siteID)
+			if !peer.Connected && tunnel.IsPeerStale(downSince, ost.tunnelManager.StalePeerThreshold(), time.Now()) {
+				// The site was up, but stopped answering while the tunnel still runs
+				if pw.indicator != nil {
+					pw.indicator.SetTextColor(walk.RGB(255, 140, 0))
+				}
+				if pw.statusLabel != nil {
+					pw.statusLabel.SetText(fmt.Sprintf("Unreachable (down for %s)", time.Since(downSince).Round(time.Second)))
+				}
+			} else if peer.Connected {
 				if pw.indicator != nil {
 					pw.indicator.SetTextColor(walk.RGB(0, 200, 0))
 				}
 				if pw.statusLabel != nil {
-					pw.statusLabel.SetText("Connected")
+					pw.statusLabel.SetText(fmt.Sprintf("Connected (%s)", tunnel.FormatRTT(peer.RTT)))
 				}
 			} else {
 				// Not connected yet: show "Connecting" until timeout, then "Disconnected".
@@ -699,14 +791,14 @@ func (ost *OLMStatusTab) updatePeersList(status *tunnel.OLMStatusResponse) {
 
 	// Create new peer widgets (outside lock, as it creates UI widgets)
 	for _, peerInfo := range peersToCreate {
-		if err := ost.createPeerWidget(peerInfo.siteID, peerInfo.name, peerInfo.endpoint, peerInfo.connected); err != nil {
+		if err := ost.createPeerWidget(peerInfo.siteID, peerInfo.name, peerInfo.endpoint, peerInfo.connected, peerInfo.rtt); err != nil {
 			continue
 		}
 	}
 }
 
 // createPeerWidget creates a new peer widget row
-func (ost *OLMStatusTab) createPeerWidget(siteID int, name, endpoint string, connected bool) error {
+func (ost *OLMStatusTab) createPeerWidget(siteID int, name, endpoint string, connected bool, rtt time.Duration) error {
 	pw := &peerWidgets{}
 
 	ost.mu.Lock()
@@ -786,7 +878,7 @@ func (ost *OLMStatusTab) createPeerWidget(siteID int, name, endpoint string, con
 	pw.indicator.SetMinMaxSize(walk.Size{Width: 12, Height: 12}, walk.Size{Width: 12, Height: 12})
 
 	// Status text
-	statusText := "Connected"
+	statusText := fmt.Sprintf("Connected (%s)", tunnel.FormatRTT(rtt))
 	if !connected {
 		statusText = "Connecting"
 	}
@@ -797,8 +889,14 @@ func (ost *OLMStatusTab) createPeerWidget(siteID int, name, endpoint string, con
 	pw.statusLabel.SetText(statusText)
 	pw.statusLabel.SetTextColor(walk.RGB(100, 100, 100))
 
+	// RTT history graph
+	if pw.sparkline, err = ost.newSparkline(row, siteID); err != nil {
+		logger.Error("Failed to create RTT graph: %v", err)
+	}
+
 	// Add spacer to match status row structure
 	walk.NewHSpacer(row)
+	theme.ApplyDark(row, theme.Current())
 
 	ost.mu.Lock()
 	ost.peerWidgets[siteID] = pw
@@ -0,0 +1,192 @@
+//go:build windows
+
+package preferences
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fosrl/windows/tunnel"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+)
+
+// ResourcesTab lists the resources of the current organization, so users can
+// see what the tunnel will route before connecting
+type ResourcesTab struct {
+	tabPage       *walk.TabPage
+	tunnelManager *tunnel.Manager
+	orgLabel      *walk.Label
+	statusLabel   *walk.Label
+	refreshButton *walk.PushButton
+	resourceView  *walk.TableView
+	model         *resourceModel
+}
+
+// ResourceRow is a resource as shown in the resources table
+type ResourceRow struct {
+	Name        string
+	Destination string
+	Alias       string
+	Site        string
+}
+
+type resourceModel struct {
+	walk.ReflectTableModelBase
+	items []ResourceRow
+}
+
+func (mdl *resourceModel) Items() any {
+	return mdl.items
+}
+
+// NewResourcesTab creates a new resources tab
+func NewResourcesTab(tm *tunnel.Manager) *ResourcesTab {
+	return &ResourcesTab{
+		tunnelManager: tm,
+		model:         &resourceModel{},
+	}
+}
+
+// Create creates the resources tab UI
+func (rt *ResourcesTab) Create(parent *walk.TabWidget) (*walk.TabPage, error) {
+	var err error
+	if rt.tabPage, err = walk.NewTabPage(); err != nil {
+		return nil, err
+	}
+
+	rt.tabPage.SetTitle("Resources")
+	rt.tabPage.SetLayout(walk.NewVBoxLayout())
+
+	headerContainer, err := walk.NewComposite(rt.tabPage)
+	if err != nil {
+		return nil, err
+	}
+	headerLayout := walk.NewHBoxLayout()
+	headerLayout.SetMargins(walk.Margins{})
+	headerLayout.SetSpacing(8)
+	headerContainer.SetLayout(headerLayout)
+
+	if rt.orgLabel, err = walk.NewLabel(headerContainer); err != nil {
+		return nil, err
+	}
+
+	walk.NewHSpacer(headerContainer)
+
+	if rt.refreshButton, err = walk.NewPushButton(headerContainer); err != nil {
+		return nil, err
+	}
+	rt.refreshButton.SetText("&Refresh")
+	rt.refreshButton.Clicked().Attach(rt.refresh)
+
+	if rt.resourceView, err = walk.NewTableView(rt.tabPage); err != nil {
+		return nil, err
+	}
+	rt.resourceView.SetAlternatingRowBG(true)
+	rt.resourceView.SetLastColumnStretched(true)
+	rt.resourceView.SetGridlines(true)
+
+	nameCol := walk.NewTableViewColumn()
+	nameCol.SetName("Name")
+	nameCol.SetTitle("Name")
+	nameCol.SetWidth(130)
+	rt.resourceView.Columns().Add(nameCol)
+
+	destCol := walk.NewTableViewColumn()
+	destCol.SetName("Destination")
+	destCol.SetTitle("Destination")
+	destCol.SetWidth(120)
+	rt.resourceView.Columns().Add(destCol)
+
+	aliasCol := walk.NewTableViewColumn()
+	aliasCol.SetName("Alias")
+	aliasCol.SetTitle("Alias")
+	aliasCol.SetWidth(100)
+	rt.resourceView.Columns().Add(aliasCol)
+
+	siteCol := walk.NewTableViewColumn()
+	siteCol.SetName("Site")
+	siteCol.SetTitle("Site")
+	rt.resourceView.Columns().Add(siteCol)
+
+	rt.resourceView.SetModel(rt.model)
+
+	if rt.statusLabel, err = walk.NewLabel(rt.tabPage); err != nil {
+		return nil, err
+	}
+	rt.statusLabel.SetTextColor(walk.RGB(100, 100, 100))
+
+	return rt.tabPage, nil
+}
+
+// AfterAdd is called after the tab page is added to the tab widget
+func (rt *ResourcesTab) AfterAdd() {
+	rt.refresh()
+}
+
+// Cleanup cleans up resources when the tab is closed
+func (rt *ResourcesTab) Cleanup() {
+}
+
+// refresh loads the resources of the current organization in the background
+func (rt *ResourcesTab) refresh() {
+	if rt.tunnelManager == nil {
+		rt.statusLabel.SetText("Resources are not available.")
+		return
+	}
+
+	rt.refreshButton.SetEnabled(false)
+	rt.statusLabel.SetText("Loading resources…")
+
+	go func() {
+		org, resources, err := rt.tunnelManager.ListResources()
+		if err != nil {
+			logger.Error("Failed to list resources: %v", err)
+		}
+
+		rows := make([]ResourceRow, 0, len(resources))
+		for _, resource := range resources {
+			row := ResourceRow{
+				Name:        resource.Name,
+				Destination: resource.Destination,
+				Site:        resource.SiteName,
+			}
+			if resource.Alias != nil {
+				row.Alias = *resource.Alias
+			}
+			rows = append(rows, row)
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			return strings.ToLower(rows[i].Name) < strings.ToLower(rows[j].Name)
+		})
+
+		walk.App().Synchronize(func() {
+			if rt.tabPage.IsDisposed() {
+				return
+			}
+			rt.refreshButton.SetEnabled(true)
+
+			if org != nil {
+				rt.orgLabel.SetText(fmt.Sprintf("Organization: %s", org.Name))
+			} else {
+				rt.orgLabel.SetText("No organization selected")
+			}
+
+			rt.model.items = rows
+			rt.model.PublishRowsReset()
+
+			switch {
+			case err != nil && org == nil:
+				rt.statusLabel.SetText("Log in and select an organization to see its resources.")
+			case err != nil:
+				rt.statusLabel.SetText(fmt.Sprintf("Could not load resources: %v", err))
+			case len(rows) == 0:
+				rt.statusLabel.SetText("This organization has no resources you can access.")
+			default:
+				rt.statusLabel.SetText(fmt.Sprintf("%d resources. Traffic to these destinations is routed through the tunnel.", len(rows)))
+			}
+		})
+	}()
+}
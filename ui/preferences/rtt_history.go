@@ -0,0 +1,145 @@
+//go:build windows
+
+package preferences
+
+import (
+	"time"
+
+	"github.com/fosrl/windows/tunnel"
+
+	"github.com/tailscale/walk"
+)
+
+// rttHistorySamples is how many RTT samples are kept per peer: five minutes
+// at the status tab's one second poll interval
+const rttHistorySamples = 300
+
+// Sparkline size in pixels at 96 DPI
+const (
+	sparklineWidth  = 120
+	sparklineHeight = 20
+)
+
+// rttHistory is a rolling buffer of RTT samples for one peer. Samples where
+// the peer was disconnected or the RTT was not measured are stored as 0 and
+// drawn as gaps.
+type rttHistory struct {
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+func newRTTHistory() *rttHistory {
+	return &rttHistory{samples: make([]time.Duration, rttHistorySamples)}
+}
+
+// add records one sample, overwriting the oldest once the buffer is full
+func (h *rttHistory) add(rtt time.Duration, connected bool) {
+	if !connected || !tunnel.IsMeasuredRTT(rtt) {
+		rtt = 0
+	}
+	h.samples[h.next] = rtt
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// values returns the samples from oldest to newest
+func (h *rttHistory) values() []time.Duration {
+	if !h.full {
+		return append([]time.Duration(nil), h.samples[:h.next]...)
+	}
+	return append(append([]time.Duration(nil), h.samples[h.next:]...), h.samples[:h.next]...)
+}
+
+// recordRTT adds a sample for every peer in status. Histories of peers that
+// are no longer reported are dropped. Must be called with ost.mu held.
+func (ost *OLMStatusTab) recordRTT(status *tunnel.OLMStatusResponse) {
+	seen := make(map[int]bool)
+	if status != nil {
+		for siteID, peer := range status.PeerStatuses {
+			seen[siteID] = true
+			history, ok := ost.rttHistories[siteID]
+			if !ok {
+				history = newRTTHistory()
+				ost.rttHistories[siteID] = history
+			}
+			history.add(peer.RTT, peer.Connected)
+		}
+	}
+	for siteID := range ost.rttHistories {
+		if !seen[siteID] {
+			delete(ost.rttHistories, siteID)
+		}
+	}
+}
+
+// resetRTT clears all RTT histories, e.g. on disconnect. Must be called with ost.mu held.
+func (ost *OLMStatusTab) resetRTT() {
+	ost.rttHistories = make(map[int]*rttHistory)
+}
+
+// newSparkline creates a small graph of the peer's RTT history, scaled to the
+// largest sample in the window
+func (ost *OLMStatusTab) newSparkline(parent walk.Container, siteID int) (*walk.CustomWidget, error) {
+	sparkline, err := walk.NewCustomWidget(parent, 0, func(canvas *walk.Canvas, updateBounds walk.Rectangle) error {
+		ost.mu.Lock()
+		var values []time.Duration
+		if history, ok := ost.rttHistories[siteID]; ok {
+			values = history.values()
+		}
+		ost.mu.Unlock()
+
+		bounds := walk.Rectangle{Width: sparklineWidth, Height: sparklineHeight}
+		var peak time.Duration
+		for _, v := range values {
+			if v > peak {
+				peak = v
+			}
+		}
+		if peak == 0 {
+			return nil
+		}
+
+		pen, err := walk.NewCosmeticPen(walk.PenSolid, walk.RGB(0x2B, 0x7A, 0xC8))
+		if err != nil {
+			return err
+		}
+		defer pen.Dispose()
+
+		// Samples are placed right-aligned so the newest is always at the right edge
+		step := float64(bounds.Width-1) / float64(rttHistorySamples-1)
+		offset := rttHistorySamples - len(values)
+		var line []walk.Point
+		flush := func() error {
+			defer func() { line = line[:0] }()
+			switch len(line) {
+			case 0:
+				return nil
+			case 1:
+				return canvas.DrawLine(pen, line[0], walk.Point{X: line[0].X + 1, Y: line[0].Y})
+			default:
+				return canvas.DrawPolyline(pen, line)
+			}
+		}
+		for i, v := range values {
+			if v == 0 {
+				if err := flush(); err != nil {
+					return err
+				}
+				continue
+			}
+			x := int(float64(offset+i) * step)
+			y := bounds.Height - 1 - int(float64(v)/float64(peak)*float64(bounds.Height-2))
+			line = append(line, walk.Point{X: x, Y: y})
+		}
+		return flush()
+	})
+	if err != nil {
+		return nil, err
+	}
+	sparkline.SetMinMaxSize(walk.Size{Width: sparklineWidth, Height: sparklineHeight}, walk.Size{Width: sparklineWidth, Height: sparklineHeight})
+	sparkline.SetToolTipText("Round-trip time over the last 5 minutes")
+	return sparkline, nil
+}
@@ -8,7 +8,9 @@ import (
 	"sync"
 
 	"github.com/fosrl/windows/config"
+	"github.com/fosrl/windows/secrets"
 	"github.com/fosrl/windows/tunnel"
+	"github.com/fosrl/windows/ui/theme"
 
 	"github.com/fosrl/newt/logger"
 	"github.com/tailscale/walk"
@@ -23,6 +25,8 @@ type PreferencesWindow struct {
 	configManager *config.ConfigManager
 	trayIcon      *walk.NotifyIcon
 	tabs          []Tab
+	prefsTab      *PreferencesTab
+	logsTab       *LogsTab
 }
 
 // Tab represents a tab in the preferences window
@@ -39,12 +43,25 @@ type Tab interface {
 var (
 	preferencesWindowInstance *PreferencesWindow
 	preferencesWindowMutex    sync.Mutex
+	onSettingsSaved           func()
+	secretManager             *secrets.SecretManager
 )
 
+// SetOnSettingsSaved sets a callback run on the UI thread after the user saves
+// settings, so the tray can apply settings it owns
+func SetOnSettingsSaved(fn func()) {
+	onSettingsSaved = fn
+}
+
+// SetSecretManager sets the secret manager that holds the proxy login
+func SetSecretManager(sm *secrets.SecretManager) {
+	secretManager = sm
+}
+
 // ShowPreferencesWindow shows the preferences window (creates if needed, or brings to front).
-// It accepts a tunnel manager to enable OLM status polling, a config manager for settings, and a tray icon for notifications.
+// It accepts a tunnel manager to enable OLM status polling, config and account managers for settings, and a tray icon for notifications.
 // initialTabIndex selects the tab to show (0-based, following the order in NewPreferencesWindow).
-func ShowPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.ConfigManager, trayIcon *walk.NotifyIcon, initialTabIndex int) error {
+func ShowPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.ConfigManager, accm *config.AccountManager, trayIcon *walk.NotifyIcon, initialTabIndex int) error {
 	preferencesWindowMutex.Lock()
 	defer preferencesWindowMutex.Unlock()
 
@@ -72,7 +89,7 @@ func ShowPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.Confi
 	}
 
 	// Create new window
-	pw, err := NewPreferencesWindow(owner, tm, cm, trayIcon)
+	pw, err := NewPreferencesWindow(owner, tm, cm, accm, trayIcon)
 	if err != nil {
 		return err
 	}
@@ -108,8 +125,25 @@ func ShowPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.Confi
 	return nil
 }
 
+// ReloadAccountSettings refreshes the account-specific settings shown in an
+// open preferences window, e.g. after the active account changes.
+func ReloadAccountSettings() {
+	preferencesWindowMutex.Lock()
+	pw := preferencesWindowInstance
+	preferencesWindowMutex.Unlock()
+
+	if pw == nil || pw.prefsTab == nil {
+		return
+	}
+	walk.App().Synchronize(func() {
+		if pw.Handle() != 0 {
+			pw.prefsTab.loadDNSSettings()
+		}
+	})
+}
+
 // NewPreferencesWindow creates a new preferences window with tabs
-func NewPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.ConfigManager, trayIcon *walk.NotifyIcon) (*PreferencesWindow, error) {
+func NewPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.ConfigManager, accm *config.AccountManager, trayIcon *walk.NotifyIcon) (*PreferencesWindow, error) {
 	pw := &PreferencesWindow{
 		tunnelManager: tm,
 		configManager: cm,
@@ -135,8 +169,8 @@ func NewPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.Config
 	}
 
 	// Create and add tabs
-	// Order: Preferences, Status, Logs, About
-	prefsTab := NewPreferencesTab(cm)
+	// Order: Preferences, Status, Resources, Logs, About
+	prefsTab := NewPreferencesTab(cm, accm)
 	if tabPage, err := prefsTab.Create(pw.tabWidget); err != nil {
 		return nil, fmt.Errorf("failed to create preferences tab: %w", err)
 	} else {
@@ -144,6 +178,7 @@ func NewPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.Config
 		pw.tabWidget.Pages().Add(tabPage)
 		prefsTab.AfterAdd()
 		pw.tabs = append(pw.tabs, prefsTab)
+		pw.prefsTab = prefsTab
 	}
 
 	olmTab := NewOLMStatusTab(tm)
@@ -155,6 +190,15 @@ func NewPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.Config
 		pw.tabs = append(pw.tabs, olmTab)
 	}
 
+	resourcesTab := NewResourcesTab(tm)
+	if tabPage, err := resourcesTab.Create(pw.tabWidget); err != nil {
+		return nil, fmt.Errorf("failed to create resources tab: %w", err)
+	} else {
+		pw.tabWidget.Pages().Add(tabPage)
+		resourcesTab.AfterAdd()
+		pw.tabs = append(pw.tabs, resourcesTab)
+	}
+
 	logsTab := NewLogsTab()
 	if tabPage, err := logsTab.Create(pw.tabWidget); err != nil {
 		return nil, fmt.Errorf("failed to create logs tab: %w", err)
@@ -163,6 +207,7 @@ func NewPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.Config
 		pw.tabWidget.Pages().Add(tabPage)
 		logsTab.AfterAdd()
 		pw.tabs = append(pw.tabs, logsTab)
+		pw.logsTab = logsTab
 	}
 
 	aboutTab := NewAboutTab()
@@ -188,6 +233,11 @@ func NewPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.Config
 		}
 	}
 
+	// Match the Windows app theme, read each time the window opens
+	palette := theme.Current()
+	theme.ApplyTitleBar(pw.Handle(), palette)
+	theme.ApplyDark(pw, palette)
+
 	// Set window size after all components are added
 	pw.SetSize(walk.Size{Width: 450, Height: 600})
 
@@ -198,5 +248,20 @@ func NewPreferencesWindow(owner walk.Form, tm *tunnel.Manager, cm *config.Config
 	exStyle |= WS_EX_APPWINDOW
 	win.SetWindowLong(pw.Handle(), GWL_EXSTYLE, exStyle)
 
+	if cm != nil && cm.GetPreferencesOnTop() {
+		pw.setTopmost(true)
+	}
+
 	return pw, nil
 }
+
+// setTopmost keeps the window above non-topmost windows, or returns it to the normal z-order
+func (pw *PreferencesWindow) setTopmost(topmost bool) {
+	insertAfter := win.HWND_NOTOPMOST
+	if topmost {
+		insertAfter = win.HWND_TOPMOST
+	}
+	if !win.SetWindowPos(pw.Handle(), insertAfter, 0, 0, 0, 0, win.SWP_NOMOVE|win.SWP_NOSIZE|win.SWP_NOACTIVATE) {
+		logger.Error("Failed to update preferences window z-order")
+	}
+}
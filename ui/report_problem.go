@@ -0,0 +1,42 @@
+//go:build windows
+
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"runtime"
+
+	"github.com/fosrl/windows/fingerprint"
+	"github.com/fosrl/windows/version"
+)
+
+// issuesURL is where new issues for the Windows client are filed
+const issuesURL = "https://github.com/fosrl/windows/issues/new"
+
+// reportProblemURL returns a new-issue link with the environment section
+// filled in, so reports consistently include the client and OS versions
+func reportProblemURL() string {
+	body := fmt.Sprintf(`### Describe the problem
+
+
+
+### Steps to reproduce
+
+1. 
+
+### Environment
+
+- Client version: %s
+- OS: %s
+- Architecture: %s
+
+### Diagnostics
+
+Please attach a diagnostics bundle: in the Pangolin tray menu choose More > Export Diagnostics…, review the .zip file and drag it into this issue.
+`, version.Number, fingerprint.OSVersion(), runtime.GOARCH)
+
+	params := url.Values{}
+	params.Set("body", body)
+	return issuesURL + "?" + params.Encode()
+}
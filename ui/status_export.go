@@ -0,0 +1,165 @@
+//go:build windows
+
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fosrl/windows/auth"
+	"github.com/fosrl/windows/diagnostics"
+	"github.com/fosrl/windows/tunnel"
+	"github.com/fosrl/windows/version"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	"github.com/tailscale/win"
+)
+
+// statusExport is the connection status snapshot users attach to support tickets
+type statusExport struct {
+	ClientVersion string                    `json:"clientVersion"`
+	ExportedAt    time.Time                 `json:"exportedAt"`
+	Account       string                    `json:"account,omitempty"`
+	Server        string                    `json:"server,omitempty"`
+	OrgID         string                    `json:"orgId,omitempty"`
+	OrgName       string                    `json:"orgName,omitempty"`
+	TunnelState   string                    `json:"tunnelState"`
+	Status        *tunnel.OLMStatusResponse `json:"status,omitempty"`
+	StatusError   string                    `json:"statusError,omitempty"`
+}
+
+// buildStatusExport collects client, account and OLM status information as indented JSON
+func buildStatusExport() ([]byte, error) {
+	export := statusExport{
+		ClientVersion: version.Number,
+		ExportedAt:    time.Now(),
+		Server:        activeAccountServer(),
+		TunnelState:   tunnel.StateStopped.String(),
+	}
+
+	if authManager != nil {
+		if user := authManager.CurrentUser(); user != nil {
+			export.Account = auth.UserDisplayName(user)
+		}
+		if org := authManager.CurrentOrg(); org != nil {
+			export.OrgID = org.Id
+			export.OrgName = org.Name
+		}
+	}
+
+	if tunnelManager != nil {
+		export.TunnelState = tunnelManager.State().String()
+		status, err := tunnelManager.GetOLMStatus()
+		if err != nil {
+			// Still export the rest; a missing status is useful information too
+			export.StatusError = err.Error()
+		} else {
+			export.Status = status
+		}
+	}
+
+	return json.MarshalIndent(export, "", "  ")
+}
+
+// exportConnectionStatus copies the connection status JSON to the clipboard
+func exportConnectionStatus() {
+	data, err := buildStatusExport()
+	walk.App().Synchronize(func() {
+		if err == nil {
+			err = walk.Clipboard().SetText(string(data))
+		}
+		if err != nil {
+			logger.Error("Failed to export connection status: %v", err)
+			td := walk.NewTaskDialog()
+			_, _ = td.Show(walk.TaskDialogOpts{
+				Owner:         mainWindow,
+				Title:         "Export Failed",
+				Content:       fmt.Sprintf("Failed to export connection status: %v", err),
+				IconSystem:    walk.TaskDialogSystemIconError,
+				CommonButtons: win.TDCBF_OK_BUTTON,
+			})
+			return
+		}
+		if trayIcon != nil {
+			trayIcon.ShowInfo("Connection Status Copied", "The connection status has been copied to the clipboard.")
+		}
+	})
+}
+
+// copySupportID copies the current user's email and OLM ID to the clipboard, so
+// support can find this device in server logs. Must run on the UI thread.
+func copySupportID() {
+	if authManager == nil || !authManager.IsAuthenticated() {
+		return
+	}
+
+	var email string
+	if user := authManager.CurrentUser(); user != nil {
+		email = user.Email
+	} else if account, err := accountManager.ActiveAccount(); err == nil {
+		email = account.Email
+	}
+
+	olmID, found := authManager.GetOlmId()
+	if !found || olmID == "" {
+		td := walk.NewTaskDialog()
+		_, _ = td.Show(walk.TaskDialogOpts{
+			Owner:         mainWindow,
+			Title:         "No Support ID",
+			Content:       "This device has not been registered yet. Connect once, then try again.",
+			IconSystem:    walk.TaskDialogSystemIconInformation,
+			CommonButtons: win.TDCBF_OK_BUTTON,
+		})
+		return
+	}
+
+	text := fmt.Sprintf("Email: %s\r\nOLM ID: %s", email, olmID)
+	if err := walk.Clipboard().SetText(text); err != nil {
+		logger.Error("Failed to copy support ID: %v", err)
+		return
+	}
+	if trayIcon != nil {
+		trayIcon.ShowInfo("Support ID Copied", "Your email and device ID have been copied to the clipboard.")
+	}
+}
+
+// exportDiagnosticsBundle asks where to save and writes a diagnostics zip. Must run on the UI thread.
+func exportDiagnosticsBundle() {
+	fd := walk.FileDialog{
+		Filter:   "Zip Files (*.zip)|*.zip|All Files (*.*)|*.*",
+		FilePath: fmt.Sprintf("pangolin-diagnostics-%s.zip", time.Now().Format("2006-01-02T150405")),
+		Title:    "Export diagnostics",
+	}
+	if ok, _ := fd.ShowSave(mainWindow); !ok {
+		return
+	}
+	if fd.FilterIndex == 1 && !strings.HasSuffix(fd.FilePath, ".zip") {
+		fd.FilePath = fd.FilePath + ".zip"
+	}
+	path := fd.FilePath
+
+	go func() {
+		err := diagnostics.ExportBundle(path, configManager, tunnelManager)
+		walk.App().Synchronize(func() {
+			if err != nil {
+				logger.Error("Failed to export diagnostics: %v", err)
+				td := walk.NewTaskDialog()
+				_, _ = td.Show(walk.TaskDialogOpts{
+					Owner:         mainWindow,
+					Title:         "Export Failed",
+					Content:       fmt.Sprintf("Failed to export diagnostics: %v", err),
+					IconSystem:    walk.TaskDialogSystemIconError,
+					CommonButtons: win.TDCBF_OK_BUTTON,
+				})
+				return
+			}
+			logger.Info("Diagnostics exported to %s", path)
+			if trayIcon != nil {
+				trayIcon.ShowInfo("Diagnostics Exported", fmt.Sprintf("Diagnostics saved to %s", path))
+			}
+		})
+	}()
+}
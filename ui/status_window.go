@@ -0,0 +1,213 @@
+//go:build windows
+
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fosrl/windows/config"
+	"github.com/fosrl/windows/tunnel"
+	"github.com/fosrl/windows/ui/preferences"
+	"github.com/fosrl/windows/ui/theme"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	. "github.com/tailscale/walk/declarative"
+	"github.com/tailscale/win"
+)
+
+var (
+	statusWindow        *walk.MainWindow
+	statusWindowState   *walk.Label
+	statusWindowAccount *walk.Label
+	statusWindowWarning *walk.Label
+	statusWindowEvents  *walk.Label
+	statusWindowConnect *walk.PushButton
+	statusWindowIcon    string
+	// statusWindowRemoving is set while the window is closed because the
+	// setting was turned off, so the close isn't turned into a minimize
+	statusWindowRemoving bool
+)
+
+// applyStatusWindowSetting shows or removes the taskbar status window to match
+// the "Show Status in Taskbar" setting. Must be called on the UI thread.
+func applyStatusWindowSetting() {
+	enabled := configManager != nil && configManager.GetShowStatusWindow()
+	if enabled && statusWindow == nil {
+		createStatusWindow()
+	} else if !enabled && statusWindow != nil {
+		statusWindowRemoving = true
+		statusWindow.Close()
+		statusWindowRemoving = false
+	}
+	updateStatusWindow()
+}
+
+// createStatusWindow creates the small status window that gives users without
+// a visible tray icon a taskbar button showing the connection state
+func createStatusWindow() {
+	var mw *walk.MainWindow
+	err := MainWindow{
+		AssignTo: &mw,
+		Title:    "Pangolin",
+		MinSize:  Size{Width: 340, Height: 160},
+		Size:     Size{Width: 340, Height: 240},
+		Layout:   VBox{Margins: Margins{Left: 16, Top: 12, Right: 16, Bottom: 12}, Spacing: 6},
+		Children: []Widget{
+			Label{
+				AssignTo: &statusWindowState,
+				Font:     Font{PointSize: 11, Bold: true},
+			},
+			Label{
+				AssignTo:  &statusWindowAccount,
+				TextColor: walk.RGB(0x80, 0x80, 0x80), // Secondary gray color
+			},
+			Label{
+				AssignTo:  &statusWindowWarning,
+				TextColor: walk.RGB(0xD0, 0x80, 0x00), // Warning orange
+				Visible:   false,
+			},
+			Label{
+				AssignTo:  &statusWindowEvents,
+				TextColor: walk.RGB(0x80, 0x80, 0x80), // Secondary gray color
+			},
+			VSpacer{},
+			Composite{
+				Layout: HBox{MarginsZero: true, Spacing: 8},
+				Children: []Widget{
+					PushButton{
+						Text: "Preferences",
+						OnClicked: func() {
+							if err := preferences.ShowPreferencesWindow(mainWindow, tunnelManager, configManager, accountManager, trayIcon, 0); err != nil {
+								logger.Error("Failed to show preferences window: %v", err)
+							}
+						},
+					},
+					HSpacer{},
+					PushButton{
+						AssignTo: &statusWindowConnect,
+						Text:     "Connect",
+						MinSize:  Size{Width: 90, Height: 0},
+						OnClicked: func() {
+							statusWindowConnect.SetEnabled(false)
+							go toggleConnection()
+						},
+					},
+				},
+			},
+		},
+	}.Create()
+	if err != nil {
+		logger.Error("Failed to create status window: %v", err)
+		return
+	}
+
+	// Closing the window minimizes it to the taskbar; it is only removed by
+	// turning the setting off
+	mw.Closing().Attach(func(canceled *bool, reason walk.CloseReason) {
+		if !statusWindowRemoving {
+			*canceled = true
+			win.ShowWindow(mw.Handle(), win.SW_MINIMIZE)
+			return
+		}
+		statusWindow = nil
+		statusWindowState = nil
+		statusWindowAccount = nil
+		statusWindowWarning = nil
+		statusWindowEvents = nil
+		statusWindowConnect = nil
+		statusWindowIcon = ""
+	})
+
+	style := win.GetWindowLong(mw.Handle(), win.GWL_STYLE)
+	style &^= win.WS_MAXIMIZEBOX
+	win.SetWindowLong(mw.Handle(), win.GWL_STYLE, style)
+
+	palette := theme.Current()
+	theme.ApplyTitleBar(mw.Handle(), palette)
+	theme.ApplyDark(mw, palette)
+
+	statusWindow = mw
+	statusWindow.SetVisible(true)
+}
+
+// updateStatusWindow mirrors the connection state and the tray's Connect item
+// in the status window. Must be called on the UI thread.
+func updateStatusWindow() {
+	if statusWindow == nil || connectAction == nil {
+		return
+	}
+
+	var state tunnel.State
+	if tunnelManager != nil {
+		state = tunnelManager.State()
+	} else {
+		tunnelStateMutex.RLock()
+		state = tunnel.State(currentTunnelState)
+		tunnelStateMutex.RUnlock()
+	}
+
+	text := stateDisplayText(state)
+	statusWindow.SetTitle("Pangolin – " + text)
+	statusWindowState.SetText(text)
+
+	account := ""
+	if accountManager != nil {
+		if active, err := accountManager.ActiveAccount(); err == nil {
+			account = accountMenuText(active, false)
+		}
+	}
+	if authManager != nil && account != "" {
+		if org := authManager.CurrentOrg(); org != nil {
+			account += " · " + org.Name
+		}
+	}
+	if account == "" {
+		account = "Not logged in"
+	}
+	statusWindowAccount.SetText(account)
+
+	warning := ""
+	if state == tunnel.StateRunning && tunnelManager != nil {
+		if stale := tunnelManager.StalePeers(); len(stale) > 0 {
+			warning = fmt.Sprintf("⚠ %s: %s", stalePeersText(), strings.Join(stale, ", "))
+		}
+	}
+	statusWindowWarning.SetText(warning)
+	statusWindowWarning.SetVisible(warning != "")
+
+	statusWindowEvents.SetText(recentEventsText())
+
+	statusWindowConnect.SetText(connectAction.Text())
+	statusWindowConnect.SetEnabled(connectAction.Visible() && connectAction.Enabled())
+
+	iconName := "icon-gray.ico"
+	if state == tunnel.StateRunning {
+		iconName = "icon-orange.ico"
+	}
+	if iconName != statusWindowIcon {
+		if icon, err := walk.NewIconFromFile(filepath.Join(config.GetIconsPath(), iconName)); err == nil {
+			statusWindow.SetIcon(icon)
+			statusWindowIcon = iconName
+		}
+	}
+}
+
+// statusWindowEventCount is how many recent connection events the status
+// window lists
+const statusWindowEventCount = 4
+
+// recentEventsText formats the latest connection events, newest first
+func recentEventsText() string {
+	if tunnelManager == nil {
+		return ""
+	}
+	events := tunnelManager.RecentEvents()
+	var lines []string
+	for i := len(events) - 1; i >= 0 && len(lines) < statusWindowEventCount; i-- {
+		lines = append(lines, fmt.Sprintf("%s  %s", events[i].Time.Format("15:04:05"), events[i].Message))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,136 @@
+//go:build windows
+
+// Package theme picks window colors to match the Windows light or dark app theme.
+package theme
+
+import (
+	"unsafe"
+
+	"github.com/tailscale/walk"
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+// Palette holds the colors for one app theme
+type Palette struct {
+	Dark          bool
+	Background    walk.Color
+	Text          walk.Color
+	SecondaryText walk.Color
+}
+
+var (
+	lightPalette = Palette{
+		Background:    walk.RGB(0xFC, 0xFC, 0xFC), // #FCFCFC
+		Text:          walk.RGB(0x00, 0x00, 0x00),
+		SecondaryText: walk.RGB(0x80, 0x80, 0x80),
+	}
+	darkPalette = Palette{
+		Dark:          true,
+		Background:    walk.RGB(0x20, 0x20, 0x20), // #202020, the Windows dark window color
+		Text:          walk.RGB(0xF2, 0xF2, 0xF2),
+		SecondaryText: walk.RGB(0xA0, 0xA0, 0xA0),
+	}
+)
+
+// IsDarkMode detects if Windows is in dark mode
+func IsDarkMode() bool {
+	var key windows.Handle
+	keyPath := windows.StringToUTF16Ptr(`Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`)
+	err := windows.RegOpenKeyEx(windows.HKEY_CURRENT_USER, keyPath, 0, windows.KEY_READ, &key)
+	if err != nil {
+		// Default to light mode if we can't detect
+		return false
+	}
+	defer windows.RegCloseKey(key)
+
+	var value uint32
+	var valueLen uint32 = 4
+	valueName := windows.StringToUTF16Ptr("AppsUseLightTheme")
+	err = windows.RegQueryValueEx(key, valueName, nil, nil, (*byte)(unsafe.Pointer(&value)), &valueLen)
+	if err != nil {
+		// Default to light mode if we can't read the value
+		return false
+	}
+
+	// AppsUseLightTheme: 0 = dark mode, 1 = light mode
+	return value == 0
+}
+
+// Current returns the palette for the current app theme. The theme is read
+// each time so windows opened after the user switches pick up the change.
+func Current() Palette {
+	if IsDarkMode() {
+		return darkPalette
+	}
+	return lightPalette
+}
+
+// WordMarkFile returns the word mark image that is legible on the palette's background
+func (p Palette) WordMarkFile() string {
+	if p.Dark {
+		return "word_mark_white.png"
+	}
+	return "word_mark_black.png"
+}
+
+// ApplyTitleBar switches the window's title bar to match the palette
+func ApplyTitleBar(hwnd win.HWND, p Palette) {
+	var dark win.BOOL
+	if p.Dark {
+		dark = win.TRUE
+	}
+	win.DwmSetWindowAttribute(hwnd, win.DWMWA_USE_IMMERSIVE_DARK_MODE, unsafe.Pointer(&dark), uint32(unsafe.Sizeof(dark)))
+}
+
+// ApplyDark recolors a window built with the default light colors so it is
+// readable in dark mode: containers get the dark background, black label text
+// becomes light and gray label text becomes the secondary color. Labels with
+// other colors (e.g. status indicators) are left alone. It does nothing in
+// light mode, and can be called again for widgets created later.
+func ApplyDark(w walk.Window, p Palette) {
+	if !p.Dark {
+		return
+	}
+	brush, err := walk.NewSolidColorBrush(p.Background)
+	if err != nil {
+		return
+	}
+	applyDark(w, p, brush)
+}
+
+func applyDark(w walk.Window, p Palette, brush walk.Brush) {
+	switch widget := w.(type) {
+	case *walk.Label:
+		widget.SetTextColor(darkTextColor(widget.TextColor(), p))
+		return
+	case *walk.TextLabel:
+		widget.SetTextColor(darkTextColor(widget.TextColor(), p))
+		return
+	case *walk.TabWidget:
+		pages := widget.Pages()
+		for i := 0; i < pages.Len(); i++ {
+			applyDark(pages.At(i), p, brush)
+		}
+		return
+	}
+
+	if container, ok := w.(walk.Container); ok {
+		container.SetBackground(brush)
+		children := container.Children()
+		for i := 0; i < children.Len(); i++ {
+			applyDark(children.At(i), p, brush)
+		}
+	}
+}
+
+// darkTextColor maps a light-theme label color to its dark-theme equivalent
+func darkTextColor(c walk.Color, p Palette) walk.Color {
+	if c == lightPalette.Text {
+		return p.Text
+	}
+	if c.R() == c.G() && c.G() == c.B() {
+		return p.SecondaryText
+	}
+	return c
+}
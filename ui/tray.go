@@ -5,8 +5,11 @@ package ui
 import (
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/url"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +22,7 @@ import (
 	"github.com/fosrl/windows/secrets"
 	"github.com/fosrl/windows/tunnel"
 	"github.com/fosrl/windows/ui/preferences"
+	"github.com/fosrl/windows/ui/theme"
 	"github.com/fosrl/windows/updater"
 	"github.com/fosrl/windows/version"
 
@@ -28,6 +32,18 @@ import (
 	"github.com/tailscale/win"
 )
 
+// sessionCheckInterval is how often the session token is verified in the background
+const sessionCheckInterval = 10 * time.Minute
+
+const (
+	// backgroundRefreshIdlePoll is how often the background refresh setting is
+	// rechecked while background refresh is off
+	backgroundRefreshIdlePoll = time.Minute
+	// backgroundRefreshJitter spreads background refreshes from many clients
+	// so they do not all hit the server at once
+	backgroundRefreshJitter = 15 * time.Second
+)
+
 var (
 	trayIcon               *walk.NotifyIcon
 	contextMenu            *walk.Menu
@@ -41,19 +57,24 @@ var (
 	statusAction           *walk.Action
 	reAuthLoginAction      *walk.Action
 	connectAction          *walk.Action
+	reconnectAction        *walk.Action
 	orgsMenuAction         *walk.Action
 	accountMenuAction      *walk.Action
 	loginAction            *walk.Action
 	logoutAction           *walk.Action
 	addAccountAction       *walk.Action
+	manageAccountsAction   *walk.Action
 	moreAction             *walk.Action
 	quitAction             *walk.Action
 	serverDownAction       *walk.Action
+	sessionExpiredAction   *walk.Action
 	errorMessageAction     *walk.Action
 	watermarkAction        *walk.Action
 	updateFoundCb          *managers.UpdateFoundCallback
 	updateProgressCb       *managers.UpdateProgressCallback
 	managerStoppingCb      *managers.ManagerStoppingCallback
+	systemResumedCb        *managers.SystemResumedCallback
+	eventsClosedCb         *managers.EventsClosedCallback
 	isConnected            bool
 	connectMutex           sync.RWMutex
 	isLoggedOut            bool
@@ -64,13 +85,23 @@ var (
 	configManager          *config.ConfigManager
 	accountManager         *config.AccountManager
 	apiClient              *api.APIClient
+	secretManager          *secrets.SecretManager
 	tunnelManager          *tunnel.Manager
 	orgMenu                *walk.Menu
 	accountMenu            *walk.Menu
 	moreMenu               *walk.Menu
 	orgActions             map[string]*walk.Action
+	connectOrgMenu         *walk.Menu
+	connectOrgMenuAction   *walk.Action
+	connectOrgActions      map[string]*walk.Action
 	accountActions         map[string]*walk.Action
 	noOrgsAction           *walk.Action
+	orgSearchAction        *walk.Action
+	autoConnectOrgAction   *walk.Action
+	copySupportIDAction    *walk.Action
+	saveSessionAction      *walk.Action
+	sessionNotSavedShown   bool
+	versionWarningShown    string
 	noAccountsAction       *walk.Action
 	menuUpdateMutex        sync.Mutex
 	cliInstallAction       *walk.Action
@@ -78,8 +109,14 @@ var (
 	cliInstalledMutex      sync.RWMutex
 	cliInstallInProgress   bool
 	cliInstallInProgressM  sync.Mutex
+	refreshAccountAction   *walk.Action
+	sessionRotatedAction   *walk.Action
+	accountRefreshing      bool
+	accountRefreshingM     sync.Mutex
 	appUpdateProgressClose func()
 	appUpdateProgressLabel *walk.TextLabel
+	appUpdateProgressBar   *walk.ProgressBar
+	appUpdateCancelButton  *walk.PushButton
 )
 
 // updateTrayTooltip updates the tray icon tooltip to show the current tunnel state
@@ -88,13 +125,61 @@ func updateTrayTooltip(state tunnel.State) {
 		return
 	}
 
-	stateText := state.DisplayText()
-	tooltipText := fmt.Sprintf("%s: %s", config.AppName, stateText)
+	tooltipText := fmt.Sprintf("%s: %s", config.AppName, stateDisplayText(state))
+	if state == tunnel.StateRunning {
+		if note := stalePeersText(); note != "" {
+			tooltipText += "\n" + note
+		}
+	}
 	if err := trayIcon.SetToolTip(tooltipText); err != nil {
 		logger.Error("Failed to set tray tooltip: %v", err)
 	}
 }
 
+// stalePeersText returns a short note such as "1 site unreachable" when sites
+// stopped answering while the tunnel is running, or "" if all are reachable
+func stalePeersText() string {
+	if tunnelManager == nil {
+		return ""
+	}
+	stale := tunnelManager.StalePeers()
+	switch len(stale) {
+	case 0:
+		return ""
+	case 1:
+		return "1 site unreachable"
+	default:
+		return fmt.Sprintf("%d sites unreachable", len(stale))
+	}
+}
+
+// activeAccountServer returns the host of the server used by the active account, or "" if unknown
+func activeAccountServer() string {
+	if accountManager == nil {
+		return ""
+	}
+	activeAccount, err := accountManager.ActiveAccount()
+	if err != nil || activeAccount == nil || activeAccount.Hostname == "" {
+		return ""
+	}
+	hostname := strings.TrimSpace(activeAccount.Hostname)
+	if u, err := url.Parse(hostname); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return strings.TrimRight(hostname, "/")
+}
+
+// stateDisplayText returns the display text for a tunnel state, naming the
+// server when connected so multi-server setups are unambiguous
+func stateDisplayText(state tunnel.State) string {
+	if state == tunnel.StateRunning {
+		if server := activeAccountServer(); server != "" {
+			return fmt.Sprintf("Connected to %s", server)
+		}
+	}
+	return state.DisplayText()
+}
+
 // setTrayIconForState sets the tray icon based on tunnel state, with overlay for transitional states
 func setTrayIconForState(state tunnel.State) {
 	if trayIcon == nil {
@@ -144,11 +229,152 @@ func setTrayIconForState(state tunnel.State) {
 	}
 }
 
+// showConnectionErrorDialog shows an error dialog for a tunnel operation,
+// using the formatted title/message when err is a ConnectionError
+func showConnectionErrorDialog(err error, fallbackTitle string) {
+	// The user started or cancelled a connection while a retry was pending
+	if errors.Is(err, tunnel.ErrConnectRetryCancelled) {
+		return
+	}
+
+	// Users without any organization need to create or join one, not pick one
+	var noOrgErr *tunnel.ConnectionError
+	if errors.As(err, &noOrgErr) && noOrgErr.Code == tunnel.ConnectionErrorNoOrganization && authManager != nil && authManager.HasNoOrganizations() {
+		showNoOrganizationsDialog()
+		return
+	}
+
+	walk.App().Synchronize(func() {
+		var title, message string
+
+		// Check if it's a ConnectionError with formatted title/message
+		if connErr, ok := err.(*tunnel.ConnectionError); ok {
+			title = connErr.Title
+			message = connErr.Message
+		} else {
+			// Fallback to generic error
+			title = fallbackTitle
+			message = err.Error()
+		}
+
+		opts := walk.TaskDialogOpts{
+			Owner:         mainWindow,
+			Title:         title,
+			Content:       message,
+			IconSystem:    walk.TaskDialogSystemIconError,
+			CommonButtons: win.TDCBF_OK_BUTTON,
+		}
+
+		// Missing device credentials can be fixed by registering the device again
+		var connErr *tunnel.ConnectionError
+		reregister := false
+		if errors.As(err, &connErr) && connErr.Code == tunnel.ConnectionErrorCredentialsMissing {
+			opts.CommonButtons = win.TDCBF_CLOSE_BUTTON
+			opts.CustomButtons = []walk.TaskDialogCustomButton{{MainText: "Re-register Device", Default: true}}
+			opts.CustomButtons[0].Clicked().Attach(func() bool {
+				reregister = true
+				return false
+			})
+		}
+
+		td := walk.NewTaskDialog()
+		_, _ = td.Show(opts)
+
+		if reregister {
+			go reregisterDevice()
+		}
+	})
+}
+
+// sessionNotSavedMessage explains why the login is only kept until restart
+func sessionNotSavedMessage() string {
+	if secretManager != nil && secrets.IsLogonSessionError(secretManager.LastSaveError()) {
+		return "Windows has no interactive logon session for your account, so your login is kept only until Pangolin restarts. Unlock Windows, then choose Retry Saving Login."
+	}
+	return "Your login could not be stored securely and is kept only until Pangolin restarts. Choose Retry Saving Login to try again."
+}
+
+// retrySaveSession tries again to store login tokens kept in memory
+func retrySaveSession() {
+	if secretManager == nil {
+		return
+	}
+	saved := secretManager.PersistSessionTokens()
+	walk.App().Synchronize(func() {
+		if saved {
+			if trayIcon != nil {
+				trayIcon.ShowInfo("Login Saved", "Your login has been stored and will be kept after restart.")
+			}
+			return
+		}
+		td := walk.NewTaskDialog()
+		_, _ = td.Show(walk.TaskDialogOpts{
+			Owner:         mainWindow,
+			Title:         "Login Not Saved",
+			Content:       sessionNotSavedMessage(),
+			IconSystem:    walk.TaskDialogSystemIconWarning,
+			CommonButtons: win.TDCBF_OK_BUTTON,
+		})
+	})
+	updateMenu()
+}
+
+// reregisterDevice replaces the current user's OLM credentials and connects again
+func reregisterDevice() {
+	if authManager == nil || tunnelManager == nil {
+		return
+	}
+	var userID string
+	if user := authManager.CurrentUser(); user != nil {
+		userID = user.UserId
+	} else if account, err := accountManager.ActiveAccount(); err == nil {
+		userID = account.UserID
+	}
+	if userID == "" {
+		return
+	}
+
+	if err := authManager.RegisterNewOlmCredentials(userID); err != nil {
+		logger.Error("Failed to re-register device: %v", err)
+		showConnectionErrorDialog(fmt.Errorf("failed to re-register device: %w", err), "Re-register Failed")
+		return
+	}
+	if err := tunnelManager.ConnectWithRetry(); err != nil {
+		logger.Error("Failed to start tunnel after re-registering device: %v", err)
+		showConnectionErrorDialog(err, "Connection Failed")
+	}
+}
+
 // openURL opens a URL in the default browser
 func openURL(url string) {
 	browser.OpenURL(url)
 }
 
+var (
+	rateLimitedUntil time.Time
+	rateLimitedMutex sync.Mutex
+)
+
+// backOffIfRateLimited pauses background refreshes after a 429, for as long as
+// the server's Retry-After header asked or api.DefaultRetryAfter
+func backOffIfRateLimited(err error) {
+	wait, ok := api.RetryAfter(err)
+	if !ok {
+		return
+	}
+	logger.Warn("Server rate limit hit, pausing background refreshes for %s", wait)
+	rateLimitedMutex.Lock()
+	rateLimitedUntil = time.Now().Add(wait)
+	rateLimitedMutex.Unlock()
+}
+
+// isRateLimited returns whether background refreshes are paused after a 429
+func isRateLimited() bool {
+	rateLimitedMutex.Lock()
+	defer rateLimitedMutex.Unlock()
+	return time.Now().Before(rateLimitedUntil)
+}
+
 // handleMenuOpen verifies session and refreshes organizations when menu opens
 func handleMenuOpen() {
 	if authManager == nil || apiClient == nil {
@@ -160,6 +386,11 @@ func handleMenuOpen() {
 		return
 	}
 
+	// Don't refresh again until the server's rate limit has passed
+	if isRateLimited() {
+		return
+	}
+
 	// Run in background goroutine to avoid blocking menu
 	go func() {
 		// Health check before fetching user data
@@ -178,6 +409,11 @@ func handleMenuOpen() {
 		// First, try to get the user to verify session is still valid
 		user, err := apiClient.GetUser()
 		if err != nil {
+			if _, limited := api.RetryAfter(err); limited {
+				// Rate limited, not logged out; keep the current state and back off
+				backOffIfRateLimited(err)
+				return
+			}
 			// 401/403: API callback already set sessionExpired; do not set isLoggedOut so we show "Account Locked" + "Log In"
 			var apiErr *api.APIError
 			if !(errors.As(err, &apiErr) && (apiErr.Status == 401 || apiErr.Status == 403)) {
@@ -219,6 +455,7 @@ func handleMenuOpen() {
 		if authManager.IsAuthenticated() {
 			if err := authManager.RefreshOrganizations(); err != nil {
 				logger.Error("Failed to refresh organizations: %v", err)
+				backOffIfRateLimited(err)
 			} else {
 				// Update menu again after orgs refresh
 				updateMenu()
@@ -227,6 +464,124 @@ func handleMenuOpen() {
 	}()
 }
 
+// toggleConnection connects when the tunnel is stopped and otherwise
+// disconnects, cancelling a connection attempt in progress
+func toggleConnection() {
+	if tunnelManager == nil {
+		logger.Error("Tunnel manager not initialized")
+		// Show error dialog to user
+		walk.App().Synchronize(func() {
+			td := walk.NewTaskDialog()
+			_, _ = td.Show(walk.TaskDialogOpts{
+				Owner:         mainWindow,
+				Title:         "Connection Error",
+				Content:       "Tunnel manager is not initialized. Please restart the application.",
+				IconSystem:    walk.TaskDialogSystemIconError,
+				CommonButtons: win.TDCBF_OK_BUTTON,
+			})
+		})
+		return
+	}
+
+	// Get current state to determine action
+	currentState := tunnelManager.State()
+
+	// Allow disconnect for any state other than Stopped or Stopping
+	// This allows users to cancel the connection process at any time
+	if currentState != tunnel.StateStopped && currentState != tunnel.StateStopping {
+		// Disconnect (or cancel connection)
+		logger.Info("Disconnecting...")
+		err := tunnelManager.Disconnect()
+		if err != nil {
+			logger.Error("Failed to stop tunnel: %v", err)
+			// Show error dialog to user
+			showConnectionErrorDialog(err, "Disconnect Failed")
+		}
+	} else if currentState == tunnel.StateStopped {
+		// Connect
+		// If enabled, open preferences immediately on the Status tab,
+		// but before starting the tunnel.
+		if configManager != nil && configManager.GetOpenStatusTabOnConnect() {
+			walk.App().Synchronize(func() {
+				if err := preferences.ShowPreferencesWindow(mainWindow, tunnelManager, configManager, accountManager, trayIcon, 1); err != nil {
+					logger.Error("Failed to show preferences window: %v", err)
+					td := walk.NewTaskDialog()
+					_, _ = td.Show(walk.TaskDialogOpts{
+						Owner:         mainWindow,
+						Title:         "Error",
+						Content:       fmt.Sprintf("Failed to open preferences window: %v", err),
+						IconSystem:    walk.TaskDialogSystemIconError,
+						CommonButtons: win.TDCBF_OK_BUTTON,
+					})
+				}
+			})
+		}
+
+		err := tunnelManager.ConnectWithRetry()
+		if err != nil {
+			logger.Error("Failed to start tunnel: %v", err)
+			// Show error dialog to user
+			showConnectionErrorDialog(err, "Connection Failed")
+		}
+	}
+	// If state is Stopping, do nothing (button should be disabled)
+}
+
+// quitApp exits the tray app. Tunnels are stopped first unless the user chose
+// to keep them running, in which case they confirm that the tunnel stays up.
+func quitApp() {
+	connected := tunnelManager != nil && tunnelManager.State() != tunnel.StateStopped
+	if configManager != nil && configManager.GetKeepTunnelOnExit() {
+		if connected {
+			td := walk.NewTaskDialog()
+			result, _ := td.Show(walk.TaskDialogOpts{
+				Owner:         mainWindow,
+				Title:         "Quit Pangolin",
+				Instruction:   "Your tunnel will stay connected",
+				Content:       "Pangolin will close, but the tunnel keeps running in the background. Open Pangolin again to disconnect.\n\nYou can change this in Preferences.",
+				IconSystem:    walk.TaskDialogSystemIconInformation,
+				CommonButtons: win.TDCBF_OK_BUTTON | win.TDCBF_CANCEL_BUTTON,
+			})
+			if result.Canceled {
+				return
+			}
+		}
+		logger.Info("Quitting and leaving the tunnel running")
+		walk.App().Exit(0)
+		return
+	}
+
+	if tunnelManager != nil && tunnelManager.State() == tunnel.StateRunning &&
+		configManager != nil && configManager.GetConfirmQuitWhileConnected() {
+		quit := false
+		opts := walk.TaskDialogOpts{
+			Owner:            mainWindow,
+			Title:            "Quit Pangolin",
+			Instruction:      "A tunnel is active. Quit and disconnect?",
+			Content:          "Quitting disconnects the tunnel, interrupting any connections to your resources.",
+			IconSystem:       walk.TaskDialogSystemIconWarning,
+			CommonButtons:    win.TDCBF_CANCEL_BUTTON,
+			CustomButtons:    []walk.TaskDialogCustomButton{{MainText: "Quit and Disconnect", Default: true}},
+			VerificationText: "Don't ask again",
+		}
+		opts.CustomButtons[0].Clicked().Attach(func() bool {
+			quit = true
+			return false
+		})
+		td := walk.NewTaskDialog()
+		result, _ := td.Show(opts)
+		if !quit {
+			return
+		}
+		if result.Checked != nil && *result.Checked {
+			configManager.SetConfirmQuitWhileConnected(false)
+		}
+	}
+
+	_ = managers.IPCClientStopAllTunnels() // stop tunnels before exiting; ignore errors (e.g. no manager connection)
+	walk.App().Exit(0)
+}
+
 // setupMenu creates the menu structure once
 func setupMenu() error {
 	if contextMenu == nil {
@@ -257,6 +612,16 @@ func setupMenu() error {
 	serverDownAction.SetVisible(false)
 	actions.Add(serverDownAction)
 
+	// Create session expired banner (initially hidden)
+	sessionExpiredAction = walk.NewAction()
+	sessionExpiredAction.SetText("Session expired – please log in again")
+	sessionExpiredAction.SetEnabled(false)
+	sessionExpiredAction.SetVisible(false)
+	actions.Add(sessionExpiredAction)
+
+	// Manager service health actions (initially hidden)
+	addManagerHealthActions(actions)
+
 	// Create error message action (initially hidden)
 	errorMessageAction = walk.NewAction()
 	errorMessageAction.SetEnabled(false)
@@ -289,110 +654,27 @@ func setupMenu() error {
 	connectAction.SetText("Connect")
 	connectAction.SetVisible(false) // Hidden initially
 	connectAction.Triggered().Attach(func() {
+		go toggleConnection()
+	})
+	actions.Add(connectAction)
+
+	// Create reconnect action (shown only while connected)
+	reconnectAction = walk.NewAction()
+	reconnectAction.SetText("Reconnect")
+	reconnectAction.SetVisible(false) // Hidden initially
+	reconnectAction.Triggered().Attach(func() {
 		go func() {
 			if tunnelManager == nil {
 				logger.Error("Tunnel manager not initialized")
-				// Show error dialog to user
-				walk.App().Synchronize(func() {
-					td := walk.NewTaskDialog()
-					_, _ = td.Show(walk.TaskDialogOpts{
-						Owner:         mainWindow,
-						Title:         "Connection Error",
-						Content:       "Tunnel manager is not initialized. Please restart the application.",
-						IconSystem:    walk.TaskDialogSystemIconError,
-						CommonButtons: win.TDCBF_OK_BUTTON,
-					})
-				})
 				return
 			}
-
-			// Get current state to determine action
-			currentState := tunnelManager.State()
-
-			// Allow disconnect for any state other than Stopped or Stopping
-			// This allows users to cancel the connection process at any time
-			if currentState != tunnel.StateStopped && currentState != tunnel.StateStopping {
-				// Disconnect (or cancel connection)
-				logger.Info("Disconnecting...")
-				err := tunnelManager.Disconnect()
-				if err != nil {
-					logger.Error("Failed to stop tunnel: %v", err)
-					// Show error dialog to user
-					walk.App().Synchronize(func() {
-						var title, message string
-
-						// Check if it's a ConnectionError with formatted title/message
-						if connErr, ok := err.(*tunnel.ConnectionError); ok {
-							title = connErr.Title
-							message = connErr.Message
-						} else {
-							// Fallback to generic error
-							title = "Disconnect Failed"
-							message = err.Error()
-						}
-
-						td := walk.NewTaskDialog()
-						_, _ = td.Show(walk.TaskDialogOpts{
-							Owner:         mainWindow,
-							Title:         title,
-							Content:       message,
-							IconSystem:    walk.TaskDialogSystemIconError,
-							CommonButtons: win.TDCBF_OK_BUTTON,
-						})
-					})
-				}
-			} else if currentState == tunnel.StateStopped {
-				// Connect
-				// If enabled, open preferences immediately on the Status tab,
-				// but before starting the tunnel.
-				if configManager != nil && configManager.GetOpenStatusTabOnConnect() {
-					walk.App().Synchronize(func() {
-						if err := preferences.ShowPreferencesWindow(mainWindow, tunnelManager, configManager, trayIcon, 1); err != nil {
-							logger.Error("Failed to show preferences window: %v", err)
-							td := walk.NewTaskDialog()
-							_, _ = td.Show(walk.TaskDialogOpts{
-								Owner:         mainWindow,
-								Title:         "Error",
-								Content:       fmt.Sprintf("Failed to open preferences window: %v", err),
-								IconSystem:    walk.TaskDialogSystemIconError,
-								CommonButtons: win.TDCBF_OK_BUTTON,
-							})
-						}
-					})
-				}
-
-				err := tunnelManager.Connect()
-				if err != nil {
-					logger.Error("Failed to start tunnel: %v", err)
-					// Show error dialog to user
-					walk.App().Synchronize(func() {
-						var title, message string
-
-						// Check if it's a ConnectionError with formatted title/message
-						if connErr, ok := err.(*tunnel.ConnectionError); ok {
-							title = connErr.Title
-							message = connErr.Message
-						} else {
-							// Fallback to generic error
-							title = "Connection Failed"
-							message = err.Error()
-						}
-
-						td := walk.NewTaskDialog()
-						_, _ = td.Show(walk.TaskDialogOpts{
-							Owner:         mainWindow,
-							Title:         title,
-							Content:       message,
-							IconSystem:    walk.TaskDialogSystemIconError,
-							CommonButtons: win.TDCBF_OK_BUTTON,
-						})
-					})
-				}
+			if err := tunnelManager.Reconnect(); err != nil {
+				logger.Error("Failed to reconnect tunnel: %v", err)
+				showConnectionErrorDialog(err, "Reconnect Failed")
 			}
-			// If state is Stopping, do nothing (button should be disabled)
 		}()
 	})
-	actions.Add(connectAction)
+	actions.Add(reconnectAction)
 
 	actions.Add(walk.NewSeparatorAction())
 
@@ -419,6 +701,21 @@ func setupMenu() error {
 	orgsMenuAction.SetVisible(false) // Hidden initially
 	actions.Add(orgsMenuAction)
 
+	// Create refresh action to pick up organization changes right away
+	refreshAccountAction = walk.NewAction()
+	refreshAccountAction.SetText("Refresh Account")
+	refreshAccountAction.SetVisible(false) // Hidden initially
+	refreshAccountAction.Triggered().Attach(func() {
+		go refreshAccountNow()
+	})
+	actions.Add(refreshAccountAction)
+
+	// Informational item shown once the server has rotated the session token
+	sessionRotatedAction = walk.NewAction()
+	sessionRotatedAction.SetEnabled(false)
+	sessionRotatedAction.SetVisible(false) // Hidden initially
+	actions.Add(sessionRotatedAction)
+
 	// Separator before login
 	actions.Add(walk.NewSeparatorAction())
 
@@ -463,6 +760,13 @@ func setupMenu() error {
 	})
 	moreMenu.Actions().Add(docAction)
 
+	reportProblemAction := walk.NewAction()
+	reportProblemAction.SetText("Report a Problem…")
+	reportProblemAction.Triggered().Attach(func() {
+		openURL(reportProblemURL())
+	})
+	moreMenu.Actions().Add(reportProblemAction)
+
 	moreMenu.Actions().Add(walk.NewSeparatorAction())
 
 	// Copyright
@@ -549,6 +853,47 @@ func setupMenu() error {
 	})
 	moreMenu.Actions().Add(checkUpdateAction)
 
+	exportStatusAction := walk.NewAction()
+	exportStatusAction.SetText("Copy Connection Status")
+	exportStatusAction.Triggered().Attach(func() {
+		go exportConnectionStatus()
+	})
+	moreMenu.Actions().Add(exportStatusAction)
+
+	copySupportIDAction = walk.NewAction()
+	copySupportIDAction.SetText("Copy Support ID")
+	copySupportIDAction.Triggered().Attach(func() {
+		copySupportID()
+	})
+	moreMenu.Actions().Add(copySupportIDAction)
+
+	saveSessionAction = walk.NewAction()
+	saveSessionAction.SetText("Retry Saving Login")
+	saveSessionAction.SetVisible(false)
+	saveSessionAction.Triggered().Attach(func() {
+		go retrySaveSession()
+	})
+	moreMenu.Actions().Add(saveSessionAction)
+
+	fingerprintAction := walk.NewAction()
+	fingerprintAction.SetText("Show Device Fingerprint…")
+	fingerprintAction.Triggered().Attach(func() {
+		go showDeviceFingerprint()
+	})
+	moreMenu.Actions().Add(fingerprintAction)
+
+	exportDiagnosticsAction := walk.NewAction()
+	exportDiagnosticsAction.SetText("Export Diagnostics…")
+	exportDiagnosticsAction.Triggered().Attach(func() {
+		exportDiagnosticsBundle()
+	})
+	moreMenu.Actions().Add(exportDiagnosticsAction)
+
+	reloadConfigAction := walk.NewAction()
+	reloadConfigAction.SetText("Reload Configuration")
+	reloadConfigAction.Triggered().Attach(reloadConfiguration)
+	moreMenu.Actions().Add(reloadConfigAction)
+
 	installCLIAction := walk.NewAction()
 	installCLIAction.SetText("Install Pangolin CLI")
 	installCLIAction.SetVisible(false)
@@ -577,7 +922,7 @@ func setupMenu() error {
 						})
 					}
 				}()
-				if err := preferences.ShowPreferencesWindow(mainWindow, tunnelManager, configManager, trayIcon, 0); err != nil {
+				if err := preferences.ShowPreferencesWindow(mainWindow, tunnelManager, configManager, accountManager, trayIcon, 0); err != nil {
 					logger.Error("Failed to show preferences window: %v", err)
 					td := walk.NewTaskDialog()
 					_, _ = td.Show(walk.TaskDialogOpts{
@@ -612,14 +957,12 @@ func setupMenu() error {
 	// Create quit action — stops any active tunnels via manager, then closes the UI process; manager service keeps running
 	quitAction = walk.NewAction()
 	quitAction.SetText("Quit")
-	quitAction.Triggered().Attach(func() {
-		_ = managers.IPCClientStopAllTunnels() // stop tunnels before exiting; ignore errors (e.g. no manager connection)
-		walk.App().Exit(0)
-	})
+	quitAction.Triggered().Attach(quitApp)
 	actions.Add(quitAction)
 
 	// Initialize org actions map
 	orgActions = make(map[string]*walk.Action)
+	connectOrgActions = make(map[string]*walk.Action)
 	accountActions = make(map[string]*walk.Action)
 
 	// Initial update to set correct visibility and text
@@ -672,6 +1015,31 @@ func updateMenu() {
 			}
 		}
 
+		// Offer to store a login that is only kept in memory
+		sessionPersistent := secretManager == nil || secretManager.IsPersistent()
+		if saveSessionAction != nil {
+			saveSessionAction.SetVisible(!sessionPersistent)
+		}
+		if !sessionPersistent && !sessionNotSavedShown && trayIcon != nil {
+			trayIcon.ShowWarning("Login Not Saved", sessionNotSavedMessage())
+		}
+		sessionNotSavedShown = !sessionPersistent
+
+		// Warn once per server about an unsupported version; the balloon can be dismissed
+		serverVersionWarning := ""
+		if authManager != nil && isAuthenticated {
+			serverVersionWarning = authManager.ServerVersionWarning()
+		}
+		if serverVersionWarning != "" && serverVersionWarning != versionWarningShown && trayIcon != nil {
+			trayIcon.ShowWarning("Unsupported Server Version", serverVersionWarning)
+		}
+		versionWarningShown = serverVersionWarning
+
+		// The support ID needs a signed-in user
+		if copySupportIDAction != nil {
+			copySupportIDAction.SetEnabled(isAuthenticated)
+		}
+
 		// Update loading state
 		if loadingAction != nil {
 			loadingAction.SetVisible(isInitializing)
@@ -684,8 +1052,16 @@ func updateMenu() {
 		hasErrorMessage := errorMessage != nil && *errorMessage != "" && !isServerDown && !sessionExpired
 
 		if serverDownAction != nil {
+			// The server down message says why when known, e.g. maintenance
+			if isServerDown && errorMessage != nil && *errorMessage != "" {
+				serverDownAction.SetText(*errorMessage)
+			}
 			serverDownAction.SetVisible(isAuthenticated && isServerDown && !isInitializing)
 		}
+		if sessionExpiredAction != nil {
+			// Explain why Log In is offered instead of silently switching the menu
+			sessionExpiredAction.SetVisible(hasLocalUserInfo && (sessionExpired || isLoggedOutLocal) && !isServerDown && !isInitializing)
+		}
 		if errorMessageAction != nil {
 			if hasErrorMessage && isAuthenticated && !isInitializing {
 				errorMessageAction.SetText(*errorMessage)
@@ -748,6 +1124,9 @@ func updateMenu() {
 		if connectAction != nil {
 			connectAction.SetVisible(showAuthSection && !sessionExpired)
 		}
+		if reconnectAction != nil {
+			reconnectAction.SetVisible(false) // Shown by updateTunnelState while connected
+		}
 		if reAuthLoginAction != nil {
 			reAuthLoginAction.SetVisible(showAuthSection && sessionExpired)
 			reAuthLoginAction.SetEnabled(authManager == nil || !authManager.IsDeviceAuthInProgress())
@@ -795,6 +1174,31 @@ func updateMenu() {
 				cliInstallAction.SetText("Install Pangolin CLI")
 			}
 		}
+
+		accountRefreshingM.Lock()
+		accountRefreshRunning := accountRefreshing
+		accountRefreshingM.Unlock()
+		if refreshAccountAction != nil {
+			refreshAccountAction.SetVisible(isAuthenticated && !isInitializing)
+			refreshAccountAction.SetEnabled(!accountRefreshRunning)
+			if accountRefreshRunning {
+				refreshAccountAction.SetText("Refreshing…")
+			} else {
+				refreshAccountAction.SetText("Refresh Account")
+			}
+		}
+		if sessionRotatedAction != nil {
+			var rotatedAt time.Time
+			if authManager != nil {
+				rotatedAt = authManager.SessionRotatedAt()
+			}
+			sessionRotatedAction.SetVisible(isAuthenticated && !rotatedAt.IsZero())
+			if !rotatedAt.IsZero() {
+				sessionRotatedAction.SetText(fmt.Sprintf("Session renewed at %s", rotatedAt.Format("15:04")))
+			}
+		}
+
+		updateStatusWindow()
 	})
 }
 
@@ -827,7 +1231,7 @@ func updateTunnelState() {
 		tunnelStateMutex.RUnlock()
 	}
 
-	statusAction.SetText(state.DisplayText())
+	statusAction.SetText(stateDisplayText(state))
 
 	var connected bool
 	if tunnelManager != nil {
@@ -854,6 +1258,27 @@ func updateTunnelState() {
 	connectAction.SetText(connectText)
 	// Set checked state based on whether we're connected or in a connecting state
 	connectAction.SetChecked(state == tunnel.StateRunning || connected)
+
+	if reconnectAction != nil {
+		reconnectAction.SetVisible(state == tunnel.StateRunning || state == tunnel.StateReconnecting)
+	}
+}
+
+// accountMenuText returns the account menu label: the display name followed
+// by the email when both are known, plus the hostname when several accounts
+// share the same email.
+func accountMenuText(account *config.Account, showHostname bool) string {
+	if account.Label != "" {
+		return account.Label
+	}
+	text := auth.AccountDisplayName(account)
+	if account.Name != "" && account.Email != "" && account.Name != account.Email {
+		text = fmt.Sprintf("%s (%s)", account.Name, account.Email)
+	}
+	if showHostname {
+		text = fmt.Sprintf("%s (%s)", text, account.Hostname)
+	}
+	return text
 }
 
 func updateAccountMenu() {
@@ -861,7 +1286,7 @@ func updateAccountMenu() {
 		return
 	}
 
-	accounts := accountManager.Accounts
+	accounts := accountManager.OrderedAccounts()
 	currentAccount, _ := accountManager.ActiveAccount()
 
 	var state tunnel.State
@@ -873,6 +1298,7 @@ func updateAccountMenu() {
 		tunnelStateMutex.RUnlock()
 	}
 	shouldDisable := state == tunnel.StateStarting || state == tunnel.StateRegistering || state == tunnel.StateRegistered || state == tunnel.StateStopping
+	switchingAccount := authManager != nil && authManager.IsSwitchingAccount()
 
 	actions := accountMenu.Actions()
 	hasMenuTitle := false
@@ -942,25 +1368,20 @@ func updateAccountMenu() {
 		emailCounts[account.Email]++
 	}
 
-	// Update or add orgs
-	for _, account := range accounts {
+	// Update or add accounts, in the user's chosen order
+	for i, account := range accounts {
 		action, exists := accountActions[account.UserID]
 		if !exists {
 			// Create new action
 			action = walk.NewAction()
-
-			displayName := auth.AccountDisplayName(&account)
-			var accountText string
-			if emailCounts[account.Email] > 1 {
-				accountText = fmt.Sprintf("%s (%s)", displayName, account.Hostname)
-			} else {
-				accountText = displayName
-			}
-
-			action.SetText(accountText)
 			action.SetCheckable(true)
-
 			action.Triggered().Attach(func() {
+				// Block further switches until this one finishes; updateMenu
+				// re-enables the items once SwitchAccount returns.
+				for _, accountAction := range accountActions {
+					accountAction.SetEnabled(false)
+				}
+
 				go func() {
 					account := account
 
@@ -1001,28 +1422,36 @@ func updateAccountMenu() {
 						return
 					}
 
+					// DNS settings can differ per account
+					preferences.ReloadAccountSettings()
 					updateMenu()
 				}()
 			})
 			accountActions[account.UserID] = action
+		}
 
-			// Insert after separator (index 2: count label at 0, separator at 1)
-			actions.Insert(2, action)
-		} else {
-			// Update existing action
-			displayName := auth.AccountDisplayName(&account)
-			var accountText string
-			if emailCounts[account.Email] > 1 {
-				accountText = fmt.Sprintf("%s (%s)", displayName, account.Hostname)
-			} else {
-				accountText = displayName
+		// Keep the items in order after the title and separator (indexes 0 and 1)
+		if actions.Index(action) != 2+i {
+			if exists {
+				actions.Remove(action)
+			}
+			actions.Insert(2+i, action)
+		}
+
+		// Prefer the freshest user info for the active account
+		if currentAccount != nil && account.UserID == currentAccount.UserID {
+			if user := authManager.CurrentUser(); user != nil && user.Name != nil && *user.Name != "" {
+				account.Name = *user.Name
 			}
-			action.SetText(accountText)
+		}
+		action.SetText(accountMenuText(&account, emailCounts[account.Email] > 1))
+		if icon, err := initialsIcon(account.UserID, auth.AccountInitials(&account), 16); err == nil {
+			action.SetImage(icon)
 		}
 
 		// Update checked state
 		action.SetChecked(currentAccount != nil && account.UserID == currentAccount.UserID)
-		action.SetEnabled(!shouldDisable)
+		action.SetEnabled(!shouldDisable && !switchingAccount)
 	}
 
 	if addAccountAction == nil {
@@ -1041,8 +1470,14 @@ func updateAccountMenu() {
 			}()
 		})
 		actions.Add(addAccountAction)
+
+		manageAccountsAction = walk.NewAction()
+		manageAccountsAction.SetText("Manage Accounts…")
+		manageAccountsAction.Triggered().Attach(showManageAccountsDialog)
+		actions.Add(manageAccountsAction)
 	}
 	addAccountAction.SetVisible(true)
+	manageAccountsAction.SetVisible(len(accounts) > 1)
 
 	// Create logout action
 	if logoutAction == nil {
@@ -1051,9 +1486,10 @@ func updateAccountMenu() {
 		logoutAction.SetVisible(false) // Initially hidden
 		logoutAction.Triggered().Attach(func() {
 			go func() {
-				// Always stop any running tunnel before logout
-				logger.Info("Stopping tunnel before logout")
-				if err := managers.IPCClientStopTunnel(); err != nil {
+				// Always stop every tunnel before logout so none keeps running
+				// with the logged-out account's credentials
+				logger.Info("Stopping tunnels before logout")
+				if err := managers.IPCClientStopAllTunnels(); err != nil {
 					logger.Error("Failed to stop tunnel before logout: %v", err)
 					// Continue with logout even if stopping tunnel fails
 				}
@@ -1088,6 +1524,20 @@ func updateAccountMenu() {
 	accountMenuAction.SetVisible(len(accounts) > 0)
 }
 
+// sortOrgsByName returns a copy of orgs sorted by name, ignoring case. Orgs
+// with the same name are ordered by ID so the menu does not reshuffle when the
+// API returns them in a different order.
+func sortOrgsByName(orgs []api.Org) []api.Org {
+	sorted := slices.Clone(orgs)
+	slices.SortFunc(sorted, func(a, b api.Org) int {
+		if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return sorted
+}
+
 // updateOrganizations updates the organizations menu
 func updateOrganizations() {
 	if orgMenu == nil || orgsMenuAction == nil || authManager == nil {
@@ -1184,60 +1634,31 @@ func updateOrganizations() {
 		}
 	}
 
+	// Favorites first, then recently selected ones when there are many orgs, then the rest
+	menuOrgs := orderOrgsForMenu(orgs)
+
 	// Update or add orgs
-	for _, org := range orgs {
+	for i, org := range menuOrgs {
 		action, exists := orgActions[org.Id]
 		if !exists {
 			// Create new action
 			action = walk.NewAction()
-			action.SetText(org.Name)
 			action.SetCheckable(true)
 			action.Triggered().Attach(func() {
 				org := org
-
-				go func() {
-					if err := authManager.SelectOrganization(&org); err != nil {
-						logger.Error("Failed to select organization: %v", err)
-						// Show error dialog to user
-						walk.App().Synchronize(func() {
-							td := walk.NewTaskDialog()
-							_, _ = td.Show(walk.TaskDialogOpts{
-								Owner:         mainWindow,
-								Title:         "Organization Selection Failed",
-								Content:       fmt.Sprintf("Failed to select organization: %v", err),
-								IconSystem:    walk.TaskDialogSystemIconError,
-								CommonButtons: win.TDCBF_OK_BUTTON,
-							})
-						})
-					} else {
-						updateMenu()
-
-						if tunnelManager.IsConnected() {
-							if err := tunnelManager.SwitchOLMOrg(org.Id); err != nil {
-								logger.Error("Failed to switch tunnel organization: %v", err)
-								// Show error dialog to user
-								walk.App().Synchronize(func() {
-									td := walk.NewTaskDialog()
-									_, _ = td.Show(walk.TaskDialogOpts{
-										Owner:         mainWindow,
-										Title:         "Tunnel Organization Switch Failed",
-										Content:       fmt.Sprintf("Failed to switch tunnel organization: %v", err),
-										IconSystem:    walk.TaskDialogSystemIconError,
-										CommonButtons: win.TDCBF_OK_BUTTON,
-									})
-								})
-							}
-						}
-					}
-				}()
+				go selectOrganization(org)
 			})
 			orgActions[org.Id] = action
 
 			// Insert after separator (index 2: count label at 0, separator at 1)
 			actions.Insert(2, action)
-		} else {
-			// Update existing action
-			action.SetText(org.Name)
+		}
+		action.SetText(orgMenuText(org))
+
+		// Keep the menu in menuOrgs order (index 2: count label at 0, separator at 1)
+		if actions.Index(action) != 2+i {
+			actions.Remove(action)
+			actions.Insert(2+i, action)
 		}
 
 		// Update checked state
@@ -1245,6 +1666,42 @@ func updateOrganizations() {
 		action.SetEnabled(!shouldDisable)
 	}
 
+	updateConnectOrgMenu(sortOrgsByName(orgs), shouldDisable)
+	updateFavoriteOrgsMenu(menuOrgs)
+
+	if orgSearchAction == nil {
+		orgSearchAction = walk.NewAction()
+		orgSearchAction.SetText("Search Organizations...")
+		orgSearchAction.Triggered().Attach(showOrgSearchDialog)
+		orgMenu.Actions().Add(orgSearchAction)
+	}
+	orgSearchAction.SetVisible(len(orgs) > orgSearchThreshold)
+	orgSearchAction.SetEnabled(!shouldDisable)
+
+	// Pin the current org for scheduled connects
+	if autoConnectOrgAction == nil {
+		autoConnectOrgAction = walk.NewAction()
+		autoConnectOrgAction.SetText("Use for Automatic Connect")
+		autoConnectOrgAction.SetCheckable(true)
+		autoConnectOrgAction.Triggered().Attach(func() {
+			org := authManager.CurrentOrg()
+			if org == nil || configManager == nil {
+				return
+			}
+			orgID := org.Id
+			if configManager.GetAutoConnectOrgID() == orgID {
+				orgID = ""
+			}
+			if !configManager.SetAutoConnectOrgID(orgID) {
+				logger.Error("Failed to save auto-connect organization")
+			}
+			updateMenu()
+		})
+		orgMenu.Actions().Add(autoConnectOrgAction)
+	}
+	autoConnectOrgAction.SetVisible(currentOrg != nil)
+	autoConnectOrgAction.SetChecked(currentOrg != nil && configManager != nil && configManager.GetAutoConnectOrgID() == currentOrg.Id)
+
 	// Update orgs menu action text
 	currentOrgName := "Organizations"
 	if currentOrg != nil {
@@ -1254,6 +1711,179 @@ func updateOrganizations() {
 	// Always show menu when authenticated (visibility controlled by updateMenu based on auth state)
 }
 
+// updateConnectOrgMenu keeps the "Connect to Organization" submenu (at the end of the org menu) in sync with orgs
+func updateConnectOrgMenu(orgs []api.Org, shouldDisable bool) {
+	if connectOrgMenu == nil {
+		menu, err := walk.NewMenu()
+		if err != nil {
+			logger.Error("Failed to create connect org menu: %v", err)
+			return
+		}
+		connectOrgMenu = menu
+		connectOrgMenuAction = walk.NewMenuAction(connectOrgMenu)
+		connectOrgMenuAction.SetText("Connect to Organization")
+		orgMenu.Actions().Add(walk.NewSeparatorAction())
+		orgMenu.Actions().Add(connectOrgMenuAction)
+	}
+
+	orgSet := make(map[string]bool)
+	for _, org := range orgs {
+		orgSet[org.Id] = true
+	}
+	for orgId, action := range connectOrgActions {
+		if !orgSet[orgId] {
+			connectOrgMenu.Actions().Remove(action)
+			delete(connectOrgActions, orgId)
+		}
+	}
+
+	actions := connectOrgMenu.Actions()
+	for i, org := range orgs {
+		action, exists := connectOrgActions[org.Id]
+		if !exists {
+			action = walk.NewAction()
+			action.Triggered().Attach(func() {
+				org := org
+				go connectToOrganization(org)
+			})
+			connectOrgActions[org.Id] = action
+			actions.Add(action)
+		}
+		if actions.Index(action) != i {
+			actions.Remove(action)
+			actions.Insert(i, action)
+		}
+		action.SetText(org.Name)
+		action.SetEnabled(!shouldDisable)
+	}
+
+	connectOrgMenuAction.SetVisible(len(orgs) > 0)
+}
+
+// connectToOrganization selects org and connects to it in one step, stopping
+// any existing connection to a different org first
+func connectToOrganization(org api.Org) {
+	if tunnelManager == nil || authManager == nil {
+		return
+	}
+
+	currentOrg := authManager.CurrentOrg()
+	sameOrg := currentOrg != nil && currentOrg.Id == org.Id
+	if sameOrg && tunnelManager.State() != tunnel.StateStopped {
+		logger.Info("Already connected to organization %s", org.Id)
+		return
+	}
+
+	if !sameOrg {
+		if err := authManager.SelectOrganization(&org); err != nil {
+			logger.Error("Failed to select organization: %v", err)
+			var deniedErr *auth.OrgAccessDeniedError
+			if errors.As(err, &deniedErr) {
+				showOrgAccessDeniedDialog(deniedErr, func() {
+					connectToOrganization(org)
+				})
+				return
+			}
+			walk.App().Synchronize(func() {
+				td := walk.NewTaskDialog()
+				_, _ = td.Show(walk.TaskDialogOpts{
+					Owner:         mainWindow,
+					Title:         "Organization Selection Failed",
+					Content:       fmt.Sprintf("Failed to select organization: %v", err),
+					IconSystem:    walk.TaskDialogSystemIconError,
+					CommonButtons: win.TDCBF_OK_BUTTON,
+				})
+			})
+			return
+		}
+		updateMenu()
+	}
+
+	if tunnelManager.State() != tunnel.StateStopped {
+		logger.Info("Disconnecting from current organization before connecting to %s", org.Id)
+		if err := tunnelManager.DisconnectAndWait(); err != nil {
+			logger.Error("Failed to stop tunnel: %v", err)
+			showConnectionErrorDialog(err, "Disconnect Failed")
+			return
+		}
+	}
+
+	if err := tunnelManager.ConnectWithRetry(); err != nil {
+		logger.Error("Failed to start tunnel: %v", err)
+		showConnectionErrorDialog(err, "Connection Failed")
+	}
+}
+
+// connectOnLaunch waits for the saved session to be restored and connects the
+// tunnel to the organization pinned for automatic connects
+func connectOnLaunch() {
+	for authManager.IsInitializing() {
+		time.Sleep(500 * time.Millisecond)
+	}
+	if !authManager.IsAuthenticated() || authManager.SessionExpired() {
+		logger.Info("Not connecting on launch, no valid session")
+		return
+	}
+
+	err := tunnelManager.ConnectOnLaunch()
+	if errors.Is(err, tunnel.ErrAutoConnectOrgUnavailable) {
+		walk.App().Synchronize(func() {
+			trayIcon.ShowWarning("Automatic Connect Skipped", "The organization selected for automatic connect is no longer available. Choose another one in the Organizations menu.")
+		})
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to connect on launch: %v", err)
+		showConnectionErrorDialog(err, "Connection Failed")
+	}
+}
+
+// selectOrganization selects org and, when connected, switches the tunnel to it.
+// Policy denials show the access-denied dialog, which retries the selection after a successful recheck.
+func selectOrganization(org api.Org) {
+	if err := authManager.SelectOrganization(&org); err != nil {
+		logger.Error("Failed to select organization: %v", err)
+		var deniedErr *auth.OrgAccessDeniedError
+		if errors.As(err, &deniedErr) {
+			showOrgAccessDeniedDialog(deniedErr, func() {
+				selectOrganization(org)
+			})
+			return
+		}
+		// Show error dialog to user
+		walk.App().Synchronize(func() {
+			td := walk.NewTaskDialog()
+			_, _ = td.Show(walk.TaskDialogOpts{
+				Owner:         mainWindow,
+				Title:         "Organization Selection Failed",
+				Content:       fmt.Sprintf("Failed to select organization: %v", err),
+				IconSystem:    walk.TaskDialogSystemIconError,
+				CommonButtons: win.TDCBF_OK_BUTTON,
+			})
+		})
+		return
+	}
+
+	updateMenu()
+
+	if tunnelManager.IsConnected() {
+		if err := tunnelManager.SwitchOLMOrg(org.Id); err != nil {
+			logger.Error("Failed to switch tunnel organization: %v", err)
+			// Show error dialog to user
+			walk.App().Synchronize(func() {
+				td := walk.NewTaskDialog()
+				_, _ = td.Show(walk.TaskDialogOpts{
+					Owner:         mainWindow,
+					Title:         "Tunnel Organization Switch Failed",
+					Content:       fmt.Sprintf("Failed to switch tunnel organization: %v", err),
+					IconSystem:    walk.TaskDialogSystemIconError,
+					CommonButtons: win.TDCBF_OK_BUTTON,
+				})
+			})
+		}
+	}
+}
+
 // updateLoginAction updates the login button text and enabled state
 func updateLoginAction() {
 	if loginAction == nil || authManager == nil || accountManager == nil {
@@ -1282,6 +1912,42 @@ func updateLoginAction() {
 	loginAction.SetVisible(len(accountManager.Accounts) == 0)
 }
 
+// showTrayMenu shows the popup menu at the cursor using Windows API
+func showTrayMenu() {
+	// Handle menu open - verify session and refresh orgs
+	handleMenuOpen()
+
+	// Get cursor position
+	var pt win.POINT
+	win.GetCursorPos(&pt)
+
+	// Get the menu handle from the context menu using unsafe
+	// The Menu struct should have an hMenu field as the first field
+	menuPtr := (*struct {
+		hMenu win.HMENU
+	})(unsafe.Pointer(contextMenu))
+
+	if menuPtr.hMenu != 0 {
+		// Update menu before showing (in case state changed)
+		updateMenu()
+
+		// Show the menu using TrackPopupMenu
+		// TrackPopupMenu automatically closes when clicking away
+		win.SetForegroundWindow(mainWindow.Handle())
+		win.TrackPopupMenu(
+			menuPtr.hMenu,
+			win.TPM_LEFTALIGN|win.TPM_LEFTBUTTON|win.TPM_RIGHTBUTTON,
+			pt.X,
+			pt.Y,
+			0,
+			mainWindow.Handle(),
+			nil,
+		)
+		// Post a null message to ensure the menu closes properly
+		win.PostMessage(mainWindow.Handle(), win.WM_NULL, 0, 0)
+	}
+}
+
 func SetupTray(
 	mw *walk.MainWindow,
 	am *auth.AuthManager,
@@ -1296,26 +1962,27 @@ func SetupTray(
 	configManager = cm
 	apiClient = ac
 	accountManager = accm
+	secretManager = sm
 
 	// Initialize tunnel manager with IPC adapter
 	ipcAdapter := managers.NewIPCAdapter()
 	tunnelManager = tunnel.NewManager(am, cm, accm, sm, ipcAdapter)
+	tunnelManager.StartScheduler()
 
-	// Create NotifyIcon
-	ni, err := walk.NewNotifyIcon()
-	if err != nil {
-		return err
-	}
-	trayIcon = ni // Store reference for icon updates
-
-	// Load default gray icon (disconnected state)
-	setTrayIconForState(tunnel.StateStopped)
+	// Create NotifyIcon; if the shell is not ready it is added later
+	watchTaskbarCreated()
+	createTrayIcon(1)
 
-	// Set initial tooltip
-	updateTrayTooltip(tunnel.StateStopped)
-
-	// Initialize context menu
-	contextMenu = ni.ContextMenu()
+	// Initialize context menu, on its own until the icon exists
+	if trayIcon != nil {
+		contextMenu = trayIcon.ContextMenu()
+	} else {
+		menu, err := walk.NewMenu()
+		if err != nil {
+			return err
+		}
+		contextMenu = menu
+	}
 
 	// Setup menu structure once
 	if err := setupMenu(); err != nil {
@@ -1323,46 +1990,14 @@ func SetupTray(
 		return err
 	}
 
-	// Handle left-click to show popup menu using Windows API
-	ni.MouseDown().Attach(func(x, y int, button walk.MouseButton) {
-		if button == walk.LeftButton {
-			// Handle menu open - verify session and refresh orgs
-			handleMenuOpen()
-
-			// Get cursor position
-			var pt win.POINT
-			win.GetCursorPos(&pt)
-
-			// Get the menu handle from the context menu using unsafe
-			// The Menu struct should have an hMenu field as the first field
-			menuPtr := (*struct {
-				hMenu win.HMENU
-			})(unsafe.Pointer(contextMenu))
-
-			if menuPtr.hMenu != 0 {
-				// Update menu before showing (in case state changed)
-				updateMenu()
-
-				// Show the menu using TrackPopupMenu
-				// TrackPopupMenu automatically closes when clicking away
-				win.SetForegroundWindow(mw.Handle())
-				win.TrackPopupMenu(
-					menuPtr.hMenu,
-					win.TPM_LEFTALIGN|win.TPM_LEFTBUTTON|win.TPM_RIGHTBUTTON,
-					pt.X,
-					pt.Y,
-					0,
-					mw.Handle(),
-					nil,
-				)
-				// Post a null message to ensure the menu closes properly
-				win.PostMessage(mw.Handle(), win.WM_NULL, 0, 0)
-			}
-		}
+	// Optional taskbar status window for users who can't find the tray icon
+	applyStatusWindowSetting()
+	preferences.SetSecretManager(secretManager)
+	preferences.SetOnSettingsSaved(func() {
+		applyStatusWindowSetting()
+		applyNetworkSettings()
 	})
 
-	ni.SetVisible(true)
-
 	// Register for update notifications from manager (if connected via IPC)
 	// These callbacks will be called when the manager finds updates or makes progress
 	updateFoundCb = managers.IPCClientRegisterUpdateFound(func(updateState managers.UpdateState) {
@@ -1387,6 +2022,32 @@ func SetupTray(
 		})
 	})
 
+	// If the manager closed our events pipe but still answers, exit so it
+	// starts this UI again with new pipes
+	eventsClosedCb = managers.IPCClientRegisterEventsClosed(func() {
+		if err := managers.IPCClientPing(managerPingTimeout); err != nil {
+			logger.Error("Lost the manager events pipe and the manager is not responding: %v", err)
+			return
+		}
+		logger.Warn("Manager service closed the events pipe, restarting the UI to reconnect")
+		walk.App().Synchronize(func() {
+			walk.App().Exit(managers.UIReconnectExitCode)
+		})
+	})
+
+	// Register for resume-from-sleep notifications so a dead tunnel is reconnected
+	systemResumedCb = managers.IPCClientRegisterSystemResumed(func() {
+		if tunnelManager == nil || configManager == nil || !configManager.GetReconnectOnResume() {
+			return
+		}
+		go func() {
+			if err := tunnelManager.VerifyAfterResume(); err != nil {
+				logger.Error("Failed to reconnect tunnel after resume: %v", err)
+				showConnectionErrorDialog(err, "Reconnect Failed")
+			}
+		}()
+	})
+
 	updateProgressCb = managers.IPCClientRegisterUpdateProgress(func(dp updater.DownloadProgress) {
 		if len(dp.Activity) > 0 {
 			logger.Info("Update: %s", dp.Activity)
@@ -1408,6 +2069,9 @@ func SetupTray(
 		walk.App().Synchronize(func() {
 			if dp.Error != nil {
 				closeAppUpdateProgressUI()
+				if errors.Is(dp.Error, updater.ErrUpdateCancelled) {
+					return
+				}
 				td := walk.NewTaskDialog()
 				_, _ = td.Show(walk.TaskDialogOpts{
 					Owner:         mw,
@@ -1454,7 +2118,7 @@ func SetupTray(
 			if !startupDialogShown {
 				startupDialogShown = true
 				startupDialogMutex.Unlock()
-				triggerUpdate(mainWindow)
+				promptForUpdateOnStartup(mainWindow)
 			} else {
 				startupDialogMutex.Unlock()
 			}
@@ -1474,7 +2138,7 @@ func SetupTray(
 			if !startupDialogShown {
 				startupDialogShown = true
 				startupDialogMutex.Unlock()
-				triggerUpdate(mainWindow)
+				promptForUpdateOnStartup(mainWindow)
 			} else {
 				startupDialogMutex.Unlock()
 			}
@@ -1514,9 +2178,54 @@ func SetupTray(
 		})
 	})
 
+	// Show sites that stopped answering in the tooltip and status window
+	tunnelManager.RegisterStalePeersCallback(func(stale []string) {
+		walk.App().Synchronize(func() {
+			updateTrayTooltip(tunnelManager.State())
+			updateStatusWindow()
+		})
+	})
+
+	// List connection events in the status window as they happen
+	tunnelManager.RegisterEventCallback(func(ev tunnel.Event) {
+		logger.Debug("Connection event: %s %s", ev.Type, ev.Message)
+		walk.App().Synchronize(updateStatusWindow)
+	})
+
+	// Offer the policy resolution flow when access is revoked mid-session
+	tunnelManager.RegisterOrgAccessLostCallback(func(deniedErr *auth.OrgAccessDeniedError) {
+		if trayIcon != nil {
+			walk.App().Synchronize(func() {
+				trayIcon.ShowWarning("Disconnected", "Access to your organization was revoked by policy.")
+			})
+		}
+		showOrgAccessDeniedDialog(deniedErr, func() {
+			if connErr := tunnelManager.ConnectWithRetry(); connErr != nil {
+				logger.Error("Failed to start tunnel: %v", connErr)
+				showConnectionErrorDialog(connErr, "Connection Failed")
+			}
+		})
+	})
+
 	// Register for tunnel error notifications via tunnel manager
+	tunnelManager.RegisterConnectErrorCallback(func(err *tunnel.ConnectionError) {
+		showConnectionErrorDialog(err, "Connection Failed")
+	})
 	tunnelManager.RegisterErrorCallback(func(err *tunnel.OLMStatusError) {
 		logger.Error("Tunnel error detected: code=%s, message=%s", err.Code, err.Message)
+		// If the failure was caused by an org policy, offer the resolution flow instead of a dead-end error
+		if currentOrg := authManager.CurrentOrg(); currentOrg != nil {
+			var deniedErr *auth.OrgAccessDeniedError
+			if _, accessErr := authManager.CheckOrgAccess(currentOrg.Id); errors.As(accessErr, &deniedErr) {
+				showOrgAccessDeniedDialog(deniedErr, func() {
+					if connErr := tunnelManager.ConnectWithRetry(); connErr != nil {
+						logger.Error("Failed to start tunnel: %v", connErr)
+						showConnectionErrorDialog(connErr, "Connection Failed")
+					}
+				})
+				return
+			}
+		}
 		walk.App().Synchronize(func() {
 			td := walk.NewTaskDialog()
 			errorMessage := err.Message
@@ -1556,57 +2265,101 @@ func SetupTray(
 		}
 	}()
 
-	// Background refresh loop - DISABLED for now
-	/*
-		go func() {
-			// Initial delay before first refresh (with jitter)
-			initialJitter := time.Duration(rand.Intn(7000)) * time.Millisecond
-			time.Sleep(initialJitter)
-
-			for {
-				if authManager == nil {
-					time.Sleep(180 * time.Second)
-					continue
-				}
+	// Welcome new users on a fresh install, explaining where the app lives and
+	// offering to log in, instead of leaving them with just a tray icon.
+	// Only ever shown once.
+	if !authManager.IsAuthenticated() && !accountManager.HasAccounts() && !configManager.GetOnboardingShown() {
+		configManager.SetOnboardingShown(true)
+		walk.App().Synchronize(showWelcomeWindow)
+	}
 
-				// Only refresh if authenticated
-				if authManager.IsAuthenticated() {
-					// Get OLM ID
-					olmId, found := authManager.GetOlmId()
-					if found && olmId != "" {
-						// Refresh from MyDevice
-						err := authManager.RefreshFromMyDevice(olmId)
-						if err != nil {
-							logger.Error("Failed to refresh from MyDevice: %v", err)
-						} else {
-							// Update menu to reflect updated orgs
-							updateMenu()
-						}
-					}
-				}
+	// Warn in the menu if the manager service stops answering IPC
+	go monitorManagerHealth()
 
-				// Check if unauthenticated after refresh (refresh might result in logout)
-				if !authManager.IsAuthenticated() {
-					// If unauthenticated, stop tunnel
-					if tunnelManager != nil && tunnelManager.IsConnected() {
-						logger.Info("User is unauthenticated, stopping tunnel")
-						if err := tunnelManager.Disconnect(); err != nil {
-							logger.Error("Failed to stop tunnel after authentication loss: %v", err)
-						}
-					}
-				}
+	if configManager.GetConnectOnLaunch() {
+		go connectOnLaunch()
+	}
 
-				baseInterval := 180 * time.Second
-				jitterRange := 15 * time.Second
-				jitter := time.Duration(rand.Intn(int(2*jitterRange))) - jitterRange
-				time.Sleep(baseInterval + jitter)
+	// Proactively verify the session so stale tokens are caught before the user
+	// next opens the menu
+	go func() {
+		for {
+			time.Sleep(sessionCheckInterval)
+			if authManager == nil || !authManager.IsAuthenticated() || authManager.SessionExpired() || authManager.IsServerDown() || isRateLimited() {
+				continue
 			}
-		}()
-	*/
+
+			if err := authManager.VerifySession(); err != nil {
+				logger.Warn("Periodic session check failed: %v", err)
+				backOffIfRateLimited(err)
+			}
+			updateMenu()
+		}
+	}()
+
+	// Pick up user and organization changes made elsewhere (and a logout done
+	// from another device) without waiting for the user to open the menu
+	go backgroundRefreshLoop()
 
 	return nil
 }
 
+// backgroundRefreshLoop periodically refreshes the user and organizations at
+// the interval set in preferences, and stops the tunnel if the refresh finds
+// the user logged out. It idles while the setting is off.
+func backgroundRefreshLoop() {
+	// Initial delay before first refresh (with jitter)
+	time.Sleep(time.Duration(rand.IntN(7000)) * time.Millisecond)
+
+	for {
+		minutes := configManager.GetBackgroundRefreshMinutes()
+		if minutes <= 0 {
+			time.Sleep(backgroundRefreshIdlePoll)
+			continue
+		}
+
+		refreshInBackground()
+
+		interval := time.Duration(minutes) * time.Minute
+		jitter := rand.N(2*backgroundRefreshJitter) - backgroundRefreshJitter
+		time.Sleep(interval + jitter)
+	}
+}
+
+// refreshInBackground runs one background refresh. It is skipped while the
+// app is initializing or the session cannot be used.
+func refreshInBackground() {
+	if authManager == nil || authManager.IsInitializing() || !authManager.IsAuthenticated() ||
+		authManager.SessionExpired() || authManager.IsServerDown() || isRateLimited() {
+		return
+	}
+
+	var err error
+	if olmId, found := authManager.GetOlmId(); found && olmId != "" {
+		err = authManager.RefreshFromMyDevice(olmId)
+	} else {
+		err = authManager.RefreshOrganizations()
+	}
+	if err != nil {
+		logger.Warn("Background refresh failed: %v", err)
+		backOffIfRateLimited(err)
+	}
+	disconnectIfLoggedOut()
+	updateMenu()
+}
+
+// disconnectIfLoggedOut stops the tunnel after a refresh found the user
+// logged out, since the server no longer accepts them
+func disconnectIfLoggedOut() {
+	if authManager.IsAuthenticated() || tunnelManager == nil || !tunnelManager.IsConnected() {
+		return
+	}
+	logger.Info("User is unauthenticated, stopping tunnel")
+	if err := tunnelManager.Disconnect(); err != nil {
+		logger.Error("Failed to stop tunnel after authentication loss: %v", err)
+	}
+}
+
 // closeAppUpdateProgressUI must run on the UI thread.
 func closeAppUpdateProgressUI() {
 	if appUpdateProgressClose != nil {
@@ -1614,9 +2367,13 @@ func closeAppUpdateProgressUI() {
 	}
 	appUpdateProgressClose = nil
 	appUpdateProgressLabel = nil
+	appUpdateProgressBar = nil
+	appUpdateCancelButton = nil
 }
 
-// applyAppUpdateProgressLabel must run on the UI thread. Updates status text only; the bar stays in marquee (loading) like the CLI install flow.
+// applyAppUpdateProgressLabel must run on the UI thread. It shows the current
+// activity, and while downloading fills the bar from the bytes received; the
+// bar stays in marquee mode until the download size is known.
 func applyAppUpdateProgressLabel(dp updater.DownloadProgress) {
 	if appUpdateProgressLabel == nil {
 		return
@@ -1625,9 +2382,44 @@ func applyAppUpdateProgressLabel(dp updater.DownloadProgress) {
 	if text == "" {
 		text = "Working…"
 	}
+	if dp.BytesTotal > 0 && dp.Error == nil && !dp.Complete {
+		text = formatDownloadProgress(dp)
+		if appUpdateProgressBar != nil {
+			if appUpdateProgressBar.MarqueeMode() {
+				_ = appUpdateProgressBar.SetMarqueeMode(false)
+				appUpdateProgressBar.SetRange(0, 100)
+			}
+			appUpdateProgressBar.SetValue(int(min(dp.BytesDownloaded*100/dp.BytesTotal, 100)))
+		}
+	}
+	if dp.Activity == "Installing update" {
+		// The installer can't be interrupted once it has started
+		if appUpdateCancelButton != nil {
+			appUpdateCancelButton.SetEnabled(false)
+		}
+		if appUpdateProgressBar != nil {
+			_ = appUpdateProgressBar.SetMarqueeMode(true)
+		}
+	}
 	appUpdateProgressLabel.SetText(text)
 }
 
+// formatDownloadProgress describes a running download, e.g.
+// "Downloading 45% – 2.1 MB/s – 12s left". Rate and time left are omitted
+// until they have been measured.
+func formatDownloadProgress(dp updater.DownloadProgress) string {
+	percent := min(dp.BytesDownloaded*100/dp.BytesTotal, 100)
+	text := fmt.Sprintf("Downloading %d%%", percent)
+	if dp.BytesPerSecond > 0 {
+		const mb = 1024 * 1024
+		text += fmt.Sprintf(" – %.1f MB/s", float64(dp.BytesPerSecond)/mb)
+	}
+	if dp.ETA > 0 {
+		text += fmt.Sprintf(" – %s left", dp.ETA.Round(time.Second))
+	}
+	return text
+}
+
 func newAppUpdateProgressDialog(mw *walk.MainWindow) func() {
 	dlg, err := walk.NewDialogWithFixedSize(mw)
 	if err != nil {
@@ -1659,11 +2451,44 @@ func newAppUpdateProgressDialog(mw *walk.MainWindow) func() {
 		logger.Error("Failed to enable app update progress marquee: %v", err)
 	}
 
+	buttons, err := walk.NewComposite(dlg)
+	if err != nil {
+		logger.Error("Failed to create app update progress buttons: %v", err)
+		dlg.Close(0)
+		return nil
+	}
+	h := walk.NewHBoxLayout()
+	h.SetMargins(walk.Margins{})
+	buttons.SetLayout(h)
+	walk.NewHSpacer(buttons)
+	cancel, err := walk.NewPushButton(buttons)
+	if err != nil {
+		logger.Error("Failed to create app update cancel button: %v", err)
+		dlg.Close(0)
+		return nil
+	}
+	cancel.SetText("Cancel")
+	cancel.Clicked().Attach(func() {
+		cancel.SetEnabled(false)
+		info.SetText("Cancelling…")
+		go func() {
+			if err := managers.IPCClientCancelUpdate(); err != nil {
+				logger.Error("Failed to cancel update: %v", err)
+			}
+		}()
+	})
+
+	palette := theme.Current()
+	theme.ApplyTitleBar(dlg.Handle(), palette)
+	theme.ApplyDark(dlg, palette)
+
 	_ = dlg.SetSize(walk.Size{Width: 420, Height: 0})
 	dlg.SetMinMaxSize(walk.Size{Width: 420, Height: 0}, walk.Size{Width: 500, Height: 200})
 	dlg.Show()
 
 	appUpdateProgressLabel = info
+	appUpdateProgressBar = pb
+	appUpdateCancelButton = cancel
 	var once sync.Once
 	return func() {
 		once.Do(func() {
@@ -1703,6 +2528,10 @@ func newCLIInstallProgressDialog(mw *walk.MainWindow) (close func()) {
 		logger.Error("Failed to enable progress marquee: %v", err)
 	}
 
+	palette := theme.Current()
+	theme.ApplyTitleBar(dlg.Handle(), palette)
+	theme.ApplyDark(dlg, palette)
+
 	_ = dlg.SetSize(walk.Size{Width: 420, Height: 0})
 	dlg.SetMinMaxSize(walk.Size{Width: 420, Height: 0}, walk.Size{Width: 500, Height: 200})
 	dlg.Show()
@@ -1805,9 +2634,30 @@ func triggerCLIInstall(mw *walk.MainWindow) {
 	refreshCLIInstallState()
 }
 
+// promptForUpdateOnStartup shows the update prompt unless the user already declined this version
+// or snoozed the prompt. The update menu item stays visible either way so the update can still be
+// installed manually.
+func promptForUpdateOnStartup(mw *walk.MainWindow) {
+	if configManager != nil {
+		if configManager.IsUpdateSnoozed() {
+			logger.Info("Not prompting for update, snoozed by the user")
+			return
+		}
+		if declined := configManager.GetDeclinedUpdateVersion(); declined != "" {
+			updateVersion, err := managers.IPCClientUpdateVersion()
+			if err == nil && updateVersion == declined {
+				logger.Info("Not prompting for update %s, previously declined", updateVersion)
+				return
+			}
+		}
+	}
+	triggerUpdate(mw)
+}
+
 // triggerUpdate asks the user for confirmation and then triggers the update via manager
 func triggerUpdate(mw *walk.MainWindow) {
 	userAcceptedChan := make(chan bool, 1)
+	snoozed := false
 
 	// Show dialog on UI thread - Show() blocks until dialog is closed
 	walk.App().Synchronize(func() {
@@ -1818,8 +2668,17 @@ func triggerUpdate(mw *walk.MainWindow) {
 			Content:       "A new Pangolin version is available.\n\nWould you like to download and install it now?",
 			IconSystem:    walk.TaskDialogSystemIconInformation,
 			CommonButtons: win.TDCBF_YES_BUTTON | win.TDCBF_NO_BUTTON,
+			CustomButtons: []walk.TaskDialogCustomButton{{MainText: "Remind Me Later"}},
 			DefaultButton: walk.TaskDialogDefaultButtonYes,
 		}
+		opts.CustomButtons[0].Clicked().Attach(func() bool {
+			snoozed = true
+			select {
+			case userAcceptedChan <- false:
+			default:
+			}
+			return false
+		})
 		opts.CommonButtonClicked(win.TDCBF_YES_BUTTON).Attach(func() bool {
 			select {
 			case userAcceptedChan <- true:
@@ -1839,8 +2698,20 @@ func triggerUpdate(mw *walk.MainWindow) {
 
 	// Wait for user response
 	userAccepted := <-userAcceptedChan
+	if snoozed {
+		// Keep the menu badge so the update can still be installed manually
+		if configManager != nil {
+			configManager.SnoozeUpdate()
+		}
+		logger.Info("User snoozed update")
+		return
+	}
 	if !userAccepted {
 		logger.Info("User declined update")
+		// Remember the declined version so it isn't prompted for again on startup
+		if updateVersion, err := managers.IPCClientUpdateVersion(); err == nil && updateVersion != "" && configManager != nil {
+			configManager.SetDeclinedUpdateVersion(updateVersion)
+		}
 		return
 	}
 
@@ -0,0 +1,175 @@
+//go:build windows
+
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fosrl/windows/tunnel"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	"github.com/tailscale/win"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// trayIconAttempts is how many times creating or showing the tray icon is
+	// tried before giving up until Explorer announces a new taskbar
+	trayIconAttempts = 5
+	// trayIconRetryDelay is the wait between tray icon attempts
+	trayIconRetryDelay = 2 * time.Second
+
+	taskbarWatcherWindowClass = `PangolinTaskbarWatcher`
+)
+
+var taskbarCreatedMsg uint32
+
+// taskbarWatcherWindow is a hidden top-level window that receives Explorer's
+// TaskbarCreated broadcast. walk re-adds existing notify icons by itself, but
+// it has nothing to re-add if the icon was never created.
+type taskbarWatcherWindow struct {
+	walk.WindowBase
+}
+
+func (tw *taskbarWatcherWindow) WndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
+	if msg == taskbarCreatedMsg && taskbarCreatedMsg != 0 {
+		logger.Info("Taskbar was created (Explorer started or restarted)")
+		// Post it so walk's notify icon window has re-added the icon first
+		walk.App().Synchronize(onTaskbarCreated)
+	}
+	return tw.WindowBase.WndProc(hwnd, msg, wParam, lParam)
+}
+
+// trayDiagnostics describes the session and taskbar, for logging why the tray
+// icon could not be added
+func trayDiagnostics() string {
+	var sessionID uint32
+	_ = windows.ProcessIdToSessionId(windows.GetCurrentProcessId(), &sessionID)
+	taskbar := "present"
+	if win.FindWindow(windows.StringToUTF16Ptr("Shell_TrayWnd"), nil) == 0 {
+		taskbar = "not found (Explorer may not be running yet)"
+	}
+	return fmt.Sprintf("session %d, taskbar %s", sessionID, taskbar)
+}
+
+// watchTaskbarCreated creates the window that listens for TaskbarCreated.
+// Must be called on the UI thread once.
+func watchTaskbarCreated() {
+	taskbarCreatedMsg = win.RegisterWindowMessage(windows.StringToUTF16Ptr("TaskbarCreated"))
+	if taskbarCreatedMsg == 0 {
+		logger.Error("Failed to register the TaskbarCreated message")
+		return
+	}
+	walk.MustRegisterWindowClass(taskbarWatcherWindowClass)
+	if err := walk.InitWindow(new(taskbarWatcherWindow), nil, taskbarWatcherWindowClass, win.WS_OVERLAPPEDWINDOW|win.WS_DISABLED, win.WS_EX_TOOLWINDOW); err != nil {
+		logger.Error("Failed to create the taskbar watcher window: %v", err)
+	}
+}
+
+// onTaskbarCreated adds the tray icon if it could not be created before, or
+// shows it if the shell refused to earlier
+func onTaskbarCreated() {
+	if trayIcon == nil {
+		createTrayIcon(1)
+		return
+	}
+	if !trayIcon.Visible() {
+		showTrayIcon()
+	}
+}
+
+// createTrayIcon creates the notify icon and attaches it. Creating can fail
+// while the shell is still starting, so failures are retried in the
+// background and again when the taskbar is created. Must be called on the UI
+// thread.
+func createTrayIcon(attempt int) {
+	if trayIcon != nil {
+		return
+	}
+	ni, err := walk.NewNotifyIcon()
+	if err != nil {
+		logger.Error("Failed to create tray icon (attempt %d of %d, %s): %v", attempt, trayIconAttempts, trayDiagnostics(), err)
+		if attempt >= trayIconAttempts {
+			logger.Error("Giving up on the tray icon until Explorer recreates the taskbar")
+			return
+		}
+		time.AfterFunc(trayIconRetryDelay, func() {
+			walk.App().Synchronize(func() {
+				createTrayIcon(attempt + 1)
+			})
+		})
+		return
+	}
+	if attempt > 1 {
+		logger.Info("Tray icon created after %d attempts", attempt)
+	}
+	attachTrayIcon(ni)
+}
+
+// attachTrayIcon makes ni the tray icon: it applies the current state, hooks
+// up the menu and shows it
+func attachTrayIcon(ni *walk.NotifyIcon) {
+	trayIcon = ni
+
+	var state tunnel.State
+	if tunnelManager != nil {
+		state = tunnelManager.State()
+	} else {
+		tunnelStateMutex.RLock()
+		state = tunnel.State(currentTunnelState)
+		tunnelStateMutex.RUnlock()
+	}
+	setTrayIconForState(state)
+	updateTrayTooltip(state)
+
+	ni.MouseDown().Attach(func(x, y int, button walk.MouseButton) {
+		if button == walk.LeftButton {
+			showTrayMenu()
+		}
+	})
+	ni.MouseUp().Attach(func(x, y int, button walk.MouseButton) {
+		// The menu was built before the icon existed, so walk has no
+		// context menu of its own to show on right-click
+		if button == walk.RightButton && contextMenu != ni.ContextMenu() {
+			showTrayMenu()
+		}
+	})
+
+	showTrayIcon()
+}
+
+// showTrayIcon makes the tray icon visible. If the shell refuses, it keeps
+// retrying in the background and logs why. Must be called on the UI thread.
+func showTrayIcon() {
+	if win.FindWindow(windows.StringToUTF16Ptr("Shell_TrayWnd"), nil) == 0 {
+		// Showing "succeeds" without a taskbar, but nothing appears until
+		// Explorer broadcasts TaskbarCreated
+		logger.Info("Taskbar not found (%s), the tray icon will appear once Explorer starts", trayDiagnostics())
+	}
+	showTrayIconAttempt(1)
+}
+
+func showTrayIconAttempt(attempt int) {
+	if trayIcon == nil || trayIcon.Visible() {
+		return
+	}
+	err := trayIcon.SetVisible(true)
+	if err == nil {
+		if attempt > 1 {
+			logger.Info("Tray icon shown after %d attempts", attempt)
+		}
+		return
+	}
+	logger.Error("Failed to show tray icon (attempt %d of %d, %s): %v", attempt, trayIconAttempts, trayDiagnostics(), err)
+	if attempt >= trayIconAttempts {
+		logger.Error("Giving up on the tray icon until Explorer recreates the taskbar")
+		return
+	}
+	time.AfterFunc(trayIconRetryDelay, func() {
+		walk.App().Synchronize(func() {
+			showTrayIconAttempt(attempt + 1)
+		})
+	})
+}
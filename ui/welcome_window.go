@@ -0,0 +1,107 @@
+//go:build windows
+
+package ui
+
+import (
+	"path/filepath"
+
+	"github.com/fosrl/windows/ui/theme"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/tailscale/walk"
+	. "github.com/tailscale/walk/declarative"
+	"github.com/tailscale/win"
+)
+
+// showWelcomeWindow shows the one-time welcome window for new users. It
+// explains that the app lives in the tray and offers to open the login dialog.
+// Must be called on the UI thread.
+func showWelcomeWindow() {
+	var dlg *walk.Dialog
+	var logoContainer *walk.Composite
+	var loginButton, laterButton *walk.PushButton
+
+	err := Dialog{
+		AssignTo:      &dlg,
+		Title:         "Welcome to Pangolin",
+		DefaultButton: &loginButton,
+		CancelButton:  &laterButton,
+		MinSize:       Size{Width: 450, Height: 300},
+		MaxSize:       Size{Width: 450, Height: 300},
+		Layout:        VBox{Margins: Margins{Left: 20, Top: 10, Right: 20, Bottom: 10}, Spacing: 10},
+		Children: []Widget{
+			Composite{
+				AssignTo: &logoContainer,
+				Layout:   HBox{MarginsZero: true, Alignment: AlignHCenterVNear},
+				MinSize:  Size{Width: 0, Height: 60},
+				MaxSize:  Size{Width: 0, Height: 60},
+			},
+			Label{
+				Text: "Pangolin runs in the system tray, in the notification area at the\n" +
+					"bottom-right of your screen. Click the Pangolin icon there to connect,\n" +
+					"switch organizations and open preferences.",
+			},
+			Label{
+				Text:      "If you don't see the icon, click the arrow next to the clock to show\nhidden icons, and drag Pangolin onto the taskbar to keep it visible.",
+				TextColor: walk.RGB(0x80, 0x80, 0x80), // Secondary gray color
+			},
+			VSpacer{},
+			Composite{
+				Layout: HBox{MarginsZero: true, Spacing: 8},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						AssignTo: &laterButton,
+						Text:     "Not Now",
+						MinSize:  Size{Width: 75, Height: 0},
+						OnClicked: func() {
+							dlg.Cancel()
+						},
+					},
+					PushButton{
+						AssignTo: &loginButton,
+						Text:     "Log In",
+						MinSize:  Size{Width: 75, Height: 0},
+						OnClicked: func() {
+							dlg.Accept()
+						},
+					},
+				},
+			},
+		},
+	}.Create(mainWindow)
+	if err != nil {
+		logger.Error("Failed to create welcome window: %v", err)
+		return
+	}
+
+	// Disable maximize, minimize buttons, and resizing
+	style := win.GetWindowLong(dlg.Handle(), win.GWL_STYLE)
+	style &^= win.WS_MAXIMIZEBOX
+	style &^= win.WS_MINIMIZEBOX
+	style &^= win.WS_THICKFRAME
+	win.SetWindowLong(dlg.Handle(), win.GWL_STYLE, style)
+	dlg.SetSize(walk.Size{Width: 450, Height: 300})
+
+	iconsPath := getIconsPath()
+	if icon, err := walk.NewIconFromFile(filepath.Join(iconsPath, "icon-orange.ico")); err == nil {
+		dlg.SetIcon(icon)
+	}
+
+	palette := theme.Current()
+	theme.ApplyTitleBar(dlg.Handle(), palette)
+	if bgBrush, err := walk.NewSolidColorBrush(palette.Background); err == nil {
+		dlg.SetBackground(bgBrush)
+	}
+	if logoView, err := walk.NewImageView(logoContainer); err == nil {
+		if img, err := walk.NewImageFromFile(filepath.Join(iconsPath, palette.WordMarkFile())); err == nil {
+			logoView.SetImage(img)
+		}
+	}
+	theme.ApplyDark(dlg, palette)
+
+	if dlg.Run() == walk.DlgCmdOK {
+		ShowLoginDialog(mainWindow, authManager, configManager, accountManager, apiClient, tunnelManager)
+		updateMenu()
+	}
+}
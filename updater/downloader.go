@@ -30,26 +30,70 @@ type DownloadProgress struct {
 	Activity        string
 	BytesDownloaded uint64
 	BytesTotal      uint64
+	BytesPerSecond  uint64        // moving average download rate, 0 until measured
+	ETA             time.Duration // estimated time left, 0 when unknown
 	Error           error
 	Complete        bool
 }
 
+// rateSampleInterval is how often the download rate is re-estimated
+const rateSampleInterval = 500 * time.Millisecond
+
+// rateSmoothing is the weight of the newest sample in the moving average
+const rateSmoothing = 0.3
+
 type progressHashWatcher struct {
-	dp        *DownloadProgress
-	c         chan DownloadProgress
-	hashState hash.Hash
+	dp         *DownloadProgress
+	c          chan DownloadProgress
+	hashState  hash.Hash
+	lastSample time.Time
+	lastBytes  uint64
+	rate       float64
 }
 
 func (pm *progressHashWatcher) Write(p []byte) (int, error) {
+	if atomic.LoadUint32(&updateCancelled) != 0 {
+		return 0, ErrUpdateCancelled
+	}
 	bytes := len(p)
 	pm.dp.BytesDownloaded += uint64(bytes)
+	pm.updateRate(time.Now())
 	pm.c <- *pm.dp
 	pm.hashState.Write(p)
 	return bytes, nil
 }
 
+// updateRate folds the bytes received since the last sample into the moving
+// average rate and recomputes the ETA
+func (pm *progressHashWatcher) updateRate(now time.Time) {
+	if pm.lastSample.IsZero() {
+		pm.lastSample = now
+		return
+	}
+	elapsed := now.Sub(pm.lastSample)
+	if elapsed < rateSampleInterval {
+		return
+	}
+	sample := float64(pm.dp.BytesDownloaded-pm.lastBytes) / elapsed.Seconds()
+	if pm.rate == 0 {
+		pm.rate = sample
+	} else {
+		pm.rate = rateSmoothing*sample + (1-rateSmoothing)*pm.rate
+	}
+	pm.lastSample = now
+	pm.lastBytes = pm.dp.BytesDownloaded
+
+	pm.dp.BytesPerSecond = uint64(pm.rate)
+	pm.dp.ETA = 0
+	if pm.rate > 0 && pm.dp.BytesTotal > pm.dp.BytesDownloaded {
+		remaining := float64(pm.dp.BytesTotal - pm.dp.BytesDownloaded)
+		pm.dp.ETA = time.Duration(remaining / pm.rate * float64(time.Second))
+	}
+}
+
 type UpdateFound struct {
 	name             string
+	version          string
 	hash             [blake2b.Size256]byte
 	downloadLocation string // Can be empty (use default), a relative path, or a full URL
 }
@@ -59,6 +103,11 @@ func (u *UpdateFound) Name() string {
 	return u.name
 }
 
+// Version returns the version of the update
+func (u *UpdateFound) Version() string {
+	return u.version
+}
+
 func CheckForUpdate() (updateFound *UpdateFound, err error) {
 	logger.Debug("Updater: CheckForUpdate() called")
 	updateFound, _, _, err = checkForUpdate(false)
@@ -104,6 +153,14 @@ func checkForUpdate(keepSession bool) (*UpdateFound, *winhttp.Session, *winhttp.
 			session.Close()
 		}
 	}()
+	scheme := "http"
+	if updateServerUseHttps {
+		scheme = "https"
+	}
+	if err = configureSession(session, &url.URL{Scheme: scheme, Host: updateServerHost}); err != nil {
+		logger.Debug("Updater: Failed to configure WinHTTP session: %v", err)
+		return nil, nil, nil, err
+	}
 
 	logger.Debug("Updater: Connecting to update server: %s:%d (HTTPS=%v)", updateServerHost, updateServerPort, updateServerUseHttps)
 	connection, err := session.Connect(updateServerHost, updateServerPort, updateServerUseHttps)
@@ -165,6 +222,22 @@ func checkForUpdate(keepSession bool) (*UpdateFound, *winhttp.Session, *winhttp.
 
 var updateInProgress = uint32(0)
 
+// updateCancelled is set by CancelUpdate and checked while the update downloads
+var updateCancelled = uint32(0)
+
+// ErrUpdateCancelled is reported through DownloadProgress.Error when the
+// user cancels the update
+var ErrUpdateCancelled = errors.New("update was cancelled")
+
+// CancelUpdate aborts an update that is still downloading. Once the installer
+// has started the update can no longer be cancelled.
+func CancelUpdate() {
+	if atomic.LoadUint32(&updateInProgress) != 0 {
+		logger.Info("Updater: Cancel requested")
+		atomic.StoreUint32(&updateCancelled, 1)
+	}
+}
+
 func DownloadVerifyAndExecute(userToken uintptr) (progress chan DownloadProgress) {
 	progress = make(chan DownloadProgress, 128)
 	progress <- DownloadProgress{Activity: "Initializing"}
@@ -174,6 +247,7 @@ func DownloadVerifyAndExecute(userToken uintptr) (progress chan DownloadProgress
 		return
 	}
 
+	atomic.StoreUint32(&updateCancelled, 0)
 	doIt := func() {
 		defer atomic.StoreUint32(&updateInProgress, 0)
 		logger.Info("Updater: DownloadVerifyAndExecute started (userToken=%v)", userToken != 0)
@@ -296,6 +370,11 @@ func DownloadVerifyAndExecute(userToken uintptr) (progress chan DownloadProgress
 				return
 			}
 			defer downloadSession.Close()
+			if err = configureSession(downloadSession, parsedURL); err != nil {
+				logger.Debug("Updater: Failed to configure WinHTTP session for external download: %v", err)
+				progress <- DownloadProgress{Error: err}
+				return
+			}
 
 			// Connect to the external host
 			downloadConnection, err = downloadSession.Connect(host, port, isHTTPS)
@@ -342,7 +421,7 @@ func DownloadVerifyAndExecute(userToken uintptr) (progress chan DownloadProgress
 			progress <- DownloadProgress{Error: err}
 			return
 		}
-		pm := &progressHashWatcher{&dp, progress, hasher}
+		pm := &progressHashWatcher{dp: &dp, c: progress, hashState: hasher}
 		logger.Debug("Updater: Starting download (max 100 MiB)")
 		bytesWritten, err := io.Copy(file, io.TeeReader(io.LimitReader(response, 1024*1024*100 /* 100 MiB */), pm))
 		if err != nil {
@@ -376,6 +455,12 @@ func DownloadVerifyAndExecute(userToken uintptr) (progress chan DownloadProgress
 		// 	logger.Info("Updater: Skipping Authenticode verification (dev mode)")
 		// }
 
+		if atomic.LoadUint32(&updateCancelled) != 0 {
+			logger.Info("Updater: Update cancelled before installation")
+			progress <- DownloadProgress{Error: ErrUpdateCancelled}
+			return
+		}
+
 		logger.Info("Updater: Starting MSI installation")
 		progress <- DownloadProgress{Activity: "Installing update"}
 
@@ -0,0 +1,55 @@
+//go:build windows
+
+package updater
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/fosrl/newt/logger"
+	"github.com/fosrl/windows/config"
+	"github.com/fosrl/windows/updater/winhttp"
+)
+
+// configureSession applies the network settings from the system config file
+// to session, for requests to target
+func configureSession(session *winhttp.Session, target *url.URL) error {
+	if config.GetSystemTLSSkipVerify() {
+		// Downloads are still checked against their signatures
+		logger.Warn("Updater: TLS certificate verification is turned off")
+		session.SetSkipVerify(true)
+	} else if roots := config.GetSystemRootCAs(); roots != nil {
+		session.SetRootCAs(roots)
+	}
+	return configureProxy(session, target)
+}
+
+// configureProxy points session at the proxy set in the system config file,
+// or else at the one HTTPS_PROXY/HTTP_PROXY names for target. With neither,
+// WinHTTP keeps using the system proxy settings.
+func configureProxy(session *winhttp.Session, target *url.URL) error {
+	proxy := config.GetSystemProxyURL()
+	if proxy == nil {
+		var err error
+		if proxy, err = http.ProxyFromEnvironment(&http.Request{URL: target}); err != nil {
+			logger.Warn("Updater: Ignoring proxy environment variables: %v", err)
+			return nil
+		}
+	}
+	if proxy == nil {
+		return nil
+	}
+	if proxy.Scheme != "http" {
+		logger.Warn("Updater: %s proxies are not supported for downloads, using the system proxy", proxy.Scheme)
+		return nil
+	}
+
+	host := proxy.Host
+	if proxy.Port() == "" {
+		host = net.JoinHostPort(proxy.Hostname(), "80")
+	}
+	password, _ := proxy.User.Password()
+	logger.Debug("Updater: Using proxy %s", host)
+	return session.SetProxy(host, proxy.User.Username(), password)
+}
@@ -3,6 +3,8 @@
 package winhttp
 
 import (
+	"bytes"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -20,12 +22,37 @@ var (
 )
 
 type Session struct {
-	handle _HINTERNET
+	handle        _HINTERNET
+	proxyUser     string
+	proxyPassword string
+	skipVerify    bool
+	rootCAs       *x509.CertPool
+}
+
+const (
+	_WINHTTP_OPTION_PROXY          = 38
+	_WINHTTP_OPTION_PROXY_USERNAME = 0x1002
+	_WINHTTP_OPTION_PROXY_PASSWORD = 0x1003
+	_WINHTTP_OPTION_SECURITY_FLAGS = 31
+
+	_WINHTTP_OPTION_SERVER_CERT_CONTEXT = 78
+
+	_SECURITY_FLAG_IGNORE_UNKNOWN_CA        = 0x00000100
+	_SECURITY_FLAG_IGNORE_CERT_WRONG_USAGE  = 0x00000200
+	_SECURITY_FLAG_IGNORE_CERT_CN_INVALID   = 0x00001000
+	_SECURITY_FLAG_IGNORE_CERT_DATE_INVALID = 0x00002000
+)
+
+type _WINHTTP_PROXY_INFO struct {
+	accessType  uint32
+	proxy       *uint16
+	proxyBypass *uint16
 }
 
 type Connection struct {
 	handle  _HINTERNET
 	session *Session
+	server  string
 	https   bool
 }
 
@@ -94,6 +121,60 @@ func NewSession(userAgent string) (session *Session, err error) {
 	return
 }
 
+// SetProxy sends the session's requests through proxy, given as host:port,
+// instead of the system proxy. If user is set, user and password are sent
+// when the proxy asks for authentication.
+func (session *Session) SetProxy(proxy, user, password string) (err error) {
+	defer convertError(&err)
+	proxy16, err := windows.UTF16PtrFromString(proxy)
+	if err != nil {
+		return
+	}
+	info := _WINHTTP_PROXY_INFO{accessType: _WINHTTP_ACCESS_TYPE_NAMED_PROXY, proxy: proxy16}
+	err = winHttpSetOption(session.handle, _WINHTTP_OPTION_PROXY, unsafe.Pointer(&info), uint32(unsafe.Sizeof(info)))
+	runtime.KeepAlive(proxy16)
+	if err != nil {
+		return
+	}
+	session.proxyUser = user
+	session.proxyPassword = password
+	return
+}
+
+// SetSkipVerify makes the session's HTTPS requests accept any server
+// certificate. Only meant for lab setups.
+func (session *Session) SetSkipVerify(skipVerify bool) {
+	session.skipVerify = skipVerify
+}
+
+// SetRootCAs makes the session's HTTPS requests also accept servers whose
+// certificate chains to roots, e.g. a private CA that is not in the Windows
+// certificate store
+func (session *Session) SetRootCAs(roots *x509.CertPool) {
+	session.rootCAs = roots
+}
+
+// setProxyCredentials sets the proxy credentials on a request handle
+func (session *Session) setProxyCredentials(request _HINTERNET) error {
+	if session.proxyUser == "" {
+		return nil
+	}
+	for option, value := range map[uint32]string{
+		_WINHTTP_OPTION_PROXY_USERNAME: session.proxyUser,
+		_WINHTTP_OPTION_PROXY_PASSWORD: session.proxyPassword,
+	} {
+		value16, err := windows.UTF16FromString(value)
+		if err != nil {
+			return err
+		}
+		// The length is in characters, without the terminating NUL
+		if err := winHttpSetOption(request, option, unsafe.Pointer(&value16[0]), uint32(len(value16)-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (session *Session) Close() (err error) {
 	defer convertError(&err)
 	handle := (_HINTERNET)(atomic.SwapUintptr((*uintptr)(&session.handle), 0))
@@ -120,6 +201,7 @@ func (session *Session) Connect(server string, port uint16, https bool) (connect
 	if err != nil {
 		return
 	}
+	connection.server = server
 	connection.https = https
 
 	runtime.SetFinalizer(connection, func(connection *Connection) {
@@ -165,10 +247,37 @@ func (connection *Connection) Get(path string, refresh bool) (response *Response
 	if err != nil {
 		return
 	}
+	err = connection.session.setProxyCredentials(response.handle)
+	if err != nil {
+		return
+	}
+	// WinHTTP only trusts the Windows certificate store, so with extra roots it
+	// is told to accept an unknown CA and the chain is verified here instead
+	verifyRoots := connection.https && !connection.session.skipVerify && connection.session.rootCAs != nil
+	if connection.https && connection.session.skipVerify {
+		var securityFlags uint32 = _SECURITY_FLAG_IGNORE_UNKNOWN_CA | _SECURITY_FLAG_IGNORE_CERT_WRONG_USAGE |
+			_SECURITY_FLAG_IGNORE_CERT_CN_INVALID | _SECURITY_FLAG_IGNORE_CERT_DATE_INVALID
+		err = winHttpSetOption(response.handle, _WINHTTP_OPTION_SECURITY_FLAGS, unsafe.Pointer(&securityFlags), uint32(unsafe.Sizeof(securityFlags)))
+		if err != nil {
+			return
+		}
+	} else if verifyRoots {
+		var securityFlags uint32 = _SECURITY_FLAG_IGNORE_UNKNOWN_CA
+		err = winHttpSetOption(response.handle, _WINHTTP_OPTION_SECURITY_FLAGS, unsafe.Pointer(&securityFlags), uint32(unsafe.Sizeof(securityFlags)))
+		if err != nil {
+			return
+		}
+	}
 	err = winHttpSendRequest(response.handle, nil, 0, nil, 0, 0, 0)
 	if err != nil {
 		return
 	}
+	if verifyRoots {
+		err = connection.verifyServerCert(response.handle)
+		if err != nil {
+			return
+		}
+	}
 	err = winHttpReceiveResponse(response.handle, 0)
 	if err != nil {
 		return
@@ -180,6 +289,43 @@ func (connection *Connection) Get(path string, refresh bool) (response *Response
 	return
 }
 
+// verifyServerCert checks that the certificate the server presented for
+// request chains to the Windows certificate store or to the session's roots
+func (connection *Connection) verifyServerCert(request _HINTERNET) error {
+	var ctx *windows.CertContext
+	size := uint32(unsafe.Sizeof(ctx))
+	if err := winHttpQueryOption(request, _WINHTTP_OPTION_SERVER_CERT_CONTEXT, unsafe.Pointer(&ctx), &size); err != nil {
+		return err
+	}
+	defer windows.CertFreeCertificateContext(ctx)
+
+	leaf, err := x509.ParseCertificate(bytes.Clone(unsafe.Slice(ctx.EncodedCert, ctx.Length)))
+	if err != nil {
+		return fmt.Errorf("failed to parse server certificate: %w", err)
+	}
+	// The context's store holds the rest of the chain the server sent
+	intermediates := x509.NewCertPool()
+	var cert *windows.CertContext
+	for {
+		if cert, err = windows.CertEnumCertificatesInStore(ctx.Store, cert); err != nil || cert == nil {
+			break
+		}
+		if parsed, err := x509.ParseCertificate(bytes.Clone(unsafe.Slice(cert.EncodedCert, cert.Length))); err == nil {
+			intermediates.AddCert(parsed)
+		}
+	}
+
+	opts := x509.VerifyOptions{DNSName: connection.server, Intermediates: intermediates}
+	if _, err := leaf.Verify(opts); err == nil {
+		return nil
+	}
+	opts.Roots = connection.session.rootCAs
+	if _, err := leaf.Verify(opts); err != nil {
+		return fmt.Errorf("server certificate is not trusted: %w", err)
+	}
+	return nil
+}
+
 func (response *Response) Length() (length uint64, err error) {
 	defer convertError(&err)
 	numBuf := make([]uint16, 22)
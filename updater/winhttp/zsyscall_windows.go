@@ -41,6 +41,7 @@ var (
 	procWinHttpOpen          = modwinhttp.NewProc("WinHttpOpen")
 	procWinHttpOpenRequest   = modwinhttp.NewProc("WinHttpOpenRequest")
 	procWinHttpQueryHeaders = modwinhttp.NewProc("WinHttpQueryHeaders")
+	procWinHttpQueryOption   = modwinhttp.NewProc("WinHttpQueryOption")
 	procWinHttpReadData      = modwinhttp.NewProc("WinHttpReadData")
 	procWinHttpReceiveResponse = modwinhttp.NewProc("WinHttpReceiveResponse")
 	procWinHttpSendRequest   = modwinhttp.NewProc("WinHttpSendRequest")
@@ -90,6 +91,14 @@ func winHttpQueryHeaders(requestHandle _HINTERNET, infoLevel uint32, name *uint1
 	return
 }
 
+func winHttpQueryOption(sessionOrRequestHandle _HINTERNET, option uint32, buffer unsafe.Pointer, bufferLen *uint32) (err error) {
+	r1, _, e1 := syscall.Syscall6(procWinHttpQueryOption.Addr(), 4, uintptr(sessionOrRequestHandle), uintptr(option), uintptr(buffer), uintptr(unsafe.Pointer(bufferLen)), 0, 0)
+	if r1 == 0 {
+		err = errnoErr(e1)
+	}
+	return
+}
+
 func winHttpReadData(requestHandle _HINTERNET, buffer *byte, bufferSize uint32, bytesRead *uint32) (err error) {
 	r1, _, e1 := syscall.Syscall6(procWinHttpReadData.Addr(), 4, uintptr(requestHandle), uintptr(unsafe.Pointer(buffer)), uintptr(bufferSize), uintptr(unsafe.Pointer(bytesRead)), 0, 0)
 	if r1 == 0 {
@@ -0,0 +1,51 @@
+//go:build windows
+
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MinServerVersion is the oldest Pangolin server version whose API this
+// client is known to work with. Older servers answer some requests with
+// shapes the client does not understand.
+const MinServerVersion = "1.10.0"
+
+// IsServerVersionSupported reports whether a server reporting serverVersion
+// meets MinServerVersion. Versions that can't be parsed (e.g. development
+// builds) are assumed to be supported.
+func IsServerVersionSupported(serverVersion string) bool {
+	server, ok := parseVersion(serverVersion)
+	if !ok {
+		return true
+	}
+	minimum, _ := parseVersion(MinServerVersion)
+	for i := range server {
+		if server[i] != minimum[i] {
+			return server[i] > minimum[i]
+		}
+	}
+	return true
+}
+
+// parseVersion parses "major.minor.patch", ignoring a leading "v" and any
+// pre-release or build suffix. Missing minor or patch parts count as 0.
+func parseVersion(s string) (parts [3]int, ok bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+ "); i >= 0 {
+		s = s[:i]
+	}
+	fields := strings.Split(s, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}